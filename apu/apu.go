@@ -0,0 +1,773 @@
+// Package apu emulates the audio processing unit of the NES's 2A03.
+package apu
+
+import (
+	"github.com/DevinRiley/nes/cartridge"
+	"github.com/DevinRiley/nes/cpu"
+)
+
+// CPU clock rates in Hz. The APU is clocked by the CPU clock.
+const (
+	NTSCClockRate  = 1789773
+	PALClockRate   = 1662607
+	DendyClockRate = 1773448
+)
+
+var lengthTable = [32]byte{
+	10, 254, 20, 2, 40, 4, 80, 6, 160, 8, 60, 10, 14, 12, 26, 14,
+	12, 16, 24, 18, 48, 20, 96, 22, 192, 24, 72, 26, 16, 28, 32, 30,
+}
+
+var dutyTable = [4][8]byte{
+	{0, 1, 0, 0, 0, 0, 0, 0}, // 12.5%
+	{0, 1, 1, 0, 0, 0, 0, 0}, // 25%
+	{0, 1, 1, 1, 1, 0, 0, 0}, // 50%
+	{1, 0, 0, 1, 1, 1, 1, 1}, // 25% negated
+}
+
+var triangleTable = [32]byte{
+	15, 14, 13, 12, 11, 10, 9, 8, 7, 6, 5, 4, 3, 2, 1, 0,
+	0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15,
+}
+
+// noise and DMC timer periods in CPU cycles, indexed by APU.region
+var noisePeriods = [2][16]uint16{
+	{4, 8, 16, 32, 64, 96, 128, 160, 202, 254, 380, 508, 762, 1016, 2034, 4068},
+	{4, 8, 14, 30, 60, 88, 118, 148, 188, 236, 354, 472, 708, 944, 1890, 3778},
+}
+
+var dmcRates = [2][16]uint16{
+	{428, 380, 340, 320, 286, 254, 226, 214, 190, 160, 142, 128, 106, 84, 72, 54},
+	{398, 354, 316, 298, 276, 236, 210, 198, 176, 148, 132, 118, 98, 78, 66, 50},
+}
+
+// frame counter steps in CPU cycles, indexed by APU.region. The first three
+// steps are shared by both modes, then the 4-step mode ends on the fourth
+// and the 5-step mode on the fifth.
+var frameSteps = [2][5]uint{
+	{7457, 14913, 22371, 29829, 37281},
+	{8313, 16627, 24939, 33253, 41565},
+}
+
+// APU is the 2A03's audio processing unit. It sits on the CPU bus at
+// $4000-$4013, $4015 and $4017, and Step must be called once per CPU
+// cycle. The mixed output is sampled at SampleRate and collected until
+// Samples is called.
+type APU struct {
+	SampleRate float64 // samples per second, no samples are made if 0
+
+	// IRQ sets the CPU's IRQ line for the frame counter and DMC
+	// interrupts. DMCRequest asks for the DMC's next sample byte, which
+	// DMA reads a few cycles later and hands back with FillDMC. Without
+	// it the samples are silent.
+	IRQ        func(source cpu.IRQSource, asserted bool)
+	DMCRequest func(address uint16)
+
+	system   cartridge.TVSystem
+	cycles   uint64
+	pulse1   pulse
+	pulse2   pulse
+	triangle triangle
+	noise    noise
+	dmc      dmc
+
+	frameCycle      uint
+	fiveStep        bool
+	irqInhibit      bool
+	frameIRQ        bool
+	frameWrite      byte
+	frameWriteDelay int
+	irqLine         cpu.IRQSource // the sources last reported through IRQ
+
+	sampleClock float64
+	samples     []float32
+}
+
+func NewAPU(system cartridge.TVSystem, sampleRate float64) *APU {
+	apu := &APU{system: system, SampleRate: sampleRate}
+	apu.PowerOn()
+
+	return apu
+}
+
+// PowerOn puts the APU into its power up state, with every channel
+// silenced and the frame counter in 4-step mode.
+func (apu *APU) PowerOn() {
+	*apu = APU{
+		SampleRate: apu.SampleRate,
+		IRQ:        apu.IRQ,
+		DMCRequest: apu.DMCRequest,
+		system:     apu.system,
+		irqLine:    apu.irqLine,
+	}
+	apu.pulse1.channel = 1
+	apu.pulse2.channel = 2
+	apu.noise.shift = 1
+	apu.noise.period = noisePeriods[apu.region()][0]
+	apu.dmc.rate = dmcRates[apu.region()][0]
+	apu.dmc.bitsRemaining = 8
+	apu.dmc.silence = true
+	apu.Reset()
+}
+
+// Reset silences every channel and restarts the frame counter as if
+// $4017 was written with its last value. The DMC output level keeps its
+// lowest bit.
+func (apu *APU) Reset() {
+	apu.Write(0x4015, 0x00)
+	apu.Write(0x4017, apu.frameWrite)
+	apu.triangle.step = 0
+	apu.dmc.level &= 0x01
+}
+
+// Samples returns the samples produced since the last call.
+func (apu *APU) Samples() []float32 {
+	samples := apu.samples
+	apu.samples = nil
+
+	return samples
+}
+
+// Read handles CPU reads of $4015, the channel status. The other APU
+// registers are write only.
+func (apu *APU) Read(address uint16) byte {
+	if address != 0x4015 {
+		return 0
+	}
+
+	var status byte
+	if apu.pulse1.length.value > 0 {
+		status |= 0x01
+	}
+	if apu.pulse2.length.value > 0 {
+		status |= 0x02
+	}
+	if apu.triangle.length.value > 0 {
+		status |= 0x04
+	}
+	if apu.noise.length.value > 0 {
+		status |= 0x08
+	}
+	if apu.dmc.bytesRemaining > 0 {
+		status |= 0x10
+	}
+	if apu.frameIRQ {
+		status |= 0x40
+	}
+	if apu.dmc.irq {
+		status |= 0x80
+	}
+
+	apu.frameIRQ = false
+	apu.updateIRQ()
+
+	return status
+}
+
+// Write handles CPU writes to the APU registers.
+func (apu *APU) Write(address uint16, value byte) {
+	switch {
+	case address >= 0x4000 && address <= 0x4003:
+		apu.pulse1.write(address, value)
+	case address >= 0x4004 && address <= 0x4007:
+		apu.pulse2.write(address, value)
+	case address >= 0x4008 && address <= 0x400B:
+		apu.triangle.write(address, value)
+	case address >= 0x400C && address <= 0x400F:
+		apu.noise.write(address, value, apu.region())
+	case address >= 0x4010 && address <= 0x4013:
+		apu.dmc.write(address, value, apu.region())
+	case address == 0x4015:
+		apu.pulse1.length.setEnabled(value&0x01 != 0)
+		apu.pulse2.length.setEnabled(value&0x02 != 0)
+		apu.triangle.length.setEnabled(value&0x04 != 0)
+		apu.noise.length.setEnabled(value&0x08 != 0)
+
+		apu.dmc.irq = false
+		if value&0x10 == 0 {
+			apu.dmc.bytesRemaining = 0
+		} else if apu.dmc.bytesRemaining == 0 {
+			apu.dmc.restart()
+			apu.fillDMCBuffer()
+		}
+	case address == 0x4017:
+		apu.frameWrite = value
+		apu.irqInhibit = value&0x40 != 0
+		if apu.irqInhibit {
+			apu.frameIRQ = false
+		}
+
+		// the frame counter is reset 3 or 4 cycles later depending on
+		// whether the write lands on an APU cycle
+		apu.frameWriteDelay = 3
+		if apu.cycles%2 == 1 {
+			apu.frameWriteDelay = 4
+		}
+	}
+
+	apu.updateIRQ()
+}
+
+// Step advances the APU by one CPU cycle.
+func (apu *APU) Step() {
+	apu.stepFrameCounter()
+
+	if apu.frameWriteDelay > 0 {
+		apu.frameWriteDelay--
+		if apu.frameWriteDelay == 0 {
+			apu.fiveStep = apu.frameWrite&0x80 != 0
+			apu.frameCycle = 0
+			if apu.fiveStep {
+				apu.clockQuarterFrame()
+				apu.clockHalfFrame()
+			}
+		}
+	}
+
+	// the pulse timers count APU cycles, which are every other CPU cycle
+	if apu.cycles%2 == 1 {
+		apu.pulse1.clockTimer()
+		apu.pulse2.clockTimer()
+	}
+	apu.triangle.clockTimer()
+	apu.noise.clockTimer()
+	apu.dmc.clockTimer()
+	apu.fillDMCBuffer()
+
+	apu.updateIRQ()
+	apu.cycles++
+
+	if apu.SampleRate > 0 {
+		apu.sampleClock += apu.SampleRate
+		if clockRate := apu.clockRate(); apu.sampleClock >= clockRate {
+			apu.sampleClock -= clockRate
+			apu.samples = append(apu.samples, apu.Output())
+		}
+	}
+}
+
+func (apu *APU) clockRate() float64 {
+	switch apu.system {
+	case cartridge.PAL:
+		return PALClockRate
+	case cartridge.Dendy:
+		return DendyClockRate
+	}
+
+	return NTSCClockRate
+}
+
+// region picks the row of the timing tables. The Dendy's APU runs with
+// NTSC timings on a slower clock, and multi-region games get an NTSC
+// console.
+func (apu *APU) region() int {
+	if apu.system == cartridge.PAL {
+		return 1
+	}
+
+	return 0
+}
+
+func (apu *APU) stepFrameCounter() {
+	apu.frameCycle++
+	steps := frameSteps[apu.region()]
+
+	switch apu.frameCycle {
+	case steps[0], steps[2]:
+		apu.clockQuarterFrame()
+	case steps[1]:
+		apu.clockQuarterFrame()
+		apu.clockHalfFrame()
+	}
+
+	if apu.fiveStep {
+		switch apu.frameCycle {
+		case steps[4]:
+			apu.clockQuarterFrame()
+			apu.clockHalfFrame()
+		case steps[4] + 1:
+			apu.frameCycle = 0
+		}
+		return
+	}
+
+	// the frame interrupt flag is set on the three cycles around the
+	// last step of the sequence
+	last := steps[3]
+	if apu.frameCycle >= last-1 && apu.frameCycle <= last+1 && !apu.irqInhibit {
+		apu.frameIRQ = true
+	}
+
+	switch apu.frameCycle {
+	case last:
+		apu.clockQuarterFrame()
+		apu.clockHalfFrame()
+	case last + 1:
+		apu.frameCycle = 0
+	}
+}
+
+// clockQuarterFrame clocks the envelopes and the triangle's linear counter
+func (apu *APU) clockQuarterFrame() {
+	apu.pulse1.envelope.clock()
+	apu.pulse2.envelope.clock()
+	apu.noise.envelope.clock()
+	apu.triangle.clockLinearCounter()
+}
+
+// clockHalfFrame clocks the length counters and sweep units
+func (apu *APU) clockHalfFrame() {
+	apu.pulse1.length.clock()
+	apu.pulse2.length.clock()
+	apu.triangle.length.clock()
+	apu.noise.length.clock()
+	apu.pulse1.clockSweep()
+	apu.pulse2.clockSweep()
+}
+
+// fillDMCBuffer is the DMC memory reader, which asks for the next sample
+// byte whenever the sample buffer is empty.
+func (apu *APU) fillDMCBuffer() {
+	dmc := &apu.dmc
+	if dmc.bufferFull || dmc.fetching || dmc.bytesRemaining == 0 {
+		return
+	}
+
+	dmc.fetching = true
+	if apu.DMCRequest != nil {
+		apu.DMCRequest(dmc.address)
+	} else {
+		apu.FillDMC(0)
+	}
+}
+
+// FillDMC gives the DMC the sample byte it asked for with DMCRequest.
+func (apu *APU) FillDMC(value byte) {
+	dmc := &apu.dmc
+	if !dmc.fetching {
+		return
+	}
+
+	dmc.fetching = false
+	dmc.buffer = value
+	dmc.bufferFull = true
+
+	dmc.address++
+	if dmc.address == 0 {
+		dmc.address = 0x8000
+	}
+
+	// the DMA finishes even if $4015 stopped the sample meanwhile
+	if dmc.bytesRemaining == 0 {
+		return
+	}
+
+	dmc.bytesRemaining--
+	if dmc.bytesRemaining == 0 {
+		if dmc.loop {
+			dmc.restart()
+		} else if dmc.irqEnabled {
+			dmc.irq = true
+		}
+	}
+	apu.updateIRQ()
+}
+
+func (apu *APU) updateIRQ() {
+	apu.setIRQ(cpu.IRQFrameCounter, apu.frameIRQ)
+	apu.setIRQ(cpu.IRQDMC, apu.dmc.irq)
+}
+
+func (apu *APU) setIRQ(source cpu.IRQSource, asserted bool) {
+	if (apu.irqLine&source != 0) == asserted {
+		return
+	}
+
+	if asserted {
+		apu.irqLine |= source
+	} else {
+		apu.irqLine &^= source
+	}
+
+	if apu.IRQ != nil {
+		apu.IRQ(source, asserted)
+	}
+}
+
+// Output is the current output of the nonlinear mixer, from 0 to 1.
+func (apu *APU) Output() float32 {
+	var pulseOut, tndOut float64
+
+	pulses := float64(apu.pulse1.output() + apu.pulse2.output())
+	if pulses > 0 {
+		pulseOut = 95.88 / (8128/pulses + 100)
+	}
+
+	tnd := float64(apu.triangle.output())/8227 +
+		float64(apu.noise.output())/12241 +
+		float64(apu.dmc.level)/22638
+	if tnd > 0 {
+		tndOut = 159.79 / (1/tnd + 100)
+	}
+
+	return float32(pulseOut + tndOut)
+}
+
+// envelope generates a decaying volume, or a constant one
+type envelope struct {
+	start    bool
+	loop     bool
+	constant bool
+	volume   byte // the constant volume, and the divider period
+	divider  byte
+	decay    byte
+}
+
+func (envelope *envelope) write(value byte) {
+	envelope.loop = value&0x20 != 0
+	envelope.constant = value&0x10 != 0
+	envelope.volume = value & 0x0F
+}
+
+func (envelope *envelope) clock() {
+	if envelope.start {
+		envelope.start = false
+		envelope.decay = 15
+		envelope.divider = envelope.volume
+		return
+	}
+
+	if envelope.divider > 0 {
+		envelope.divider--
+		return
+	}
+
+	envelope.divider = envelope.volume
+	if envelope.decay > 0 {
+		envelope.decay--
+	} else if envelope.loop {
+		envelope.decay = 15
+	}
+}
+
+func (envelope *envelope) output() byte {
+	if envelope.constant {
+		return envelope.volume
+	}
+
+	return envelope.decay
+}
+
+// lengthCounter silences a channel once it has counted down to 0
+type lengthCounter struct {
+	enabled bool
+	halt    bool
+	value   byte
+}
+
+func (length *lengthCounter) load(value byte) {
+	if length.enabled {
+		length.value = lengthTable[value>>3]
+	}
+}
+
+func (length *lengthCounter) setEnabled(enabled bool) {
+	length.enabled = enabled
+	if !enabled {
+		length.value = 0
+	}
+}
+
+func (length *lengthCounter) clock() {
+	if !length.halt && length.value > 0 {
+		length.value--
+	}
+}
+
+type pulse struct {
+	channel  byte // pulse 1 and 2 negate their sweeps differently
+	envelope envelope
+	length   lengthCounter
+	duty     byte
+	step     byte
+	period   uint16
+	timer    uint16
+
+	sweepEnabled bool
+	sweepNegate  bool
+	sweepReload  bool
+	sweepPeriod  byte
+	sweepShift   byte
+	sweepDivider byte
+}
+
+func (pulse *pulse) write(address uint16, value byte) {
+	switch address & 0x03 {
+	case 0:
+		pulse.duty = value >> 6
+		pulse.length.halt = value&0x20 != 0
+		pulse.envelope.write(value)
+	case 1:
+		pulse.sweepEnabled = value&0x80 != 0
+		pulse.sweepPeriod = value >> 4 & 0x07
+		pulse.sweepNegate = value&0x08 != 0
+		pulse.sweepShift = value & 0x07
+		pulse.sweepReload = true
+	case 2:
+		pulse.period = pulse.period&0x0700 | uint16(value)
+	case 3:
+		pulse.period = pulse.period&0x00FF | uint16(value&0x07)<<8
+		pulse.length.load(value)
+		pulse.step = 0
+		pulse.envelope.start = true
+	}
+}
+
+func (pulse *pulse) clockTimer() {
+	if pulse.timer > 0 {
+		pulse.timer--
+		return
+	}
+
+	pulse.timer = pulse.period
+	pulse.step = (pulse.step + 1) & 0x07
+}
+
+// sweepTarget is the period the sweep unit is heading for. Pulse 1 adds
+// the ones' complement when negating, so it sweeps down one further.
+func (pulse *pulse) sweepTarget() int {
+	period := int(pulse.period)
+	delta := period >> pulse.sweepShift
+
+	if !pulse.sweepNegate {
+		return period + delta
+	}
+
+	if pulse.channel == 1 {
+		return period - delta - 1
+	}
+
+	return period - delta
+}
+
+// muted is true when the sweep unit silences the channel, which it does
+// even while the sweep is disabled
+func (pulse *pulse) muted() bool {
+	return pulse.period < 8 || pulse.sweepTarget() > 0x07FF
+}
+
+func (pulse *pulse) clockSweep() {
+	if pulse.sweepDivider == 0 && pulse.sweepEnabled && pulse.sweepShift > 0 && !pulse.muted() {
+		target := pulse.sweepTarget()
+		if target < 0 {
+			target = 0
+		}
+		pulse.period = uint16(target)
+	}
+
+	if pulse.sweepDivider == 0 || pulse.sweepReload {
+		pulse.sweepDivider = pulse.sweepPeriod
+		pulse.sweepReload = false
+	} else {
+		pulse.sweepDivider--
+	}
+}
+
+func (pulse *pulse) output() byte {
+	if pulse.length.value == 0 || pulse.muted() || dutyTable[pulse.duty][pulse.step] == 0 {
+		return 0
+	}
+
+	return pulse.envelope.output()
+}
+
+type triangle struct {
+	length       lengthCounter
+	control      bool
+	linearPeriod byte
+	linear       byte
+	linearReload bool
+	period       uint16
+	timer        uint16
+	step         byte
+}
+
+func (triangle *triangle) write(address uint16, value byte) {
+	switch address & 0x03 {
+	case 0:
+		triangle.control = value&0x80 != 0
+		triangle.length.halt = triangle.control
+		triangle.linearPeriod = value & 0x7F
+	case 2:
+		triangle.period = triangle.period&0x0700 | uint16(value)
+	case 3:
+		triangle.period = triangle.period&0x00FF | uint16(value&0x07)<<8
+		triangle.length.load(value)
+		triangle.linearReload = true
+	}
+}
+
+// clockTimer steps the triangle's sequence, which only moves while both
+// the length counter and the linear counter are non-zero
+func (triangle *triangle) clockTimer() {
+	if triangle.timer > 0 {
+		triangle.timer--
+		return
+	}
+
+	triangle.timer = triangle.period
+	if triangle.length.value > 0 && triangle.linear > 0 {
+		triangle.step = (triangle.step + 1) & 0x1F
+	}
+}
+
+func (triangle *triangle) clockLinearCounter() {
+	if triangle.linearReload {
+		triangle.linear = triangle.linearPeriod
+	} else if triangle.linear > 0 {
+		triangle.linear--
+	}
+
+	if !triangle.control {
+		triangle.linearReload = false
+	}
+}
+
+func (triangle *triangle) output() byte {
+	return triangleTable[triangle.step]
+}
+
+type noise struct {
+	envelope envelope
+	length   lengthCounter
+	mode     bool // short mode, taps bit 6 instead of bit 1
+	period   uint16
+	timer    uint16
+	shift    uint16 // 15 bit linear feedback shift register
+}
+
+func (noise *noise) write(address uint16, value byte, region int) {
+	switch address & 0x03 {
+	case 0:
+		noise.length.halt = value&0x20 != 0
+		noise.envelope.write(value)
+	case 2:
+		noise.mode = value&0x80 != 0
+		noise.period = noisePeriods[region][value&0x0F]
+	case 3:
+		noise.length.load(value)
+		noise.envelope.start = true
+	}
+}
+
+func (noise *noise) clockTimer() {
+	if noise.timer > 0 {
+		noise.timer--
+		return
+	}
+
+	if noise.period > 0 {
+		noise.timer = noise.period - 1
+	}
+
+	tap := noise.shift >> 1
+	if noise.mode {
+		tap = noise.shift >> 6
+	}
+
+	feedback := (noise.shift ^ tap) & 0x01
+	noise.shift = noise.shift>>1 | feedback<<14
+}
+
+func (noise *noise) output() byte {
+	if noise.length.value == 0 || noise.shift&0x01 != 0 {
+		return 0
+	}
+
+	return noise.envelope.output()
+}
+
+// dmc is the delta modulation channel, which plays 1 bit delta encoded
+// samples read from CPU memory
+type dmc struct {
+	irqEnabled bool
+	irq        bool
+	loop       bool
+	rate       uint16
+	timer      uint16
+	level      byte // 7 bit output level
+
+	sampleAddress  uint16
+	sampleLength   uint16
+	address        uint16
+	bytesRemaining uint16
+
+	buffer        byte
+	bufferFull    bool
+	fetching      bool // waiting for FillDMC
+	shift         byte
+	bitsRemaining byte
+	silence       bool
+}
+
+func (dmc *dmc) write(address uint16, value byte, region int) {
+	switch address & 0x03 {
+	case 0:
+		dmc.irqEnabled = value&0x80 != 0
+		if !dmc.irqEnabled {
+			dmc.irq = false
+		}
+		dmc.loop = value&0x40 != 0
+		dmc.rate = dmcRates[region][value&0x0F]
+	case 1:
+		dmc.level = value & 0x7F
+	case 2:
+		dmc.sampleAddress = 0xC000 | uint16(value)<<6
+	case 3:
+		dmc.sampleLength = uint16(value)<<4 | 1
+	}
+}
+
+func (dmc *dmc) restart() {
+	dmc.address = dmc.sampleAddress
+	dmc.bytesRemaining = dmc.sampleLength
+}
+
+func (dmc *dmc) clockTimer() {
+	if dmc.timer > 0 {
+		dmc.timer--
+		return
+	}
+
+	if dmc.rate > 0 {
+		dmc.timer = dmc.rate - 1
+	}
+	dmc.clockOutput()
+}
+
+// clockOutput moves the output level up or down by 2 for each bit of the
+// sample, staying within 0-127
+func (dmc *dmc) clockOutput() {
+	if !dmc.silence {
+		if dmc.shift&0x01 != 0 {
+			if dmc.level <= 125 {
+				dmc.level += 2
+			}
+		} else if dmc.level >= 2 {
+			dmc.level -= 2
+		}
+	}
+
+	dmc.shift >>= 1
+	dmc.bitsRemaining--
+	if dmc.bitsRemaining > 0 {
+		return
+	}
+
+	dmc.bitsRemaining = 8
+	dmc.silence = !dmc.bufferFull
+	if dmc.bufferFull {
+		dmc.shift = dmc.buffer
+		dmc.bufferFull = false
+	}
+}
@@ -0,0 +1,430 @@
+package apu
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/DevinRiley/nes/cartridge"
+	"github.com/DevinRiley/nes/cpu"
+)
+
+func stepAPU(apu *APU, cycles int) {
+	for i := 0; i < cycles; i++ {
+		apu.Step()
+	}
+}
+
+func TestAPULengthCounterStatus(t *testing.T) {
+	apu := NewAPU(cartridge.NTSC, 0)
+
+	apu.Write(0x4003, 0x08)
+	if apu.Read(0x4015)&0x01 != 0 {
+		t.Error("loaded the length counter of a disabled channel")
+	}
+
+	apu.Write(0x4015, 0x0F)
+	apu.Write(0x4003, 0x08)
+	apu.Write(0x4007, 0x08)
+	apu.Write(0x400B, 0x08)
+	apu.Write(0x400F, 0x08)
+
+	if apu.Read(0x4015) != 0x0F {
+		t.Error("did not report the length counters in $4015")
+	}
+
+	if apu.pulse1.length.value != 254 {
+		t.Error("did not load the length counter from the length table")
+	}
+
+	apu.Write(0x4015, 0x00)
+	if apu.Read(0x4015) != 0x00 {
+		t.Error("disabling the channels did not clear their length counters")
+	}
+}
+
+func TestAPULengthCounterClockedOnHalfFrames(t *testing.T) {
+	apu := NewAPU(cartridge.NTSC, 0)
+	apu.Write(0x4015, 0x01)
+	apu.Write(0x4003, 0x18) // length 2
+	stepAPU(apu, 3)
+
+	stepAPU(apu, 14912)
+	if apu.pulse1.length.value != 2 {
+		t.Error("clocked the length counter before the first half frame")
+	}
+
+	stepAPU(apu, 1)
+	if apu.pulse1.length.value != 1 {
+		t.Error("did not clock the length counter on the first half frame")
+	}
+
+	stepAPU(apu, 29829-14913)
+	if apu.pulse1.length.value != 0 || apu.Read(0x4015)&0x01 != 0 {
+		t.Error("did not clock the length counter on the second half frame")
+	}
+}
+
+func TestAPULengthCounterHalt(t *testing.T) {
+	apu := NewAPU(cartridge.NTSC, 0)
+	apu.Write(0x4015, 0x01)
+	apu.Write(0x4000, 0x20)
+	apu.Write(0x4003, 0x18)
+
+	stepAPU(apu, 2*29830)
+
+	if apu.pulse1.length.value != 2 {
+		t.Error("clocked a halted length counter")
+	}
+}
+
+func TestAPUFrameIRQ(t *testing.T) {
+	apu := NewAPU(cartridge.NTSC, 0)
+	var line bool
+	apu.IRQ = func(source cpu.IRQSource, asserted bool) {
+		if source == cpu.IRQFrameCounter {
+			line = asserted
+		}
+	}
+	stepAPU(apu, 3)
+
+	stepAPU(apu, 29827)
+	if line {
+		t.Error("asserted the frame IRQ early")
+	}
+
+	stepAPU(apu, 1)
+	if !line {
+		t.Error("did not assert the frame IRQ at the end of the sequence")
+	}
+
+	if apu.Read(0x4015)&0x40 == 0 {
+		t.Error("did not report the frame IRQ in $4015")
+	}
+
+	if line || apu.Read(0x4015)&0x40 != 0 {
+		t.Error("reading $4015 did not acknowledge the frame IRQ")
+	}
+}
+
+func TestAPUFrameIRQInhibit(t *testing.T) {
+	apu := NewAPU(cartridge.NTSC, 0)
+	stepAPU(apu, 30000)
+
+	apu.Write(0x4017, 0x40)
+	if apu.frameIRQ {
+		t.Error("setting the inhibit flag did not clear the frame IRQ")
+	}
+
+	stepAPU(apu, 30000)
+	if apu.frameIRQ {
+		t.Error("set the frame IRQ while inhibited")
+	}
+}
+
+func TestAPUFiveStepMode(t *testing.T) {
+	apu := NewAPU(cartridge.NTSC, 0)
+	apu.Write(0x4015, 0x01)
+	apu.Write(0x4003, 0x18)
+	apu.Write(0x4017, 0x80)
+	stepAPU(apu, 3)
+
+	if apu.pulse1.length.value != 1 {
+		t.Error("switching to 5-step mode did not clock a half frame")
+	}
+
+	stepAPU(apu, 37282)
+	if apu.frameIRQ {
+		t.Error("5-step mode should not set the frame IRQ")
+	}
+
+	if apu.frameCycle != 0 {
+		t.Error("did not restart the 37282 cycle sequence")
+	}
+}
+
+func TestAPUEnvelope(t *testing.T) {
+	apu := NewAPU(cartridge.NTSC, 0)
+	apu.Write(0x4015, 0x01)
+	apu.Write(0x4000, 0x01) // decay, divider period 1
+	apu.Write(0x4003, 0x08)
+
+	apu.clockQuarterFrame()
+	if apu.pulse1.envelope.output() != 15 {
+		t.Error("did not restart the envelope at 15")
+	}
+
+	apu.clockQuarterFrame()
+	apu.clockQuarterFrame()
+	if apu.pulse1.envelope.output() != 14 {
+		t.Error("did not decay once per divider period")
+	}
+
+	apu.Write(0x4000, 0x17)
+	if apu.pulse1.envelope.output() != 7 {
+		t.Error("did not output the constant volume")
+	}
+}
+
+func TestAPUEnvelopeLoops(t *testing.T) {
+	var envelope envelope
+	envelope.write(0x20)
+	envelope.start = true
+
+	for i := 0; i < 16; i++ {
+		envelope.clock()
+	}
+	if envelope.output() != 0 {
+		t.Error("did not decay to 0")
+	}
+
+	envelope.clock()
+	if envelope.output() != 15 {
+		t.Error("did not loop back to 15")
+	}
+}
+
+func TestAPUSweep(t *testing.T) {
+	apu := NewAPU(cartridge.NTSC, 0)
+	apu.Write(0x4015, 0x03)
+
+	apu.Write(0x4001, 0x89) // enabled, period 0, negate, shift 1
+	apu.Write(0x4002, 0x00)
+	apu.Write(0x4003, 0x01)
+	apu.Write(0x4005, 0x89)
+	apu.Write(0x4006, 0x00)
+	apu.Write(0x4007, 0x01)
+
+	apu.clockHalfFrame()
+
+	if apu.pulse1.period != 0x7F || apu.pulse2.period != 0x80 {
+		t.Errorf("did not sweep down, periods are %03X and %03X", apu.pulse1.period, apu.pulse2.period)
+	}
+}
+
+func TestAPUSweepMutes(t *testing.T) {
+	apu := NewAPU(cartridge.NTSC, 0)
+	apu.Write(0x4015, 0x01)
+	apu.Write(0x4000, 0x1F)
+	apu.Write(0x4001, 0x01) // disabled, but shift 1
+	apu.Write(0x4002, 0x00)
+	apu.Write(0x4003, 0x07) // period $700 sweeps to $A80
+
+	apu.pulse1.step = 1
+	if apu.pulse1.output() != 0 {
+		t.Error("did not mute a pulse whose sweep target overflows")
+	}
+
+	apu.Write(0x4003, 0x00)
+	apu.Write(0x4002, 0x07)
+	apu.pulse1.step = 1
+	if apu.pulse1.output() != 0 {
+		t.Error("did not mute a pulse with a period under 8")
+	}
+
+	apu.Write(0x4002, 0x08)
+	apu.pulse1.step = 1
+	if apu.pulse1.output() != 15 {
+		t.Error("did not output the pulse's volume")
+	}
+}
+
+func TestAPUPulseSequence(t *testing.T) {
+	apu := NewAPU(cartridge.NTSC, 0)
+	apu.Write(0x4015, 0x01)
+	apu.Write(0x4000, 0x9F) // 50% duty, constant volume 15
+	apu.Write(0x4002, 0x08)
+	apu.Write(0x4003, 0x08)
+
+	high := 0
+	for i := 0; i < 8; i++ {
+		// period 8 is 9 APU cycles per step
+		stepAPU(apu, 18)
+		if apu.pulse1.output() != 0 {
+			high++
+		}
+	}
+
+	if high != 4 {
+		t.Errorf("50%% duty cycle was high for %d of 8 steps", high)
+	}
+}
+
+func TestAPUTriangleLinearCounter(t *testing.T) {
+	apu := NewAPU(cartridge.NTSC, 0)
+	apu.Write(0x4015, 0x04)
+	apu.Write(0x4008, 0x02)
+	apu.Write(0x400A, 0x00)
+	apu.Write(0x400B, 0x08)
+
+	apu.clockQuarterFrame()
+	if apu.triangle.linear != 2 {
+		t.Error("did not reload the linear counter")
+	}
+
+	stepAPU(apu, 1)
+	if apu.triangle.step != 1 {
+		t.Error("did not step the triangle sequence")
+	}
+
+	apu.clockQuarterFrame()
+	apu.clockQuarterFrame()
+	step := apu.triangle.step
+	stepAPU(apu, 10)
+	if apu.triangle.step != step {
+		t.Error("stepped the triangle with the linear counter at 0")
+	}
+}
+
+func TestAPUNoiseShiftRegister(t *testing.T) {
+	var noise noise
+	noise.shift = 1
+
+	noise.clockTimer()
+	if noise.shift != 0x4000 {
+		t.Errorf("long mode shifted to %04X", noise.shift)
+	}
+
+	noise.shift = 0x0040
+	noise.mode = true
+	noise.clockTimer()
+	if noise.shift != 0x4020 {
+		t.Errorf("short mode shifted to %04X", noise.shift)
+	}
+}
+
+func TestAPUDMC(t *testing.T) {
+	apu := NewAPU(cartridge.NTSC, 0)
+	memory := &cpu.Memory{}
+	memory[0xC040] = 0xFF
+	memory[0xC041] = 0x00
+	reads := 0
+	apu.DMCRequest = func(address uint16) {
+		reads++
+		apu.FillDMC(memory.Read(address))
+	}
+	var line bool
+	apu.IRQ = func(source cpu.IRQSource, asserted bool) {
+		if source == cpu.IRQDMC {
+			line = asserted
+		}
+	}
+
+	apu.Write(0x4010, 0x8F) // IRQ, fastest rate
+	apu.Write(0x4011, 0x40)
+	apu.Write(0x4012, 0x01) // $C040
+	apu.Write(0x4013, 0x00) // 1 byte
+	apu.Write(0x4015, 0x10)
+
+	if reads != 1 || !line {
+		t.Error("did not fetch the sample and assert the DMC IRQ")
+	}
+
+	if apu.Read(0x4015)&0x10 != 0 {
+		t.Error("reported bytes remaining after the last fetch")
+	}
+
+	// the channel is silent for the first 8 bits, then plays the sample
+	stepAPU(apu, 16*54)
+	if apu.dmc.level != 0x40+16 {
+		t.Errorf("output level is %02X after playing $FF", apu.dmc.level)
+	}
+
+	apu.Write(0x4015, 0x00)
+	if line || apu.Read(0x4015)&0x80 != 0 {
+		t.Error("writing $4015 did not acknowledge the DMC IRQ")
+	}
+}
+
+func TestAPUDMCLoop(t *testing.T) {
+	apu := NewAPU(cartridge.NTSC, 0)
+	apu.DMCRequest = func(address uint16) { apu.FillDMC(0) }
+	apu.Write(0x4010, 0xCF)
+	apu.Write(0x4012, 0xFF)
+	apu.Write(0x4013, 0x00)
+	apu.Write(0x4015, 0x10)
+
+	if apu.dmc.address != 0xFFC0 || apu.dmc.bytesRemaining != 1 || apu.dmc.irq {
+		t.Error("did not restart a looping sample")
+	}
+}
+
+func TestAPUDMCWaitsForFill(t *testing.T) {
+	apu := NewAPU(cartridge.NTSC, 0)
+	var requested []uint16
+	apu.DMCRequest = func(address uint16) { requested = append(requested, address) }
+	apu.Write(0x4012, 0x01) // $C040
+	apu.Write(0x4013, 0x01) // 17 bytes
+	apu.Write(0x4015, 0x10)
+	stepAPU(apu, 10)
+
+	if len(requested) != 1 || requested[0] != 0xC040 || apu.dmc.bufferFull {
+		t.Error("did not ask for one byte and wait for it, asked for", requested)
+	}
+
+	apu.FillDMC(0xAA)
+	if apu.dmc.buffer != 0xAA || !apu.dmc.bufferFull || apu.dmc.address != 0xC041 || apu.dmc.bytesRemaining != 16 {
+		t.Error("did not take the byte it was given")
+	}
+}
+
+func TestAPUMixer(t *testing.T) {
+	apu := NewAPU(cartridge.NTSC, 0)
+
+	// the triangle holds its last value when it stops
+	apu.triangle.step = 16
+	if apu.Output() != 0 {
+		t.Error("silent channels should mix to 0")
+	}
+
+	apu.Write(0x4011, 0x7F)
+	if output := apu.Output(); output < 0.57 || output > 0.58 {
+		t.Error("DMC at full level mixed to", output)
+	}
+}
+
+func TestAPUSampleRate(t *testing.T) {
+	apu := NewAPU(cartridge.NTSC, 44100)
+
+	stepAPU(apu, NTSCClockRate)
+
+	if samples := len(apu.Samples()); samples != 44100 {
+		t.Error("made", samples, "samples in one second")
+	}
+
+	if len(apu.Samples()) != 0 {
+		t.Error("Samples did not clear the buffer")
+	}
+}
+
+func TestAPUSaveState(t *testing.T) {
+	apu := NewAPU(cartridge.NTSC, 0)
+	apu.Write(0x4015, 0x0F)
+	apu.Write(0x4000, 0x9F)
+	apu.Write(0x4002, 0x08)
+	apu.Write(0x4003, 0x08)
+	apu.Write(0x400E, 0x03)
+	apu.Write(0x400F, 0x08)
+	stepAPU(apu, 12345)
+
+	var state bytes.Buffer
+	if err := apu.SaveState(&state); err != nil {
+		t.Fatal(err)
+	}
+
+	restored := NewAPU(cartridge.NTSC, 0)
+	if err := restored.LoadState(&state); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 1000; i++ {
+		apu.Step()
+		restored.Step()
+		if apu.Output() != restored.Output() {
+			t.Fatal("did not produce the same output after restoring the state")
+		}
+	}
+
+	if restored.Read(0x4015) != apu.Read(0x4015) {
+		t.Error("did not restore the length counters")
+	}
+}
@@ -0,0 +1,291 @@
+package apu
+
+import (
+	"encoding/gob"
+	"io"
+
+	"github.com/DevinRiley/nes/cpu"
+)
+
+type apuState struct {
+	Cycles   uint64
+	Pulse1   pulseState
+	Pulse2   pulseState
+	Triangle triangleState
+	Noise    noiseState
+	DMC      dmcState
+
+	FrameCycle      uint
+	FiveStep        bool
+	IRQInhibit      bool
+	FrameIRQ        bool
+	FrameWrite      byte
+	FrameWriteDelay int
+	IRQLine         cpu.IRQSource
+	SampleClock     float64
+}
+
+type envelopeState struct {
+	Start    bool
+	Loop     bool
+	Constant bool
+	Volume   byte
+	Divider  byte
+	Decay    byte
+}
+
+type lengthCounterState struct {
+	Enabled bool
+	Halt    bool
+	Value   byte
+}
+
+type pulseState struct {
+	Envelope     envelopeState
+	Length       lengthCounterState
+	Duty         byte
+	Step         byte
+	Period       uint16
+	Timer        uint16
+	SweepEnabled bool
+	SweepNegate  bool
+	SweepReload  bool
+	SweepPeriod  byte
+	SweepShift   byte
+	SweepDivider byte
+}
+
+type triangleState struct {
+	Length       lengthCounterState
+	Control      bool
+	LinearPeriod byte
+	Linear       byte
+	LinearReload bool
+	Period       uint16
+	Timer        uint16
+	Step         byte
+}
+
+type noiseState struct {
+	Envelope envelopeState
+	Length   lengthCounterState
+	Mode     bool
+	Period   uint16
+	Timer    uint16
+	Shift    uint16
+}
+
+type dmcState struct {
+	IRQEnabled     bool
+	IRQ            bool
+	Loop           bool
+	Rate           uint16
+	Timer          uint16
+	Level          byte
+	SampleAddress  uint16
+	SampleLength   uint16
+	Address        uint16
+	BytesRemaining uint16
+	Buffer         byte
+	BufferFull     bool
+	Fetching       bool
+	Shift          byte
+	BitsRemaining  byte
+	Silence        bool
+}
+
+// SaveState writes the channels and the frame counter. Samples that
+// haven't been collected yet are not included.
+func (apu *APU) SaveState(w io.Writer) error {
+	state := apuState{
+		Cycles:          apu.cycles,
+		Pulse1:          apu.pulse1.saveState(),
+		Pulse2:          apu.pulse2.saveState(),
+		Triangle:        apu.triangle.saveState(),
+		Noise:           apu.noise.saveState(),
+		DMC:             apu.dmc.saveState(),
+		FrameCycle:      apu.frameCycle,
+		FiveStep:        apu.fiveStep,
+		IRQInhibit:      apu.irqInhibit,
+		FrameIRQ:        apu.frameIRQ,
+		FrameWrite:      apu.frameWrite,
+		FrameWriteDelay: apu.frameWriteDelay,
+		IRQLine:         apu.irqLine,
+		SampleClock:     apu.sampleClock,
+	}
+
+	return gob.NewEncoder(w).Encode(&state)
+}
+
+// LoadState restores the state written by SaveState.
+func (apu *APU) LoadState(r io.Reader) error {
+	var state apuState
+	if err := gob.NewDecoder(r).Decode(&state); err != nil {
+		return err
+	}
+
+	apu.cycles = state.Cycles
+	apu.pulse1.loadState(state.Pulse1)
+	apu.pulse2.loadState(state.Pulse2)
+	apu.triangle.loadState(state.Triangle)
+	apu.noise.loadState(state.Noise)
+	apu.dmc.loadState(state.DMC)
+	apu.frameCycle = state.FrameCycle
+	apu.fiveStep = state.FiveStep
+	apu.irqInhibit = state.IRQInhibit
+	apu.frameIRQ = state.FrameIRQ
+	apu.frameWrite = state.FrameWrite
+	apu.frameWriteDelay = state.FrameWriteDelay
+	apu.irqLine = state.IRQLine
+	apu.sampleClock = state.SampleClock
+	apu.samples = nil
+
+	return nil
+}
+
+func (envelope *envelope) saveState() envelopeState {
+	return envelopeState{
+		Start:    envelope.start,
+		Loop:     envelope.loop,
+		Constant: envelope.constant,
+		Volume:   envelope.volume,
+		Divider:  envelope.divider,
+		Decay:    envelope.decay,
+	}
+}
+
+func (envelope *envelope) loadState(state envelopeState) {
+	envelope.start = state.Start
+	envelope.loop = state.Loop
+	envelope.constant = state.Constant
+	envelope.volume = state.Volume
+	envelope.divider = state.Divider
+	envelope.decay = state.Decay
+}
+
+func (length *lengthCounter) saveState() lengthCounterState {
+	return lengthCounterState{Enabled: length.enabled, Halt: length.halt, Value: length.value}
+}
+
+func (length *lengthCounter) loadState(state lengthCounterState) {
+	length.enabled = state.Enabled
+	length.halt = state.Halt
+	length.value = state.Value
+}
+
+// the channel number is fixed when the APU is made, so it isn't saved
+func (pulse *pulse) saveState() pulseState {
+	return pulseState{
+		Envelope:     pulse.envelope.saveState(),
+		Length:       pulse.length.saveState(),
+		Duty:         pulse.duty,
+		Step:         pulse.step,
+		Period:       pulse.period,
+		Timer:        pulse.timer,
+		SweepEnabled: pulse.sweepEnabled,
+		SweepNegate:  pulse.sweepNegate,
+		SweepReload:  pulse.sweepReload,
+		SweepPeriod:  pulse.sweepPeriod,
+		SweepShift:   pulse.sweepShift,
+		SweepDivider: pulse.sweepDivider,
+	}
+}
+
+func (pulse *pulse) loadState(state pulseState) {
+	pulse.envelope.loadState(state.Envelope)
+	pulse.length.loadState(state.Length)
+	pulse.duty = state.Duty
+	pulse.step = state.Step
+	pulse.period = state.Period
+	pulse.timer = state.Timer
+	pulse.sweepEnabled = state.SweepEnabled
+	pulse.sweepNegate = state.SweepNegate
+	pulse.sweepReload = state.SweepReload
+	pulse.sweepPeriod = state.SweepPeriod
+	pulse.sweepShift = state.SweepShift
+	pulse.sweepDivider = state.SweepDivider
+}
+
+func (triangle *triangle) saveState() triangleState {
+	return triangleState{
+		Length:       triangle.length.saveState(),
+		Control:      triangle.control,
+		LinearPeriod: triangle.linearPeriod,
+		Linear:       triangle.linear,
+		LinearReload: triangle.linearReload,
+		Period:       triangle.period,
+		Timer:        triangle.timer,
+		Step:         triangle.step,
+	}
+}
+
+func (triangle *triangle) loadState(state triangleState) {
+	triangle.length.loadState(state.Length)
+	triangle.control = state.Control
+	triangle.linearPeriod = state.LinearPeriod
+	triangle.linear = state.Linear
+	triangle.linearReload = state.LinearReload
+	triangle.period = state.Period
+	triangle.timer = state.Timer
+	triangle.step = state.Step
+}
+
+func (noise *noise) saveState() noiseState {
+	return noiseState{
+		Envelope: noise.envelope.saveState(),
+		Length:   noise.length.saveState(),
+		Mode:     noise.mode,
+		Period:   noise.period,
+		Timer:    noise.timer,
+		Shift:    noise.shift,
+	}
+}
+
+func (noise *noise) loadState(state noiseState) {
+	noise.envelope.loadState(state.Envelope)
+	noise.length.loadState(state.Length)
+	noise.mode = state.Mode
+	noise.period = state.Period
+	noise.timer = state.Timer
+	noise.shift = state.Shift
+}
+
+func (dmc *dmc) saveState() dmcState {
+	return dmcState{
+		IRQEnabled:     dmc.irqEnabled,
+		IRQ:            dmc.irq,
+		Loop:           dmc.loop,
+		Rate:           dmc.rate,
+		Timer:          dmc.timer,
+		Level:          dmc.level,
+		SampleAddress:  dmc.sampleAddress,
+		SampleLength:   dmc.sampleLength,
+		Address:        dmc.address,
+		BytesRemaining: dmc.bytesRemaining,
+		Buffer:         dmc.buffer,
+		BufferFull:     dmc.bufferFull,
+		Fetching:       dmc.fetching,
+		Shift:          dmc.shift,
+		BitsRemaining:  dmc.bitsRemaining,
+		Silence:        dmc.silence,
+	}
+}
+
+func (dmc *dmc) loadState(state dmcState) {
+	dmc.irqEnabled = state.IRQEnabled
+	dmc.irq = state.IRQ
+	dmc.loop = state.Loop
+	dmc.rate = state.Rate
+	dmc.timer = state.Timer
+	dmc.level = state.Level
+	dmc.sampleAddress = state.SampleAddress
+	dmc.sampleLength = state.SampleLength
+	dmc.address = state.Address
+	dmc.bytesRemaining = state.BytesRemaining
+	dmc.buffer = state.Buffer
+	dmc.bufferFull = state.BufferFull
+	dmc.fetching = state.Fetching
+	dmc.shift = state.Shift
+	dmc.bitsRemaining = state.BitsRemaining
+	dmc.silence = state.Silence
+}
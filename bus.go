@@ -0,0 +1,82 @@
+// Package nes puts the CPU, PPU, APU and cartridge together into an NES.
+package nes
+
+import "github.com/DevinRiley/nes/cpu"
+
+// Bus implements the NES CPU memory map:
+//
+// $0000-$07FF  2KB internal RAM
+// $0800-$1FFF  Mirrors of $0000-$07FF
+// $2000-$2007  PPU registers
+// $2008-$3FFF  Mirrors of $2000-$2007 (repeats every 8 bytes)
+// $4000-$4017  APU and I/O registers
+// $4018-$401F  APU and I/O functionality that is normally disabled
+// $4020-$FFFF  Cartridge space: PRG ROM, PRG RAM, and mapper registers
+//
+// Devices that are not attached read back as open bus, meaning the last
+// value that was driven onto the data bus.
+type Bus struct {
+	RAM       [0x800]byte
+	PPU       cpu.Bus // receives addresses $2000-$2007
+	IO        cpu.Bus // receives addresses $4000-$401F
+	Cartridge cpu.Bus // receives addresses $4020-$FFFF
+	openBus   byte
+}
+
+func (bus *Bus) Read(address uint16) byte {
+	switch {
+	case address < 0x2000:
+		bus.openBus = bus.RAM[address&0x07FF]
+	case address < 0x4000:
+		if bus.PPU != nil {
+			bus.openBus = bus.PPU.Read(0x2000 | address&0x0007)
+		}
+	case address < 0x4020:
+		if bus.IO != nil {
+			bus.openBus = bus.IO.Read(address)
+		}
+	default:
+		if bus.Cartridge != nil {
+			bus.openBus = bus.Cartridge.Read(address)
+		}
+	}
+
+	return bus.openBus
+}
+
+// Peek reads RAM and the cartridge without changing open bus. The PPU
+// and APU registers change when they are read, so they aren't peeked and
+// read as $FF.
+func (bus *Bus) Peek(address uint16) byte {
+	switch {
+	case address < 0x2000:
+		return bus.RAM[address&0x07FF]
+	case address < 0x4020:
+		return 0xFF
+	case bus.Cartridge != nil:
+		return bus.Cartridge.Read(address)
+	}
+
+	return bus.openBus
+}
+
+func (bus *Bus) Write(address uint16, value byte) {
+	bus.openBus = value
+
+	switch {
+	case address < 0x2000:
+		bus.RAM[address&0x07FF] = value
+	case address < 0x4000:
+		if bus.PPU != nil {
+			bus.PPU.Write(0x2000|address&0x0007, value)
+		}
+	case address < 0x4020:
+		if bus.IO != nil {
+			bus.IO.Write(address, value)
+		}
+	default:
+		if bus.Cartridge != nil {
+			bus.Cartridge.Write(address, value)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package nes
+
+import (
+	"testing"
+
+	"github.com/DevinRiley/nes/cpu"
+)
+
+type testDevice struct {
+	cpu.Memory
+	lastWrite uint16
+}
+
+func (d *testDevice) Write(address uint16, value byte) {
+	d.lastWrite = address
+	d.Memory.Write(address, value)
+}
+
+func TestBusMirrorsInternalRAM(t *testing.T) {
+	bus := &Bus{}
+	bus.Write(0x0017, 0x42)
+
+	for _, address := range []uint16{0x0017, 0x0817, 0x1017, 0x1817} {
+		if bus.Read(address) != 0x42 {
+			t.Errorf("did not mirror RAM at %04X, got %02X", address, bus.Read(address))
+		}
+	}
+}
+
+func TestBusMirrorsPPURegisters(t *testing.T) {
+	ppu := &testDevice{}
+	bus := &Bus{PPU: ppu}
+	bus.Write(0x3FFE, 0x80)
+
+	if ppu.lastWrite != 0x2006 {
+		t.Errorf("did not mirror PPU register, wrote to %04X", ppu.lastWrite)
+	}
+
+	if bus.Read(0x200E) != 0x80 {
+		t.Error("did not read back mirrored PPU register")
+	}
+}
+
+func TestBusRoutesIO(t *testing.T) {
+	io := &testDevice{}
+	bus := &Bus{IO: io}
+	bus.Write(0x4015, 0x0F)
+
+	if io.lastWrite != 0x4015 || io.Memory[0x4015] != 0x0F {
+		t.Error("did not route APU register write to I/O device")
+	}
+}
+
+func TestBusRoutesCartridge(t *testing.T) {
+	cartridge := &testDevice{}
+	bus := &Bus{Cartridge: cartridge}
+	cartridge.Memory[0xFFFC] = 0x04
+	bus.Write(0x4020, 0x01)
+
+	if cartridge.lastWrite != 0x4020 {
+		t.Error("did not route write to cartridge")
+	}
+
+	if bus.Read(0xFFFC) != 0x04 {
+		t.Error("did not read from cartridge")
+	}
+}
+
+func TestBusOpenBus(t *testing.T) {
+	bus := &Bus{}
+	bus.Write(0x0000, 0x3C)
+	bus.Read(0x0000)
+
+	if bus.Read(0x8000) != 0x3C {
+		t.Error("unmapped read did not return open bus value")
+	}
+}
+
+func TestCPUUsesBus(t *testing.T) {
+	bus := &Bus{}
+	cpu := cpu.NewCPU()
+	cpu.Bus = bus
+	cpu.PC = 0x0800 // mirror of $0000
+	cpu.A = 0x99
+	bus.RAM[0] = 0x85 // STA $10
+	bus.RAM[1] = 0x10
+	cpu.Exec()
+
+	if bus.Read(0x1810) != 0x99 {
+		t.Error("STA did not write through the bus")
+	}
+}
+
+func TestBusPeek(t *testing.T) {
+	ppu := &testDevice{}
+	cartridge := &testDevice{}
+	bus := &Bus{PPU: ppu, Cartridge: cartridge}
+	ppu.Memory[0x2002] = 0x80
+	cartridge.Memory[0x8000] = 0x4C
+	bus.Write(0x0817, 0x42)
+
+	if bus.Peek(0x0017) != 0x42 || bus.Peek(0x8000) != 0x4C {
+		t.Error("did not peek RAM and the cartridge")
+	}
+	if bus.Peek(0x2002) != 0xFF {
+		t.Error("peeked a PPU register")
+	}
+	if bus.openBus != 0x42 {
+		t.Error("did not leave open bus alone")
+	}
+}
@@ -0,0 +1,154 @@
+package cartridge
+
+import (
+	"encoding/gob"
+	"fmt"
+	"io"
+)
+
+// Mapper is the circuitry on a cartridge board. It decides what the CPU
+// sees at $4020-$FFFF and what the PPU sees in the pattern tables, and
+// usually does so through bank switching registers that are written at
+// the same addresses as the ROM.
+type Mapper interface {
+	// Read and Write handle CPU accesses to $4020-$FFFF
+	Read(address uint16) byte
+	Write(address uint16, value byte)
+
+	// ReadPPU and WritePPU handle PPU accesses to the pattern tables at
+	// $0000-$1FFF. Every pattern fetch goes through ReadPPU, so boards
+	// that watch the PPU address bus can do so here.
+	ReadPPU(address uint16) byte
+	WritePPU(address uint16, value byte)
+
+	// Mirroring is the nametable mirroring the board currently selects
+	Mirroring() Mirroring
+
+	// IRQ reports whether the board is pulling the CPU's IRQ line low
+	IRQ() bool
+
+	// Step is called once per CPU cycle, and Scanline at the end of every
+	// scanline that the PPU renders
+	Step()
+	Scanline()
+
+	// SaveState and LoadState serialize the board's registers and RAM,
+	// but not its ROM. SaveRegisters leaves out the RAM that Memory
+	// returns, and LoadState leaves that RAM alone when reading it back.
+	SaveState(w io.Writer) error
+	SaveRegisters(w io.Writer) error
+	LoadState(r io.Reader) error
+
+	// Memory returns the board's RAM, PRG RAM first. The slices are the
+	// board's own memory and their sizes never change.
+	Memory() [][]byte
+}
+
+// MapperConstructor builds the mapper for a ROM
+type MapperConstructor func(rom *ROM) (Mapper, error)
+
+var mappers = map[uint16]MapperConstructor{}
+
+// RegisterMapper makes a mapper available to NewMapper under its iNES
+// mapper number.
+func RegisterMapper(number uint16, constructor MapperConstructor) {
+	mappers[number] = constructor
+}
+
+// UnsupportedMapperError is returned by NewMapper for mapper numbers that
+// have not been registered.
+type UnsupportedMapperError struct {
+	Mapper uint16
+}
+
+func (err *UnsupportedMapperError) Error() string {
+	return fmt.Sprintf("unsupported mapper %d", err.Mapper)
+}
+
+// NewMapper builds the mapper that ROM.Mapper asks for.
+func NewMapper(rom *ROM) (Mapper, error) {
+	constructor, ok := mappers[rom.Mapper]
+	if !ok {
+		return nil, &UnsupportedMapperError{rom.Mapper}
+	}
+
+	mapper, err := constructor(rom)
+	if err != nil {
+		return nil, err
+	}
+
+	// the trainer was copied into the board's PRG RAM at power on
+	for i, value := range rom.TrainerData {
+		mapper.Write(0x7000+uint16(i), value)
+	}
+
+	return mapper, nil
+}
+
+// mapperDefaults can be embedded by mappers that have no IRQ and don't
+// need to be clocked.
+type mapperDefaults struct{}
+
+func (mapperDefaults) IRQ() bool { return false }
+func (mapperDefaults) Step()     {}
+func (mapperDefaults) Scanline() {}
+
+// romMirroring is the mirroring hardwired on the board by the header
+func romMirroring(rom *ROM) Mirroring {
+	if rom.FourScreen {
+		return FourScreen
+	}
+
+	return rom.Mirroring
+}
+
+// romPRG checks that the ROM has as much PRG data as its header says and
+// that the size is a multiple of the bank size.
+func romPRG(rom *ROM, bankSize uint) ([]byte, error) {
+	if rom.PRGSize == 0 || rom.PRGSize%bankSize != 0 {
+		return nil, fmt.Errorf("mapper %d: PRG size %d is not a multiple of %d", rom.Mapper, rom.PRGSize, bankSize)
+	}
+
+	if uint(len(rom.PRGData)) != rom.PRGSize {
+		return nil, fmt.Errorf("mapper %d: expected %d bytes of PRG data, got %d", rom.Mapper, rom.PRGSize, len(rom.PRGData))
+	}
+
+	return rom.PRGData, nil
+}
+
+// romCHR returns the board's CHR memory. A ROM without CHR data has CHR
+// RAM instead, 8KB unless an NES 2.0 header says otherwise, and the
+// second return value is true.
+func romCHR(rom *ROM) ([]byte, bool, error) {
+	if rom.CHRSize == 0 {
+		size := rom.CHRRAMSize + rom.CHRNVRAMSize
+		if size < 0x2000 {
+			size = 0x2000
+		}
+		return make([]byte, size), true, nil
+	}
+
+	if uint(len(rom.CHRData)) != rom.CHRSize {
+		return nil, false, fmt.Errorf("mapper %d: expected %d bytes of CHR data, got %d", rom.Mapper, rom.CHRSize, len(rom.CHRData))
+	}
+
+	return rom.CHRData, false, nil
+}
+
+// boardMemory is a board's PRG RAM and, when it has no CHR ROM, its CHR
+// RAM
+func boardMemory(prgRAM []byte, chr []byte, chrRAM bool) [][]byte {
+	if chrRAM {
+		return [][]byte{prgRAM, chr}
+	}
+
+	return [][]byte{prgRAM}
+}
+
+func saveState(w io.Writer, state interface{}) error {
+	return gob.NewEncoder(w).Encode(state)
+}
+
+func loadState(r io.Reader, state interface{}) error {
+	return gob.NewDecoder(r).Decode(state)
+}
@@ -0,0 +1,135 @@
+package cartridge
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// testPRG makes PRG data where every 16KB bank is filled with its number
+func testPRG(size uint) []byte {
+	prg := make([]byte, size)
+	for i := range prg {
+		prg[i] = byte(i / 0x4000)
+	}
+
+	return prg
+}
+
+func TestNewMapperUnsupported(t *testing.T) {
+	_, err := NewMapper(&ROM{Mapper: 0xFF})
+
+	var unsupported *UnsupportedMapperError
+	if !errors.As(err, &unsupported) || unsupported.Mapper != 0xFF {
+		t.Error("did not return an UnsupportedMapperError, got", err)
+	}
+}
+
+func TestNROMBadPRGSize(t *testing.T) {
+	rom := &ROM{PRGSize: 0x2000, PRGData: make([]byte, 0x2000)}
+
+	if _, err := NewMapper(rom); err == nil {
+		t.Error("accepted 8KB of PRG")
+	}
+}
+
+func TestNROM16KMirrorsPRG(t *testing.T) {
+	rom := &ROM{PRGSize: 0x4000, PRGData: testPRG(0x4000)}
+	rom.PRGData[0x3FFC] = 0x34
+
+	mapper, err := NewMapper(rom)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if mapper.Read(0x8000) != 0x00 || mapper.Read(0xFFFC) != 0x34 || mapper.Read(0xBFFC) != 0x34 {
+		t.Error("did not mirror 16KB of PRG into $8000 and $C000")
+	}
+}
+
+func TestNROM32K(t *testing.T) {
+	rom := &ROM{PRGSize: 0x8000, PRGData: testPRG(0x8000)}
+
+	mapper, err := NewMapper(rom)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if mapper.Read(0x8000) != 0x00 || mapper.Read(0xC000) != 0x01 {
+		t.Error("did not map 32KB of PRG into $8000-$FFFF")
+	}
+
+	mapper.Write(0xC000, 0xFF)
+	if mapper.Read(0xC000) != 0x01 {
+		t.Error("wrote to PRG ROM")
+	}
+}
+
+func TestNROMPRGRAM(t *testing.T) {
+	mapper, _ := NewMapper(&ROM{PRGSize: 0x4000, PRGData: testPRG(0x4000)})
+
+	mapper.Write(0x6123, 0x42)
+
+	if mapper.Read(0x6123) != 0x42 {
+		t.Error("did not provide PRG RAM at $6000")
+	}
+}
+
+func TestNROMCHRROM(t *testing.T) {
+	rom := &ROM{PRGSize: 0x4000, PRGData: testPRG(0x4000), CHRSize: 0x2000, CHRData: make([]byte, 0x2000)}
+	rom.CHRData[0x1234] = 0x56
+
+	mapper, _ := NewMapper(rom)
+	mapper.WritePPU(0x1234, 0x00)
+
+	if mapper.ReadPPU(0x1234) != 0x56 {
+		t.Error("did not map CHR ROM read only")
+	}
+}
+
+func TestNROMCHRRAM(t *testing.T) {
+	mapper, _ := NewMapper(&ROM{PRGSize: 0x4000, PRGData: testPRG(0x4000)})
+
+	mapper.WritePPU(0x1234, 0x56)
+
+	if mapper.ReadPPU(0x1234) != 0x56 {
+		t.Error("did not provide CHR RAM when the ROM has no CHR")
+	}
+}
+
+func TestNROMMirroring(t *testing.T) {
+	rom := &ROM{PRGSize: 0x4000, PRGData: testPRG(0x4000), Mirroring: Horizontal}
+	mapper, _ := NewMapper(rom)
+
+	if mapper.Mirroring() != Horizontal {
+		t.Error("did not use the ROM's mirroring")
+	}
+
+	rom.FourScreen = true
+	mapper, _ = NewMapper(rom)
+
+	if mapper.Mirroring() != FourScreen {
+		t.Error("did not use four-screen mirroring")
+	}
+}
+
+func TestNROMSaveState(t *testing.T) {
+	rom := &ROM{PRGSize: 0x4000, PRGData: testPRG(0x4000)}
+	mapper, _ := NewMapper(rom)
+	mapper.Write(0x6000, 0x11)
+	mapper.WritePPU(0x0000, 0x22)
+
+	var state bytes.Buffer
+	if err := mapper.SaveState(&state); err != nil {
+		t.Fatal(err)
+	}
+
+	restored, _ := NewMapper(rom)
+	if err := restored.LoadState(&state); err != nil {
+		t.Fatal(err)
+	}
+
+	if restored.Read(0x6000) != 0x11 || restored.ReadPPU(0x0000) != 0x22 {
+		t.Error("did not restore PRG RAM and CHR RAM")
+	}
+}
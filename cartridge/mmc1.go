@@ -0,0 +1,316 @@
+package cartridge
+
+import "io"
+
+func init() {
+	RegisterMapper(1, newMMC1)
+}
+
+// MMC1 (mapper 1) is used by the SxROM boards. Its registers are loaded
+// one bit at a time through a 5 bit shift register: five writes to
+// $8000-$FFFF shift in bit 0 of each value, and the fifth write copies
+// the result into the register picked by address bits 13 and 14.
+//
+// $8000-$9FFF  Control: mirroring, PRG and CHR banking modes
+// $A000-$BFFF  CHR bank 0
+// $C000-$DFFF  CHR bank 1
+// $E000-$FFFF  PRG bank and PRG RAM enable
+//
+// The boards with CHR RAM reuse the upper bits of the CHR bank registers:
+// SNROM disables PRG RAM with bit 4, SOROM and SXROM bank their 16KB and
+// 32KB of PRG RAM with bits 2-3, and SUROM and SXROM select which 256KB
+// half of their 512KB of PRG ROM is used with bit 4.
+type MMC1 struct {
+	mapperDefaults
+
+	prg    []byte
+	chr    []byte
+	chrRAM bool
+	prgRAM [0x8000]byte
+
+	shift      byte
+	shiftCount byte
+	control    byte
+	chrBank0   byte
+	chrBank1   byte
+	prgBank    byte
+
+	// the last pattern table the PPU fetched from, which decides which
+	// CHR bank register the SxROM bits come from in 4KB mode
+	chrHigh bool
+
+	// writes on consecutive cycles, like the dummy write of a read
+	// modify write instruction, only reach the shift register once
+	writeThisCycle bool
+	writeLastCycle bool
+}
+
+type mmc1State struct {
+	Shift      byte
+	ShiftCount byte
+	Control    byte
+	CHRBank0   byte
+	CHRBank1   byte
+	PRGBank    byte
+	CHRHigh    bool
+	WriteThis  bool
+	WriteLast  bool
+	PRGRAM     []byte
+	CHRRAM     []byte
+}
+
+func newMMC1(rom *ROM) (Mapper, error) {
+	prg, err := romPRG(rom, 0x4000)
+	if err != nil {
+		return nil, err
+	}
+
+	chr, chrRAM, err := romCHR(rom)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MMC1{
+		prg:     prg,
+		chr:     chr,
+		chrRAM:  chrRAM,
+		control: 0x0C,
+	}, nil
+}
+
+func (mmc1 *MMC1) Read(address uint16) byte {
+	switch {
+	case address >= 0x8000:
+		return mmc1.prg[mmc1.prgAddress(address)]
+	case address >= 0x6000:
+		if mmc1.prgRAMEnabled() {
+			return mmc1.prgRAM[mmc1.prgRAMAddress(address)]
+		}
+	}
+
+	return 0
+}
+
+func (mmc1 *MMC1) Write(address uint16, value byte) {
+	switch {
+	case address >= 0x8000:
+		mmc1.writeRegister(address, value)
+	case address >= 0x6000:
+		if mmc1.prgRAMEnabled() {
+			mmc1.prgRAM[mmc1.prgRAMAddress(address)] = value
+		}
+	}
+}
+
+func (mmc1 *MMC1) writeRegister(address uint16, value byte) {
+	mmc1.writeThisCycle = true
+	if mmc1.writeLastCycle {
+		return
+	}
+
+	if value&0x80 != 0 {
+		mmc1.shift = 0
+		mmc1.shiftCount = 0
+		mmc1.control |= 0x0C
+		return
+	}
+
+	mmc1.shift |= (value & 0x01) << mmc1.shiftCount
+	mmc1.shiftCount++
+	if mmc1.shiftCount < 5 {
+		return
+	}
+
+	switch address & 0xE000 {
+	case 0x8000:
+		mmc1.control = mmc1.shift
+	case 0xA000:
+		mmc1.chrBank0 = mmc1.shift
+	case 0xC000:
+		mmc1.chrBank1 = mmc1.shift
+	case 0xE000:
+		mmc1.prgBank = mmc1.shift
+	}
+
+	mmc1.shift = 0
+	mmc1.shiftCount = 0
+}
+
+// prgAddress maps $8000-$FFFF into PRG ROM according to the PRG mode in
+// control bits 2-3:
+//
+// 0, 1  32KB at $8000, ignoring the low bit of the bank number
+// 2     first bank fixed at $8000, 16KB bank switched at $C000
+// 3     16KB bank switched at $8000, last bank fixed at $C000
+func (mmc1 *MMC1) prgAddress(address uint16) int {
+	bank := mmc1.prgBank & 0x0F
+	outer := mmc1.prgOuterBank()
+	high := address >= 0xC000
+
+	switch mmc1.control >> 2 & 0x03 {
+	case 0, 1:
+		bank &^= 0x01
+		if high {
+			bank |= 0x01
+		}
+	case 2:
+		if !high {
+			bank = 0
+		}
+	case 3:
+		if high {
+			bank = 0x0F
+		}
+	}
+
+	banks := len(mmc1.prg) / 0x4000
+	return (int(outer|bank)%banks)*0x4000 | int(address&0x3FFF)
+}
+
+// prgOuterBank selects the 256KB half of the PRG on SUROM and SXROM
+func (mmc1 *MMC1) prgOuterBank() byte {
+	if len(mmc1.prg) <= 0x40000 {
+		return 0
+	}
+
+	return mmc1.sxromBits() & 0x10
+}
+
+// sxromBits is the CHR bank register that the board's extra lines are
+// wired to. In 8KB CHR mode that is always CHR bank 0, in 4KB mode it is
+// the register for the pattern table the PPU last fetched from.
+func (mmc1 *MMC1) sxromBits() byte {
+	if mmc1.control&0x10 != 0 && mmc1.chrHigh {
+		return mmc1.chrBank1
+	}
+
+	return mmc1.chrBank0
+}
+
+func (mmc1 *MMC1) prgRAMEnabled() bool {
+	if mmc1.prgBank&0x10 != 0 {
+		return false
+	}
+
+	// SNROM's PRG RAM disable line
+	if mmc1.chrRAM && len(mmc1.prg) <= 0x40000 && mmc1.sxromBits()&0x10 != 0 {
+		return false
+	}
+
+	return true
+}
+
+func (mmc1 *MMC1) prgRAMAddress(address uint16) int {
+	bank := 0
+	if mmc1.chrRAM {
+		bank = int(mmc1.sxromBits() >> 2 & 0x03)
+	}
+
+	return bank*0x2000 | int(address&0x1FFF)
+}
+
+func (mmc1 *MMC1) chrAddress(address uint16) int {
+	address &= 0x1FFF
+	var bank int
+
+	if mmc1.control&0x10 == 0 {
+		// 8KB mode ignores the low bit of the bank number
+		bank = int(mmc1.chrBank0&^0x01) | int(address>>12)
+	} else if address < 0x1000 {
+		bank = int(mmc1.chrBank0)
+	} else {
+		bank = int(mmc1.chrBank1)
+	}
+
+	banks := len(mmc1.chr) / 0x1000
+	return (bank%banks)*0x1000 | int(address&0x0FFF)
+}
+
+func (mmc1 *MMC1) ReadPPU(address uint16) byte {
+	mmc1.chrHigh = address&0x1000 != 0
+	return mmc1.chr[mmc1.chrAddress(address)]
+}
+
+func (mmc1 *MMC1) WritePPU(address uint16, value byte) {
+	mmc1.chrHigh = address&0x1000 != 0
+	if mmc1.chrRAM {
+		mmc1.chr[mmc1.chrAddress(address)] = value
+	}
+}
+
+// Mirroring is selected by control bits 0-1. MMC1 documentation names
+// modes 2 and 3 after the mirroring, which is the opposite of the
+// arrangement the Mirroring constants are named after.
+func (mmc1 *MMC1) Mirroring() Mirroring {
+	switch mmc1.control & 0x03 {
+	case 0:
+		return SingleScreenLower
+	case 1:
+		return SingleScreenUpper
+	case 2:
+		return Horizontal
+	default:
+		return Vertical
+	}
+}
+
+func (mmc1 *MMC1) Step() {
+	mmc1.writeLastCycle = mmc1.writeThisCycle
+	mmc1.writeThisCycle = false
+}
+
+func (mmc1 *MMC1) SaveState(w io.Writer) error {
+	state := mmc1.registerState()
+	state.PRGRAM = mmc1.prgRAM[:]
+	if mmc1.chrRAM {
+		state.CHRRAM = mmc1.chr
+	}
+
+	return saveState(w, &state)
+}
+
+func (mmc1 *MMC1) SaveRegisters(w io.Writer) error {
+	state := mmc1.registerState()
+	return saveState(w, &state)
+}
+
+func (mmc1 *MMC1) Memory() [][]byte {
+	return boardMemory(mmc1.prgRAM[:], mmc1.chr, mmc1.chrRAM)
+}
+
+func (mmc1 *MMC1) registerState() mmc1State {
+	return mmc1State{
+		Shift:      mmc1.shift,
+		ShiftCount: mmc1.shiftCount,
+		Control:    mmc1.control,
+		CHRBank0:   mmc1.chrBank0,
+		CHRBank1:   mmc1.chrBank1,
+		PRGBank:    mmc1.prgBank,
+		CHRHigh:    mmc1.chrHigh,
+		WriteThis:  mmc1.writeThisCycle,
+		WriteLast:  mmc1.writeLastCycle,
+	}
+}
+
+func (mmc1 *MMC1) LoadState(r io.Reader) error {
+	var state mmc1State
+	if err := loadState(r, &state); err != nil {
+		return err
+	}
+
+	mmc1.shift = state.Shift
+	mmc1.shiftCount = state.ShiftCount
+	mmc1.control = state.Control
+	mmc1.chrBank0 = state.CHRBank0
+	mmc1.chrBank1 = state.CHRBank1
+	mmc1.prgBank = state.PRGBank
+	mmc1.chrHigh = state.CHRHigh
+	mmc1.writeThisCycle = state.WriteThis
+	mmc1.writeLastCycle = state.WriteLast
+	copy(mmc1.prgRAM[:], state.PRGRAM)
+	if mmc1.chrRAM {
+		copy(mmc1.chr, state.CHRRAM)
+	}
+
+	return nil
+}
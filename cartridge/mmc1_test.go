@@ -0,0 +1,219 @@
+package cartridge
+
+import (
+	"bytes"
+	"testing"
+)
+
+func newTestMMC1(t *testing.T, prgSize uint, chrSize uint) *MMC1 {
+	rom := &ROM{Mapper: 1, PRGSize: prgSize, PRGData: testPRG(prgSize), CHRSize: chrSize}
+	rom.CHRData = make([]byte, chrSize)
+	for i := range rom.CHRData {
+		rom.CHRData[i] = byte(i / 0x1000)
+	}
+
+	mapper, err := NewMapper(rom)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return mapper.(*MMC1)
+}
+
+// writeMMC1 loads a register through the shift register, a bit at a time
+func writeMMC1(mmc1 *MMC1, address uint16, value byte) {
+	for i := 0; i < 5; i++ {
+		mmc1.Step()
+		mmc1.Write(address, value>>i&0x01)
+		mmc1.Step()
+	}
+}
+
+func TestMMC1PowerOnFixesLastBank(t *testing.T) {
+	mmc1 := newTestMMC1(t, 0x20000, 0x2000)
+
+	if mmc1.Read(0xC000) != 0x07 || mmc1.Read(0x8000) != 0x00 {
+		t.Error("did not start in PRG mode 3 with the last bank at $C000")
+	}
+}
+
+func TestMMC1ShiftRegister(t *testing.T) {
+	mmc1 := newTestMMC1(t, 0x20000, 0x2000)
+
+	writeMMC1(mmc1, 0xE000, 0x05)
+
+	if mmc1.prgBank != 0x05 || mmc1.Read(0x8000) != 0x05 {
+		t.Error("did not load the PRG bank through the shift register")
+	}
+}
+
+func TestMMC1ResetBit(t *testing.T) {
+	mmc1 := newTestMMC1(t, 0x20000, 0x2000)
+	writeMMC1(mmc1, 0x8000, 0x00)
+
+	mmc1.Step()
+	mmc1.Write(0xE000, 0x01)
+	mmc1.Step()
+	mmc1.Step()
+	mmc1.Write(0x8000, 0x80)
+	mmc1.Step()
+	writeMMC1(mmc1, 0xE000, 0x02)
+
+	if mmc1.prgBank != 0x02 {
+		t.Error("did not reset the shift register")
+	}
+
+	if mmc1.control&0x0C != 0x0C {
+		t.Error("did not switch to PRG mode 3 on reset")
+	}
+}
+
+func TestMMC1IgnoresConsecutiveWrites(t *testing.T) {
+	mmc1 := newTestMMC1(t, 0x20000, 0x2000)
+
+	// like INC $E000, which writes the old value and then the new one
+	for i := 0; i < 5; i++ {
+		mmc1.Step()
+		mmc1.Write(0xE000, 0x01)
+		mmc1.Step()
+		mmc1.Write(0xE000, 0x00)
+		mmc1.Step()
+	}
+
+	if mmc1.prgBank != 0x1F {
+		t.Errorf("did not ignore the second of two consecutive writes, PRG bank is %02X", mmc1.prgBank)
+	}
+}
+
+func TestMMC1PRGModes(t *testing.T) {
+	mmc1 := newTestMMC1(t, 0x20000, 0x2000)
+	writeMMC1(mmc1, 0xE000, 0x03)
+
+	writeMMC1(mmc1, 0x8000, 0x00)
+	if mmc1.Read(0x8000) != 0x02 || mmc1.Read(0xC000) != 0x03 {
+		t.Error("did not switch 32KB ignoring the low bit")
+	}
+
+	writeMMC1(mmc1, 0x8000, 0x08)
+	if mmc1.Read(0x8000) != 0x00 || mmc1.Read(0xC000) != 0x03 {
+		t.Error("did not fix the first bank at $8000")
+	}
+
+	writeMMC1(mmc1, 0x8000, 0x0C)
+	if mmc1.Read(0x8000) != 0x03 || mmc1.Read(0xC000) != 0x07 {
+		t.Error("did not fix the last bank at $C000")
+	}
+}
+
+func TestMMC1CHRModes(t *testing.T) {
+	mmc1 := newTestMMC1(t, 0x20000, 0x20000)
+	writeMMC1(mmc1, 0xA000, 0x05)
+	writeMMC1(mmc1, 0xC000, 0x09)
+
+	writeMMC1(mmc1, 0x8000, 0x0C)
+	if mmc1.ReadPPU(0x0000) != 0x04 || mmc1.ReadPPU(0x1000) != 0x05 {
+		t.Error("did not switch 8KB of CHR ignoring the low bit")
+	}
+
+	writeMMC1(mmc1, 0x8000, 0x1C)
+	if mmc1.ReadPPU(0x0000) != 0x05 || mmc1.ReadPPU(0x1000) != 0x09 {
+		t.Error("did not switch two 4KB CHR banks")
+	}
+}
+
+func TestMMC1Mirroring(t *testing.T) {
+	mmc1 := newTestMMC1(t, 0x20000, 0x2000)
+	expected := []Mirroring{SingleScreenLower, SingleScreenUpper, Horizontal, Vertical}
+
+	for mode, mirroring := range expected {
+		writeMMC1(mmc1, 0x8000, 0x0C|byte(mode))
+
+		if mmc1.Mirroring() != mirroring {
+			t.Errorf("mirroring mode %d selected %d", mode, mmc1.Mirroring())
+		}
+	}
+}
+
+func TestMMC1PRGRAMEnable(t *testing.T) {
+	mmc1 := newTestMMC1(t, 0x20000, 0x2000)
+	mmc1.Write(0x6000, 0x42)
+
+	writeMMC1(mmc1, 0xE000, 0x10)
+	if mmc1.Read(0x6000) != 0x00 {
+		t.Error("did not disable PRG RAM")
+	}
+
+	writeMMC1(mmc1, 0xE000, 0x00)
+	if mmc1.Read(0x6000) != 0x42 {
+		t.Error("did not enable PRG RAM")
+	}
+}
+
+func TestMMC1SNROMDisablesPRGRAM(t *testing.T) {
+	mmc1 := newTestMMC1(t, 0x40000, 0)
+	mmc1.Write(0x6000, 0x42)
+
+	writeMMC1(mmc1, 0xA000, 0x10)
+
+	if mmc1.Read(0x6000) != 0x00 {
+		t.Error("did not disable PRG RAM with CHR bank bit 4")
+	}
+}
+
+func TestMMC1SUROMOuterBank(t *testing.T) {
+	mmc1 := newTestMMC1(t, 0x80000, 0)
+	writeMMC1(mmc1, 0xE000, 0x02)
+
+	if mmc1.Read(0x8000) != 0x02 || mmc1.Read(0xC000) != 0x0F {
+		t.Error("did not use the first 256KB")
+	}
+
+	writeMMC1(mmc1, 0xA000, 0x10)
+
+	if mmc1.Read(0x8000) != 0x12 || mmc1.Read(0xC000) != 0x1F {
+		t.Error("did not select the second 256KB with CHR bank bit 4")
+	}
+
+	mmc1.Write(0x6000, 0x42)
+	if mmc1.Read(0x6000) != 0x42 {
+		t.Error("SUROM should not use bit 4 to disable PRG RAM")
+	}
+}
+
+func TestMMC1SXROMPRGRAMBanks(t *testing.T) {
+	mmc1 := newTestMMC1(t, 0x80000, 0)
+
+	writeMMC1(mmc1, 0xA000, 0x00)
+	mmc1.Write(0x6000, 0x11)
+	writeMMC1(mmc1, 0xA000, 0x0C)
+	mmc1.Write(0x6000, 0x22)
+
+	if mmc1.Read(0x6000) != 0x22 {
+		t.Error("did not switch PRG RAM bank")
+	}
+
+	writeMMC1(mmc1, 0xA000, 0x00)
+	if mmc1.Read(0x6000) != 0x11 {
+		t.Error("did not keep PRG RAM banks separate")
+	}
+}
+
+func TestMMC1SaveState(t *testing.T) {
+	mmc1 := newTestMMC1(t, 0x20000, 0x2000)
+	writeMMC1(mmc1, 0xE000, 0x05)
+	mmc1.Write(0x6000, 0x42)
+
+	var state bytes.Buffer
+	if err := mmc1.SaveState(&state); err != nil {
+		t.Fatal(err)
+	}
+
+	restored := newTestMMC1(t, 0x20000, 0x2000)
+	if err := restored.LoadState(&state); err != nil {
+		t.Fatal(err)
+	}
+
+	if restored.Read(0x8000) != 0x05 || restored.Read(0x6000) != 0x42 {
+		t.Error("did not restore the banks and PRG RAM")
+	}
+}
@@ -0,0 +1,309 @@
+package cartridge
+
+import "io"
+
+func init() {
+	RegisterMapper(4, newMMC3)
+}
+
+// MMC3 (mapper 4) is used by the TxROM boards. It has 8KB PRG banks, 2KB
+// and 1KB CHR banks, and a scanline counter that raises an IRQ.
+//
+// $8000-$9FFE even  Bank select: target register, PRG mode, CHR inversion
+// $8001-$9FFF odd   Bank data for the selected register
+// $A000-$BFFE even  Mirroring
+// $A001-$BFFF odd   PRG RAM protect
+// $C000-$DFFE even  IRQ latch
+// $C001-$DFFF odd   IRQ reload
+// $E000-$FFFE even  IRQ disable, which also acknowledges the IRQ
+// $E001-$FFFF odd   IRQ enable
+//
+// The counter is clocked by rising edges of PPU A12, which happen once per
+// scanline when the background and sprites use different pattern tables.
+// A12 has to stay low for a few CPU cycles first, which filters out the
+// quick toggles between the sprite fetches.
+type MMC3 struct {
+	prg        []byte
+	chr        []byte
+	chrRAM     bool
+	prgRAM     [0x2000]byte
+	fourScreen bool
+
+	bankSelect byte
+	registers  [8]byte
+	mirroring  byte
+	prgRAMBits byte // bit 7 enables PRG RAM, bit 6 protects it from writes
+
+	irqLatch   byte
+	irqCounter byte
+	irqReload  bool
+	irqEnabled bool
+	irq        bool
+
+	a12          bool
+	a12LowCycles int
+}
+
+// the number of CPU cycles A12 must stay low before a rising edge counts
+const mmc3A12Filter = 3
+
+type mmc3State struct {
+	BankSelect   byte
+	Registers    [8]byte
+	Mirroring    byte
+	PRGRAMBits   byte
+	IRQLatch     byte
+	IRQCounter   byte
+	IRQReload    bool
+	IRQEnabled   bool
+	IRQ          bool
+	A12          bool
+	A12LowCycles int
+	PRGRAM       []byte
+	CHRRAM       []byte
+}
+
+func newMMC3(rom *ROM) (Mapper, error) {
+	prg, err := romPRG(rom, 0x2000)
+	if err != nil {
+		return nil, err
+	}
+
+	chr, chrRAM, err := romCHR(rom)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MMC3{
+		prg:        prg,
+		chr:        chr,
+		chrRAM:     chrRAM,
+		fourScreen: rom.FourScreen,
+		prgRAMBits: 0x80,
+	}, nil
+}
+
+func (mmc3 *MMC3) Read(address uint16) byte {
+	switch {
+	case address >= 0x8000:
+		return mmc3.prg[mmc3.prgAddress(address)]
+	case address >= 0x6000:
+		if mmc3.prgRAMBits&0x80 != 0 {
+			return mmc3.prgRAM[address-0x6000]
+		}
+	}
+
+	return 0
+}
+
+func (mmc3 *MMC3) Write(address uint16, value byte) {
+	if address >= 0x6000 && address < 0x8000 {
+		if mmc3.prgRAMBits&0xC0 == 0x80 {
+			mmc3.prgRAM[address-0x6000] = value
+		}
+		return
+	}
+
+	if address < 0x8000 {
+		return
+	}
+
+	even := address&0x01 == 0
+	switch address & 0xE000 {
+	case 0x8000:
+		if even {
+			mmc3.bankSelect = value
+		} else {
+			mmc3.registers[mmc3.bankSelect&0x07] = value
+		}
+	case 0xA000:
+		if even {
+			mmc3.mirroring = value & 0x01
+		} else {
+			mmc3.prgRAMBits = value & 0xC0
+		}
+	case 0xC000:
+		if even {
+			mmc3.irqLatch = value
+		} else {
+			mmc3.irqCounter = 0
+			mmc3.irqReload = true
+		}
+	case 0xE000:
+		if even {
+			mmc3.irqEnabled = false
+			mmc3.irq = false
+		} else {
+			mmc3.irqEnabled = true
+		}
+	}
+}
+
+// prgAddress maps $8000-$FFFF onto 8KB PRG banks. R6 and R7 are
+// switchable, and the last two banks are fixed. PRG mode 1 swaps the
+// places of R6 and the second to last bank.
+func (mmc3 *MMC3) prgAddress(address uint16) int {
+	banks := len(mmc3.prg) / 0x2000
+	slot := int(address-0x8000) / 0x2000
+	if mmc3.bankSelect&0x40 != 0 && slot != 1 && slot != 3 {
+		slot ^= 0x02
+	}
+
+	var bank int
+	switch slot {
+	case 0:
+		bank = int(mmc3.registers[6])
+	case 1:
+		bank = int(mmc3.registers[7])
+	case 2:
+		bank = banks - 2
+	case 3:
+		bank = banks - 1
+	}
+
+	return (bank%banks)*0x2000 | int(address&0x1FFF)
+}
+
+// chrAddress maps the pattern tables onto CHR banks: R0 and R1 are 2KB
+// banks at $0000 and $0800, and R2-R5 are 1KB banks at $1000-$1C00. CHR
+// inversion swaps the two pattern tables.
+func (mmc3 *MMC3) chrAddress(address uint16) int {
+	address &= 0x1FFF
+	if mmc3.bankSelect&0x80 != 0 {
+		address ^= 0x1000
+	}
+
+	var bank int
+	if address < 0x1000 {
+		bank = int(mmc3.registers[address>>11]&^0x01) | int(address>>10&0x01)
+	} else {
+		bank = int(mmc3.registers[2+(address-0x1000)>>10])
+	}
+
+	banks := len(mmc3.chr) / 0x0400
+	return (bank%banks)*0x0400 | int(address&0x03FF)
+}
+
+func (mmc3 *MMC3) ReadPPU(address uint16) byte {
+	mmc3.watchA12(address)
+	return mmc3.chr[mmc3.chrAddress(address)]
+}
+
+func (mmc3 *MMC3) WritePPU(address uint16, value byte) {
+	mmc3.watchA12(address)
+	if mmc3.chrRAM {
+		mmc3.chr[mmc3.chrAddress(address)] = value
+	}
+}
+
+func (mmc3 *MMC3) watchA12(address uint16) {
+	a12 := address&0x1000 != 0
+
+	if a12 && !mmc3.a12 && mmc3.a12LowCycles >= mmc3A12Filter {
+		mmc3.clockIRQCounter()
+	}
+
+	if !a12 && mmc3.a12 {
+		mmc3.a12LowCycles = 0
+	}
+	mmc3.a12 = a12
+}
+
+func (mmc3 *MMC3) clockIRQCounter() {
+	if mmc3.irqCounter == 0 || mmc3.irqReload {
+		mmc3.irqCounter = mmc3.irqLatch
+		mmc3.irqReload = false
+	} else {
+		mmc3.irqCounter--
+	}
+
+	if mmc3.irqCounter == 0 && mmc3.irqEnabled {
+		mmc3.irq = true
+	}
+}
+
+// Mirroring is vertical mirroring when $A000 bit 0 is clear, which is a
+// horizontal arrangement of the nametables.
+func (mmc3 *MMC3) Mirroring() Mirroring {
+	if mmc3.fourScreen {
+		return FourScreen
+	}
+
+	if mmc3.mirroring == 0 {
+		return Horizontal
+	}
+
+	return Vertical
+}
+
+func (mmc3 *MMC3) IRQ() bool {
+	return mmc3.irq
+}
+
+func (mmc3 *MMC3) Step() {
+	if !mmc3.a12 {
+		mmc3.a12LowCycles++
+	}
+}
+
+func (mmc3 *MMC3) Scanline() {}
+
+func (mmc3 *MMC3) SaveState(w io.Writer) error {
+	state := mmc3.registerState()
+	state.PRGRAM = mmc3.prgRAM[:]
+	if mmc3.chrRAM {
+		state.CHRRAM = mmc3.chr
+	}
+
+	return saveState(w, &state)
+}
+
+func (mmc3 *MMC3) SaveRegisters(w io.Writer) error {
+	state := mmc3.registerState()
+	return saveState(w, &state)
+}
+
+func (mmc3 *MMC3) Memory() [][]byte {
+	return boardMemory(mmc3.prgRAM[:], mmc3.chr, mmc3.chrRAM)
+}
+
+func (mmc3 *MMC3) registerState() mmc3State {
+	return mmc3State{
+		BankSelect:   mmc3.bankSelect,
+		Registers:    mmc3.registers,
+		Mirroring:    mmc3.mirroring,
+		PRGRAMBits:   mmc3.prgRAMBits,
+		IRQLatch:     mmc3.irqLatch,
+		IRQCounter:   mmc3.irqCounter,
+		IRQReload:    mmc3.irqReload,
+		IRQEnabled:   mmc3.irqEnabled,
+		IRQ:          mmc3.irq,
+		A12:          mmc3.a12,
+		A12LowCycles: mmc3.a12LowCycles,
+	}
+}
+
+func (mmc3 *MMC3) LoadState(r io.Reader) error {
+	var state mmc3State
+	if err := loadState(r, &state); err != nil {
+		return err
+	}
+
+	mmc3.bankSelect = state.BankSelect
+	mmc3.registers = state.Registers
+	mmc3.mirroring = state.Mirroring
+	mmc3.prgRAMBits = state.PRGRAMBits
+	mmc3.irqLatch = state.IRQLatch
+	mmc3.irqCounter = state.IRQCounter
+	mmc3.irqReload = state.IRQReload
+	mmc3.irqEnabled = state.IRQEnabled
+	mmc3.irq = state.IRQ
+	mmc3.a12 = state.A12
+	mmc3.a12LowCycles = state.A12LowCycles
+	copy(mmc3.prgRAM[:], state.PRGRAM)
+	if mmc3.chrRAM {
+		copy(mmc3.chr, state.CHRRAM)
+	}
+
+	return nil
+}
@@ -0,0 +1,176 @@
+package cartridge
+
+import (
+	"bytes"
+	"testing"
+)
+
+func newTestMMC3(t *testing.T) *MMC3 {
+	rom := &ROM{Mapper: 4, PRGSize: 0x20000, CHRSize: 0x20000}
+	rom.PRGData = make([]byte, rom.PRGSize)
+	for i := range rom.PRGData {
+		rom.PRGData[i] = byte(i / 0x2000)
+	}
+	rom.CHRData = make([]byte, rom.CHRSize)
+	for i := range rom.CHRData {
+		rom.CHRData[i] = byte(i / 0x0400)
+	}
+
+	mapper, err := NewMapper(rom)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return mapper.(*MMC3)
+}
+
+func setMMC3Bank(mmc3 *MMC3, register byte, bank byte) {
+	mmc3.Write(0x8000, mmc3.bankSelect&0xC0|register)
+	mmc3.Write(0x8001, bank)
+}
+
+func TestMMC3PRGBanks(t *testing.T) {
+	mmc3 := newTestMMC3(t)
+	setMMC3Bank(mmc3, 6, 3)
+	setMMC3Bank(mmc3, 7, 5)
+
+	if mmc3.Read(0x8000) != 3 || mmc3.Read(0xA000) != 5 || mmc3.Read(0xC000) != 14 || mmc3.Read(0xE000) != 15 {
+		t.Error("did not map PRG in mode 0")
+	}
+
+	mmc3.Write(0x8000, 0x40)
+	if mmc3.Read(0x8000) != 14 || mmc3.Read(0xA000) != 5 || mmc3.Read(0xC000) != 3 || mmc3.Read(0xE000) != 15 {
+		t.Error("did not map PRG in mode 1")
+	}
+}
+
+func TestMMC3CHRBanks(t *testing.T) {
+	mmc3 := newTestMMC3(t)
+	for register, bank := range []byte{9, 12, 20, 21, 22, 23} {
+		setMMC3Bank(mmc3, byte(register), bank)
+	}
+
+	expected := []byte{8, 9, 12, 13, 20, 21, 22, 23}
+	for i, bank := range expected {
+		if mmc3.ReadPPU(uint16(i)*0x0400) != bank {
+			t.Errorf("$%04X mapped to bank %d, expected %d", i*0x400, mmc3.ReadPPU(uint16(i)*0x0400), bank)
+		}
+	}
+
+	mmc3.Write(0x8000, 0x80)
+	if mmc3.ReadPPU(0x0000) != 20 || mmc3.ReadPPU(0x1000) != 8 || mmc3.ReadPPU(0x1C00) != 13 {
+		t.Error("did not invert the pattern tables")
+	}
+}
+
+func TestMMC3Mirroring(t *testing.T) {
+	mmc3 := newTestMMC3(t)
+
+	mmc3.Write(0xA000, 0x00)
+	if mmc3.Mirroring() != Horizontal {
+		t.Error("did not select vertical mirroring")
+	}
+
+	mmc3.Write(0xA000, 0x01)
+	if mmc3.Mirroring() != Vertical {
+		t.Error("did not select horizontal mirroring")
+	}
+
+	mmc3.fourScreen = true
+	if mmc3.Mirroring() != FourScreen {
+		t.Error("four-screen boards should ignore the mirroring register")
+	}
+}
+
+func TestMMC3PRGRAMProtect(t *testing.T) {
+	mmc3 := newTestMMC3(t)
+	mmc3.Write(0x6000, 0x11)
+
+	mmc3.Write(0xA001, 0xC0)
+	mmc3.Write(0x6000, 0x22)
+	if mmc3.Read(0x6000) != 0x11 {
+		t.Error("wrote to write protected PRG RAM")
+	}
+
+	mmc3.Write(0xA001, 0x00)
+	if mmc3.Read(0x6000) != 0x00 {
+		t.Error("read disabled PRG RAM")
+	}
+}
+
+// clockA12 holds A12 low for long enough and then raises it
+func clockA12(mmc3 *MMC3) {
+	mmc3.ReadPPU(0x0000)
+	for i := 0; i < mmc3A12Filter; i++ {
+		mmc3.Step()
+	}
+	mmc3.ReadPPU(0x1000)
+}
+
+func TestMMC3IRQCounter(t *testing.T) {
+	mmc3 := newTestMMC3(t)
+	mmc3.Write(0xC000, 2)
+	mmc3.Write(0xC001, 0)
+	mmc3.Write(0xE001, 0)
+
+	clockA12(mmc3)
+	if mmc3.irqCounter != 2 || mmc3.IRQ() {
+		t.Error("did not reload the counter from the latch")
+	}
+
+	clockA12(mmc3)
+	if mmc3.IRQ() {
+		t.Error("asserted IRQ before the counter reached 0")
+	}
+
+	clockA12(mmc3)
+	if !mmc3.IRQ() {
+		t.Error("did not assert IRQ when the counter reached 0")
+	}
+
+	mmc3.Write(0xE000, 0)
+	if mmc3.IRQ() {
+		t.Error("did not acknowledge the IRQ")
+	}
+
+	clockA12(mmc3)
+	if mmc3.irqCounter != 2 {
+		t.Error("did not reload the counter after reaching 0")
+	}
+}
+
+func TestMMC3FiltersA12(t *testing.T) {
+	mmc3 := newTestMMC3(t)
+	mmc3.Write(0xC000, 5)
+	clockA12(mmc3)
+
+	// sprite fetches toggle A12 within a single CPU cycle
+	mmc3.ReadPPU(0x0000)
+	mmc3.Step()
+	mmc3.ReadPPU(0x1000)
+
+	if mmc3.irqCounter != 5 {
+		t.Error("clocked the counter on a filtered A12 edge")
+	}
+}
+
+func TestMMC3SaveState(t *testing.T) {
+	mmc3 := newTestMMC3(t)
+	setMMC3Bank(mmc3, 6, 3)
+	mmc3.Write(0xC000, 7)
+	mmc3.Write(0x6000, 0x42)
+
+	var state bytes.Buffer
+	if err := mmc3.SaveState(&state); err != nil {
+		t.Fatal(err)
+	}
+
+	restored := newTestMMC3(t)
+	if err := restored.LoadState(&state); err != nil {
+		t.Fatal(err)
+	}
+
+	if restored.Read(0x8000) != 3 || restored.irqLatch != 7 || restored.Read(0x6000) != 0x42 {
+		t.Error("did not restore the banks, IRQ latch and PRG RAM")
+	}
+}
@@ -0,0 +1,108 @@
+package cartridge
+
+import "io"
+
+func init() {
+	RegisterMapper(0, newNROM)
+}
+
+// NROM (mapper 0) has no bank switching. 16KB of PRG ROM is mirrored into
+// both $8000 and $C000, 32KB fills $8000-$FFFF. CHR is 8KB of ROM, or RAM
+// when the header has no CHR. The 8KB of PRG RAM at $6000-$7FFF is only
+// present on Family Basic boards, but it is harmless to provide it.
+type NROM struct {
+	mapperDefaults
+
+	prg       []byte
+	chr       []byte
+	chrRAM    bool
+	prgRAM    [0x2000]byte
+	mirroring Mirroring
+}
+
+type nromState struct {
+	PRGRAM []byte
+	CHRRAM []byte
+}
+
+func newNROM(rom *ROM) (Mapper, error) {
+	prg, err := romPRG(rom, 0x4000)
+	if err != nil {
+		return nil, err
+	}
+
+	chr, chrRAM, err := romCHR(rom)
+	if err != nil {
+		return nil, err
+	}
+
+	return &NROM{
+		prg:       prg,
+		chr:       chr,
+		chrRAM:    chrRAM,
+		mirroring: romMirroring(rom),
+	}, nil
+}
+
+func (nrom *NROM) Read(address uint16) byte {
+	switch {
+	case address >= 0x8000:
+		return nrom.prg[int(address-0x8000)%len(nrom.prg)]
+	case address >= 0x6000:
+		return nrom.prgRAM[address-0x6000]
+	}
+
+	return 0
+}
+
+func (nrom *NROM) Write(address uint16, value byte) {
+	if address >= 0x6000 && address < 0x8000 {
+		nrom.prgRAM[address-0x6000] = value
+	}
+}
+
+func (nrom *NROM) ReadPPU(address uint16) byte {
+	return nrom.chr[address&0x1FFF]
+}
+
+func (nrom *NROM) WritePPU(address uint16, value byte) {
+	if nrom.chrRAM {
+		nrom.chr[address&0x1FFF] = value
+	}
+}
+
+func (nrom *NROM) Mirroring() Mirroring {
+	return nrom.mirroring
+}
+
+func (nrom *NROM) SaveState(w io.Writer) error {
+	state := nromState{PRGRAM: nrom.prgRAM[:]}
+	if nrom.chrRAM {
+		state.CHRRAM = nrom.chr
+	}
+
+	return saveState(w, &state)
+}
+
+// SaveRegisters has nothing to write, NROM has no registers
+func (nrom *NROM) SaveRegisters(w io.Writer) error {
+	return saveState(w, &nromState{})
+}
+
+func (nrom *NROM) Memory() [][]byte {
+	return boardMemory(nrom.prgRAM[:], nrom.chr, nrom.chrRAM)
+}
+
+func (nrom *NROM) LoadState(r io.Reader) error {
+	var state nromState
+	if err := loadState(r, &state); err != nil {
+		return err
+	}
+
+	copy(nrom.prgRAM[:], state.PRGRAM)
+	if nrom.chrRAM {
+		copy(nrom.chr, state.CHRRAM)
+	}
+
+	return nil
+}
@@ -0,0 +1,488 @@
+// Package cartridge loads iNES and NES 2.0 ROM images and implements the
+// circuitry of the cartridge boards they were dumped from.
+package cartridge
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+type Mirroring int
+
+const (
+	Vertical Mirroring = iota
+	Horizontal
+	FourScreen        // the cartridge provides VRAM for all four nametables
+	SingleScreenLower // every nametable is the first 1KB of VRAM
+	SingleScreenUpper // every nametable is the second 1KB of VRAM
+)
+
+type TVSystem int
+
+const (
+	NTSC TVSystem = iota
+	PAL
+	MultiRegion // NES 2.0 only, runs on both NTSC and PAL consoles
+	Dendy       // NES 2.0 only, the timing of the Dendy famiclone
+)
+
+type ConsoleType int
+
+const (
+	NESConsole ConsoleType = iota // NES or Famicom
+	VsSystem
+	PlayChoice10
+	ExtendedConsole // NES 2.0 only, see ROM.ExtendedConsoleType
+)
+
+type ROM struct {
+	Mirroring       Mirroring
+	CartridgeMemory bool
+	Trainer         bool
+	FourScreen      bool
+	Mapper          uint16
+	VSUnisystem     bool
+	ConsoleType     ConsoleType
+	NES2Format      bool
+	TVSystem        TVSystem
+	PRGSize         uint
+	CHRSize         uint
+	PRGData         []byte
+	CHRData         []byte
+	TrainerData     []byte // 512 bytes loaded into $7000-$71FF
+
+	// PlayChoice-10 dumps end with the 8KB INST-ROM holding the hint
+	// screen and the 32 byte PROM used to decrypt it
+	PlayChoiceINSTROM []byte
+	PlayChoicePROM    []byte
+
+	// Only set by NES 2.0 headers. RAM sizes are in bytes, NVRAM is the
+	// battery backed part.
+	Submapper              uint8
+	PRGRAMSize             uint
+	PRGNVRAMSize           uint
+	CHRRAMSize             uint
+	CHRNVRAMSize           uint
+	VsPPUType              uint8 // when ConsoleType is VsSystem
+	VsHardwareType         uint8 // when ConsoleType is VsSystem
+	ExtendedConsoleType    uint8 // when ConsoleType is ExtendedConsole
+	MiscROMs               uint8
+	MiscROMData            []byte // everything after CHR when MiscROMs > 0
+	DefaultExpansionDevice uint8
+}
+
+// ## Flags 6 #
+//76543210
+//||||||||
+//|||||||+- Mirroring: 0: horizontal (vertical arrangement) (CIRAM A10 = PPU A11)
+//|||||||              1: vertical (horizontal arrangement) (CIRAM A10 = PPU A10)
+//||||||+-- 1: Cartridge contains battery-backed PRG RAM ($6000-7FFF) or other persistent memory
+//|||||+--- 1: 512-byte trainer at $7000-$71FF (stored before PRG data)
+//||||+---- 1: Ignore mirroring control or above mirroring bit; instead provide four-screen VRAM
+//++++----- Lower nybble of mapper number
+
+func parseFlags6Mirroring(flags byte) Mirroring {
+	// Right-most bit position
+	return Mirroring(flags & 0x01)
+}
+
+func parseFlags6CartridgeMemory(flags byte) bool {
+	// Second bit position from the right
+	return (uint8(flags>>1) & 0x01) == 1
+}
+
+func parseFlags6Trainer(flags byte) bool {
+	// 512-byte trainer at $7000-$71FF (stored before PRG data)
+	// third bit position from the right
+	return (uint8(flags>>2) & 0x01) == 1
+}
+
+func parseFlags6FourScreen(flags byte) bool {
+	// Ignore mirroring control or above mirroring bit; instead provide four-screen VRAM
+	// fourth bit position from the right
+	return (uint8(flags>>3) & 0x01) == 1
+}
+
+func parseFlags6MapperLowerNibble(flags byte) uint8 {
+	// Lower nibble of mapper number
+	return uint8(flags>>4) & 0x0F
+}
+
+// # Flags 7 #
+// 76543210
+// ||||||||
+// |||||||+- VS Unisystem
+// ||||||+-- PlayChoice-10 (8KB of Hint Screen data stored after CHR data)
+// ||||++--- If equal to 2, flags 8-15 are in NES 2.0 format
+// ++++----- Upper nybble of mapper number
+
+func parseFlags7VSUnisystem(flags byte) bool {
+	// Right-most bit position
+	return (uint8(flags) & 0x01) == 1
+}
+
+func parseFlags7PlayChoice10(flags byte) bool {
+	// Second bit position from the right
+	return (uint8(flags>>1) & 0x01) == 1
+}
+
+func parseFlags7ConsoleType(flags byte) ConsoleType {
+	// Two right-most bit positions. NES 2.0 reads them as one number,
+	// iNES as two separate flags that can both be set, so PlayChoice-10
+	// wins there because its data follows CHR.
+	if parseFlags7NES2RomFormat(flags) {
+		return ConsoleType(flags & 0x03)
+	}
+
+	if parseFlags7PlayChoice10(flags) {
+		return PlayChoice10
+	}
+	if parseFlags7VSUnisystem(flags) {
+		return VsSystem
+	}
+
+	return NESConsole
+}
+
+func parseFlags7NES2RomFormat(flags byte) bool {
+	// Third and fourth bit position from the right
+	return uint8(flags>>2)&0x03 == 2
+}
+
+func parseFlags7MapperUpperNibble(flags byte) uint8 {
+	return uint8(flags >> 4)
+}
+
+// # Flags 9 #
+// 76543210
+//||||||||
+//|||||||+- TV system (0: NTSC; 1: PAL)
+//+++++++-- Reserved, set to zero
+
+func parseFlags9TVSystem(flags byte) TVSystem {
+	return TVSystem(uint8(flags) & 0x01)
+}
+
+// # NES 2.0 Byte 8 #
+// 76543210
+// ||||||||
+// ||||++++- Mapper number bits 8-11
+// ++++----- Submapper number
+
+func parseNES2MapperHighNibble(flags byte) uint16 {
+	return uint16(flags & 0x0F)
+}
+
+func parseNES2Submapper(flags byte) uint8 {
+	return uint8(flags >> 4)
+}
+
+// # NES 2.0 Byte 9 #
+// 76543210
+// ||||||||
+// ||||++++- PRG ROM size MSB
+// ++++----- CHR ROM size MSB
+//
+// The MSB extends the size in byte 4 or 5 to 12 bits, counted in the
+// usual 16KB or 8KB units. An MSB of $F switches the LSB to exponent-
+// multiplier notation instead:
+//
+// 76543210
+// ||||||||
+// ||||||++- Multiplier, actual value is MM*2+1 (1,3,5,7)
+// ++++++--- Exponent (2^E), 0-63
+//
+// for a size of 2^E * (MM*2+1) bytes
+
+func parseNES2RomSize(lsb byte, msb byte, unit uint) uint64 {
+	if msb == 0x0F {
+		exponent := lsb >> 2
+		multiplier := uint64(lsb&0x03)*2 + 1
+		if exponent > 60 {
+			// too large for a uint64, parseNES2Header rejects it anyway
+			return 1<<64 - 1
+		}
+		return (1 << exponent) * multiplier
+	}
+
+	return (uint64(msb)<<8 | uint64(lsb)) * uint64(unit)
+}
+
+// # NES 2.0 Byte 10 #
+// 76543210
+// ||||||||
+// ||||++++- PRG-RAM (volatile) shift count
+// ++++----- PRG-NVRAM/EEPROM (non-volatile) shift count
+//
+// # NES 2.0 Byte 11 #
+// 76543210
+// ||||||||
+// ||||++++- CHR-RAM size (volatile) shift count
+// ++++----- CHR-NVRAM size (non-volatile) shift count
+//
+// A shift count of 0 means there is no RAM, otherwise the size is 64
+// bytes shifted left by the count.
+
+func parseNES2RAMSize(shift byte) uint {
+	if shift == 0 {
+		return 0
+	}
+
+	return 64 << shift
+}
+
+// # NES 2.0 Byte 12 #
+// 76543210
+// ||||||||
+// ||||||++- CPU/PPU timing mode
+// ||||||    0: RP2C02 ("NTSC NES")
+// ||||||    1: RP2C07 ("Licensed PAL NES")
+// ||||||    2: Multiple-region
+// ||||||    3: UMC 6527P ("Dendy")
+// ++++++--- Reserved
+
+func parseNES2TVSystem(flags byte) TVSystem {
+	return TVSystem(flags & 0x03)
+}
+
+// # NES 2.0 Byte 13 #
+// When ConsoleType is Vs. System:
+// 76543210
+// ||||||||
+// ||||++++- Vs. PPU Type
+// ++++----- Vs. Hardware Type
+//
+// When ConsoleType is Extended Console:
+// 76543210
+// ||||||||
+// ||||++++- Extended Console Type
+// ++++----- Reserved
+//
+// # NES 2.0 Byte 14 #
+// 76543210
+// ||||||||
+// ||||||++- Number of miscellaneous ROMs present
+// ++++++--- Reserved
+//
+// # NES 2.0 Byte 15 #
+// 76543210
+// ||||||||
+// ||++++++- Default Expansion Device
+// ++------- Reserved
+
+func parseNES2Header(rom *ROM, header []byte) error {
+	rom.Mapper |= parseNES2MapperHighNibble(header[8]) << 8
+	rom.Submapper = parseNES2Submapper(header[8])
+
+	prgSize := parseNES2RomSize(header[4], header[9]&0x0F, 16384)
+	if prgSize == 0 || prgSize > maxRomSize {
+		return &NES2SizeError{Section: "PRG", Size: prgSize}
+	}
+
+	chrSize := parseNES2RomSize(header[5], header[9]>>4, 8192)
+	if chrSize > maxRomSize {
+		return &NES2SizeError{Section: "CHR", Size: chrSize}
+	}
+
+	rom.PRGSize = uint(prgSize)
+	rom.CHRSize = uint(chrSize)
+	rom.PRGRAMSize = parseNES2RAMSize(header[10] & 0x0F)
+	rom.PRGNVRAMSize = parseNES2RAMSize(header[10] >> 4)
+	rom.CHRRAMSize = parseNES2RAMSize(header[11] & 0x0F)
+	rom.CHRNVRAMSize = parseNES2RAMSize(header[11] >> 4)
+	rom.TVSystem = parseNES2TVSystem(header[12])
+
+	switch rom.ConsoleType {
+	case VsSystem:
+		rom.VsPPUType = header[13] & 0x0F
+		rom.VsHardwareType = header[13] >> 4
+	case ExtendedConsole:
+		rom.ExtendedConsoleType = header[13] & 0x0F
+	}
+
+	rom.MiscROMs = header[14] & 0x03
+	rom.DefaultExpansionDevice = header[15] & 0x3F
+
+	return nil
+}
+
+func parsePrgRomSize(size byte) uint {
+	// value is in 16kB blocks
+	return uint(size) * 16384
+}
+
+func parseChrRomSize(size byte) uint {
+	// value is in 8kB blocks
+	return uint(size) * 8192
+}
+
+// Errors returned by Load. Truncated files are reported with a
+// RomTruncatedError and inconsistent NES 2.0 headers with an
+// NES2SizeError, mappers that don't exist are an UnsupportedMapperError
+// from NewMapper.
+var (
+	ErrBadMagic     = errors.New("does not contain the iNES magic header")
+	ErrTrailingData = errors.New("unexpected bytes after the ROM data")
+)
+
+// RomTruncatedError is returned when the file ends before a section of
+// the ROM does. Err is the underlying io error.
+type RomTruncatedError struct {
+	Section  string // "header", "trainer", "PRG", "CHR", ...
+	Expected int
+	Got      int
+	Err      error
+}
+
+func (err *RomTruncatedError) Error() string {
+	return fmt.Sprintf("ROM is truncated, expected %d bytes of %s data, got %d", err.Expected, err.Section, err.Got)
+}
+
+func (err *RomTruncatedError) Unwrap() error {
+	return err.Err
+}
+
+// NES2SizeError is returned for NES 2.0 headers whose PRG or CHR size
+// can't be right, like no PRG ROM at all or an exponent-multiplier size
+// larger than any cartridge.
+type NES2SizeError struct {
+	Section string
+	Size    uint64
+}
+
+func (err *NES2SizeError) Error() string {
+	return fmt.Sprintf("NES 2.0 header has an inconsistent %s size of %d bytes", err.Section, err.Size)
+}
+
+// maxRomSize bounds the PRG and CHR sizes of an NES 2.0 header. The
+// largest dumps are a few megabytes, the 12 bit sizes top out at 64MB.
+const maxRomSize = 64 << 20
+
+func validateHeader(header []byte) error {
+	magicHeader := []byte{'N', 'E', 'S', 0x1A}
+
+	for i := range magicHeader {
+		if header[i] != magicHeader[i] {
+			return ErrBadMagic
+		}
+	}
+
+	return nil
+}
+
+func parseMapper(flags []byte) uint16 {
+	lower := parseFlags6MapperLowerNibble(flags[0])
+	upper := parseFlags7MapperUpperNibble(flags[1])
+
+	return uint16(upper)<<4 | uint16(lower)
+}
+
+// Load reads an iNES or NES 2.0 ROM image. Nothing is returned with an
+// error, a ROM that can't be read completely isn't returned at all.
+func Load(file io.Reader) (*ROM, error) {
+	var rom ROM
+
+	header := make([]byte, 16)
+	if err := readRomSection(file, header, "header"); err != nil {
+		return nil, err
+	}
+
+	if err := validateHeader(header); err != nil {
+		return nil, err
+	}
+
+	rom.PRGSize = parsePrgRomSize(header[4])
+	rom.CHRSize = parseChrRomSize(header[5])
+	rom.Mirroring = parseFlags6Mirroring(header[6])
+	rom.CartridgeMemory = parseFlags6CartridgeMemory(header[6])
+	rom.Trainer = parseFlags6Trainer(header[6])
+	rom.FourScreen = parseFlags6FourScreen(header[6])
+	rom.Mapper = parseMapper(header[6:8])
+	rom.VSUnisystem = parseFlags7VSUnisystem(header[7])
+	rom.ConsoleType = parseFlags7ConsoleType(header[7])
+	rom.NES2Format = parseFlags7NES2RomFormat(header[7])
+
+	// bytes 8-15 mean something else entirely in NES 2.0
+	if rom.NES2Format {
+		if err := parseNES2Header(&rom, header); err != nil {
+			return nil, err
+		}
+	} else {
+		rom.TVSystem = parseFlags9TVSystem(header[9])
+	}
+
+	if rom.Trainer {
+		rom.TrainerData = make([]byte, 512)
+		if err := readRomSection(file, rom.TrainerData, "trainer"); err != nil {
+			return nil, err
+		}
+	}
+
+	rom.PRGData = make([]byte, rom.PRGSize)
+	if err := readRomSection(file, rom.PRGData, "PRG"); err != nil {
+		return nil, err
+	}
+
+	// a CHR size of 0 means the board has CHR RAM
+	rom.CHRData = make([]byte, rom.CHRSize)
+	if err := readRomSection(file, rom.CHRData, "CHR"); err != nil {
+		return nil, err
+	}
+
+	if rom.ConsoleType == PlayChoice10 {
+		rom.PlayChoiceINSTROM = make([]byte, 8192)
+		if err := readRomSection(file, rom.PlayChoiceINSTROM, "PlayChoice-10 INST-ROM"); err != nil {
+			return nil, err
+		}
+
+		rom.PlayChoicePROM = make([]byte, 32)
+		if err := readRomSection(file, rom.PlayChoicePROM, "PlayChoice-10 PROM"); err != nil {
+			return nil, err
+		}
+	}
+
+	rest, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+
+	if rom.NES2Format && rom.MiscROMs > 0 {
+		rom.MiscROMData = rest
+	} else if len(rest) > 0 {
+		return nil, fmt.Errorf("%w: %d bytes", ErrTrailingData, len(rest))
+	}
+
+	return &rom, nil
+}
+
+// readRomSection fills data from the file, failing when the file ends
+// before the section does.
+func readRomSection(file io.Reader, data []byte, section string) error {
+	n, err := io.ReadFull(file, data)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return &RomTruncatedError{Section: section, Expected: len(data), Got: n, Err: err}
+	}
+	if err != nil {
+		return fmt.Errorf("reading %s data: %w", section, err)
+	}
+
+	return nil
+}
+
+// LoadFile reads the ROM image at path.
+func LoadFile(path string) (*ROM, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	rom, err := Load(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return rom, nil
+}
@@ -0,0 +1,496 @@
+package cartridge
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestReturErrorOnBadHeader(t *testing.T) {
+	badHeader := []byte{'B', 'A', 'D', 0x1A}
+	err := validateHeader(badHeader)
+	if err == nil {
+		t.Error("bad header did not return error")
+	}
+}
+
+func TestNoErrorOnBadHeader(t *testing.T) {
+	badHeader := []byte{'N', 'E', 'S', 0x1A}
+	err := validateHeader(badHeader)
+	if err != nil {
+		t.Error("good header is returning an error")
+	}
+}
+
+func TestGetPrgRomSize(t *testing.T) {
+	header := byte(0xFF)
+	size := parsePrgRomSize(header)
+
+	if size != (16384 * 0xFF) {
+		t.Error("Incorrectly read PRG ROM size")
+	}
+}
+
+func TestGetChrRomSize(t *testing.T) {
+	header := byte(0xBA)
+	size := parseChrRomSize(header)
+
+	if size != (8192 * 0xBA) {
+		t.Error("Incorrectly read CHR ROM size")
+	}
+}
+
+func TestParseFlags6MirroringVertical(t *testing.T) {
+	flags := byte(0x00)
+
+	mirroring := parseFlags6Mirroring(flags)
+
+	if mirroring != Vertical {
+		t.Error("Incorrectly parsed vertical mirroring")
+	}
+}
+
+func TestParseFlags6MirroringHorizontal(t *testing.T) {
+	flags := byte(0x01)
+
+	mirroring := parseFlags6Mirroring(flags)
+
+	if mirroring != Horizontal {
+		t.Error("Incorrectly parsed horizontal mirroring")
+	}
+}
+
+func TestParseFlags6CartridgeDoesNotHaveMemory(t *testing.T) {
+	flags := byte(0x00)
+
+	onboardMemory := parseFlags6CartridgeMemory(flags)
+
+	if onboardMemory != false {
+		t.Error("Incorrectly parsed cartridge onboard memory")
+	}
+}
+
+func TestParseFlags6CartridgeHasMemory(t *testing.T) {
+	flags := byte(0x02)
+
+	onboardMemory := parseFlags6CartridgeMemory(flags)
+
+	if onboardMemory != true {
+		t.Error("Incorrectly parsed cartridge onboard memory")
+	}
+}
+
+func TestParseFlags6Trainer(t *testing.T) {
+	flags := byte(0x00)
+
+	trainer := parseFlags6Trainer(flags)
+
+	if trainer != false {
+		t.Error("Incorrectly parsed presence of trainer data")
+	}
+}
+
+func TestParseFlags6NoTrainer(t *testing.T) {
+	flags := byte(0xFF)
+
+	trainer := parseFlags6Trainer(flags)
+
+	if trainer != true {
+		t.Error("Incorrectly parsed presence of trainer data")
+	}
+}
+
+func TestParseFlags6DoNotFourScreen(t *testing.T) {
+	flags := byte(0x00)
+
+	fourScreen := parseFlags6FourScreen(flags)
+
+	if fourScreen != false {
+		t.Error("Incorrectly parsed four screen flag")
+	}
+}
+
+func TestParseFlags6FourScreen(t *testing.T) {
+	flags := byte(0x08)
+
+	fourScreen := parseFlags6FourScreen(flags)
+
+	if fourScreen != true {
+		t.Error("Incorrectly parsed four screen flag")
+	}
+}
+
+func TestParseFlags6MapperLowerNibble(t *testing.T) {
+	flags := byte(0x08 << 4)
+
+	lowerNibble := parseFlags6MapperLowerNibble(flags)
+
+	if lowerNibble != 0x08 {
+		t.Error("Incorrectly parsed mapper lower nibble")
+	}
+}
+
+func TestParseFlags7NotVSUnisystem(t *testing.T) {
+	flags := byte(0x00)
+
+	vsUnisystem := parseFlags7VSUnisystem(flags)
+
+	if vsUnisystem != false {
+		t.Error("Incorrectly parsed VS Unisystem flag")
+	}
+}
+
+func TestParseFlags7IsVSUnisystem(t *testing.T) {
+	flags := byte(0x01)
+
+	vsUnisystem := parseFlags7VSUnisystem(flags)
+
+	if vsUnisystem != true {
+		t.Error("Incorrectly parsed VS Unisystem flag")
+	}
+}
+
+func TestParseFlags7NotNES2RomFormat(t *testing.T) {
+	flags := byte(0x00)
+
+	nes2RomFormat := parseFlags7NES2RomFormat(flags)
+
+	if nes2RomFormat != false {
+		t.Error("Incorrectly parsed NES 2.0 ROM format")
+	}
+}
+
+func TestParseFlags7IsNES2RomFormat(t *testing.T) {
+	flags := byte(0x08)
+
+	nes2RomFormat := parseFlags7NES2RomFormat(flags)
+
+	if nes2RomFormat != true {
+		t.Error("Incorrectly parsed NES 2.0 ROM format")
+	}
+}
+
+func TestParseFlags7MapperUpperNibble(t *testing.T) {
+	flags := byte(0x0C << 4)
+
+	nibble := parseFlags7MapperUpperNibble(flags)
+
+	if nibble != 0x0C {
+		t.Error("Incorrectly parsed mapper upper nibble")
+	}
+}
+
+func TestParseFlags9TVSystemNTSC(t *testing.T) {
+	flags := byte(0x00)
+
+	isNTSC := parseFlags9TVSystem(flags)
+
+	if isNTSC != 0x00 {
+		t.Error("Incorrectly parsed TV System NTSC")
+	}
+}
+
+func TestParseFlags9TVSystemPAL(t *testing.T) {
+	flags := byte(0x01)
+
+	isNTSC := parseFlags9TVSystem(flags)
+
+	if isNTSC != 0x01 {
+		t.Error("Incorrectly parsed TV System NTSC")
+	}
+}
+
+func TestParseMapper(t *testing.T) {
+	// pass in flags 6 and 7, since mapper is spread across both
+	flagSix := byte(0x01 << 4)
+	flagSeven := byte(0x01 << 4)
+	flags := []byte{flagSix, flagSeven}
+
+	mapper := parseMapper(flags)
+
+	if mapper != 0x11 {
+		t.Error("Incorrectly parsed full mapper byte")
+	}
+}
+
+func TestParseRom(t *testing.T) {
+	romData := []byte{
+		'N',  // Magic Header
+		'E',  // Magic Header
+		'S',  // Magic Header
+		0x1A, // Magic Header
+		0x0F, // PRG Size
+		0x0A, // CHR Size
+		0xFF, // Flags 6
+		0xFF, // Flags 7
+		0x00, // PRG RAM Size
+		0x01, // Flags 9
+		0x00, // Flags 10
+		0x00, // Zero-filled
+		0x00, // Zero-filled
+		0x00, // Zero-filled
+		0x00, // Zero-filled
+		0x00, // Zero-filled
+	}
+	// the trainer, PRG and CHR the header promises, and the PlayChoice-10
+	// INST-ROM and PROM flags 7 asks for
+	romData = append(romData, make([]byte, 512+245760+0x0A*8192+8192+32)...)
+
+	rom, err := Load(bytes.NewBuffer(romData))
+	if err != nil {
+		t.Fatal("Failed to parse ROM!", err)
+	}
+
+	if rom.PRGSize != 245760 {
+		t.Error("Incorrect PRGSize")
+	}
+
+	if rom.CHRSize != 0x0A*8192 {
+		t.Error("Incorrect CHRSize")
+	}
+
+	if rom.Mirroring != Horizontal {
+		t.Error("Incorrect Mirroring")
+	}
+
+	if rom.CartridgeMemory != true {
+		t.Error("Incorrect CartridgeMemory")
+	}
+
+	if rom.Trainer != true {
+		t.Error("Incorrect Trainer")
+	}
+
+	if rom.FourScreen != true {
+		t.Error("Incorrect FourScreen")
+	}
+
+	if rom.Mapper != 0xFF {
+		t.Error("Incorrect Mapper")
+	}
+
+	if rom.VSUnisystem != true {
+		t.Error("Incorrect VSUnisystem")
+	}
+
+	if rom.NES2Format != false {
+		t.Error("Incorrect NES 2.0 Format")
+	}
+
+	if rom.TVSystem != PAL {
+		t.Error("Incorrect TVSystem (NTSC or PAL)")
+	}
+}
+
+func TestParseFlags7ConsoleType(t *testing.T) {
+	if parseFlags7ConsoleType(0x01) != VsSystem || parseFlags7ConsoleType(0x0B) != ExtendedConsole {
+		t.Error("Incorrectly parsed console type")
+	}
+}
+
+func TestParseFlags7ConsoleTypeINESBothFlags(t *testing.T) {
+	// iNES bits 0 and 1 are separate flags, not NES 2.0's extended console
+	if parseFlags7ConsoleType(0x03) != PlayChoice10 || !parseFlags7PlayChoice10(0x03) {
+		t.Error("did not read bit 1 as PlayChoice-10 in an iNES header")
+	}
+}
+
+func TestParseNES2RomSize(t *testing.T) {
+	if size := parseNES2RomSize(0x02, 0x01, 16384); size != 0x102*16384 {
+		t.Error("Incorrectly parsed ROM size with MSB, got", size)
+	}
+
+	// 2^4 * (1*2+1)
+	if size := parseNES2RomSize(0x11, 0x0F, 16384); size != 48 {
+		t.Error("Incorrectly parsed exponent-multiplier ROM size, got", size)
+	}
+}
+
+func TestParseNES2RAMSize(t *testing.T) {
+	if parseNES2RAMSize(0) != 0 || parseNES2RAMSize(7) != 8192 {
+		t.Error("Incorrectly parsed RAM shift count")
+	}
+}
+
+func TestParseNES2Rom(t *testing.T) {
+	header := []byte{
+		'N', 'E', 'S', 0x1A,
+		0x02, // PRG Size
+		0x00, // CHR Size
+		0x40, // Flags 6, mapper low nibble 4
+		0x09, // Flags 7, NES 2.0 and Vs. System
+		0x31, // mapper bits 8-11 and submapper
+		0x00, // size MSBs
+		0x97, // PRG NVRAM and RAM
+		0x07, // CHR RAM
+		0x03, // Dendy
+		0x21, // Vs. hardware and PPU type
+		0x01, // misc ROMs
+		0x2A, // expansion device
+	}
+	romData := append(header, make([]byte, 2*16384)...)
+
+	rom, err := Load(bytes.NewBuffer(romData))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !rom.NES2Format || rom.Mapper != 0x104 || rom.Submapper != 3 {
+		t.Error("Incorrectly parsed NES 2.0 mapper")
+	}
+
+	if rom.PRGSize != 2*16384 || rom.CHRSize != 0 {
+		t.Error("Incorrectly parsed NES 2.0 ROM sizes")
+	}
+
+	if rom.PRGRAMSize != 8192 || rom.PRGNVRAMSize != 32768 || rom.CHRRAMSize != 8192 || rom.CHRNVRAMSize != 0 {
+		t.Error("Incorrectly parsed NES 2.0 RAM sizes")
+	}
+
+	if rom.TVSystem != Dendy {
+		t.Error("Incorrectly parsed NES 2.0 timing")
+	}
+
+	if rom.ConsoleType != VsSystem || rom.VsPPUType != 1 || rom.VsHardwareType != 2 {
+		t.Error("Incorrectly parsed Vs. System types")
+	}
+
+	if rom.MiscROMs != 1 || rom.DefaultExpansionDevice != 0x2A {
+		t.Error("Incorrectly parsed misc ROMs and expansion device")
+	}
+}
+
+// testRomHeader is an iNES header for one 16KB PRG bank and one 8KB CHR bank
+func testRomHeader(flags6 byte, flags7 byte) []byte {
+	return []byte{'N', 'E', 'S', 0x1A, 0x01, 0x01, flags6, flags7, 0, 0, 0, 0, 0, 0, 0, 0}
+}
+
+func TestParseRomData(t *testing.T) {
+	romData := testRomHeader(0x04, 0x00)
+	trainer := bytes.Repeat([]byte{0x11}, 512)
+	prg := bytes.Repeat([]byte{0x22}, 16384)
+	chr := bytes.Repeat([]byte{0x33}, 8192)
+	romData = append(romData, trainer...)
+	romData = append(romData, prg...)
+	romData = append(romData, chr...)
+
+	rom, err := Load(bytes.NewBuffer(romData))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(rom.TrainerData, trainer) || !bytes.Equal(rom.PRGData, prg) || !bytes.Equal(rom.CHRData, chr) {
+		t.Error("did not read the trainer, PRG and CHR data")
+	}
+
+	mapper, err := NewMapper(rom)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if mapper.Read(0x7000) != 0x11 || mapper.Read(0x71FF) != 0x11 || mapper.Read(0x7200) != 0x00 {
+		t.Error("did not load the trainer into $7000-$71FF")
+	}
+}
+
+func TestParseRomPlayChoice(t *testing.T) {
+	romData := testRomHeader(0x00, 0x02)
+	romData = append(romData, make([]byte, 16384+8192)...)
+	romData = append(romData, bytes.Repeat([]byte{0x44}, 8192)...)
+	romData = append(romData, bytes.Repeat([]byte{0x55}, 32)...)
+
+	rom, err := Load(bytes.NewBuffer(romData))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rom.PlayChoiceINSTROM) != 8192 || rom.PlayChoiceINSTROM[0] != 0x44 {
+		t.Error("did not read the PlayChoice-10 INST-ROM")
+	}
+
+	if len(rom.PlayChoicePROM) != 32 || rom.PlayChoicePROM[31] != 0x55 {
+		t.Error("did not read the PlayChoice-10 PROM")
+	}
+}
+
+func TestParseRomVsPlayChoice(t *testing.T) {
+	romData := testRomHeader(0x00, 0x03)
+	romData = append(romData, make([]byte, 16384)...)
+	romData = append(romData, bytes.Repeat([]byte{0x33}, 8192)...)
+	romData = append(romData, bytes.Repeat([]byte{0x44}, 8192+32)...)
+
+	rom, err := Load(bytes.NewBuffer(romData))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !rom.VSUnisystem || rom.ConsoleType != PlayChoice10 || rom.CHRData[8191] != 0x33 || rom.PlayChoicePROM[0] != 0x44 {
+		t.Error("did not read the PlayChoice-10 data after CHR with both flags set")
+	}
+}
+
+func TestParseRomTruncated(t *testing.T) {
+	romData := testRomHeader(0x00, 0x00)
+	romData = append(romData, make([]byte, 16384+100)...)
+
+	rom, err := Load(bytes.NewBuffer(romData))
+
+	var truncated *RomTruncatedError
+	if !errors.As(err, &truncated) || truncated.Section != "CHR" || truncated.Got != 100 {
+		t.Error("did not return a RomTruncatedError for the CHR data, got", err)
+	}
+
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Error("did not wrap the io error")
+	}
+
+	if rom != nil {
+		t.Error("returned a half read ROM with the error")
+	}
+}
+
+func TestParseRomTrailingData(t *testing.T) {
+	romData := testRomHeader(0x00, 0x00)
+	romData = append(romData, make([]byte, 16384+8192+1)...)
+
+	if _, err := Load(bytes.NewBuffer(romData)); !errors.Is(err, ErrTrailingData) {
+		t.Error("did not return ErrTrailingData, got", err)
+	}
+}
+
+func TestParseRomBadMagic(t *testing.T) {
+	romData := []byte{'B', 'A', 'D', 0x1A, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}
+
+	if _, err := Load(bytes.NewBuffer(romData)); !errors.Is(err, ErrBadMagic) {
+		t.Error("did not return ErrBadMagic, got", err)
+	}
+}
+
+func TestParseRomTruncatedHeader(t *testing.T) {
+	_, err := Load(bytes.NewBuffer([]byte{'N', 'E', 'S'}))
+
+	var truncated *RomTruncatedError
+	if !errors.As(err, &truncated) || truncated.Section != "header" {
+		t.Error("did not return a RomTruncatedError for the header, got", err)
+	}
+}
+
+func TestParseRomNES2InconsistentSize(t *testing.T) {
+	romData := testRomHeader(0x00, 0x08)
+	romData[4] = 0xFF // 2^63 * 7
+	romData[9] = 0x0F
+
+	_, err := Load(bytes.NewBuffer(romData))
+
+	var inconsistent *NES2SizeError
+	if !errors.As(err, &inconsistent) || inconsistent.Section != "PRG" {
+		t.Error("did not return an NES2SizeError, got", err)
+	}
+
+	romData[4] = 0x00
+	romData[9] = 0x00
+	if _, err := Load(bytes.NewBuffer(romData)); !errors.As(err, &inconsistent) {
+		t.Error("accepted an NES 2.0 header without PRG ROM, got", err)
+	}
+}
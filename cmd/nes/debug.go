@@ -0,0 +1,20 @@
+package main
+
+import (
+	"flag"
+	"os"
+
+	"github.com/DevinRiley/nes/debugger"
+)
+
+func debug(args []string) error {
+	flags := flag.NewFlagSet("nes debug", flag.ExitOnError)
+	flags.Parse(args)
+
+	console, err := load(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	return debugger.New(console, os.Stdout).Run(os.Stdin)
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/DevinRiley/nes"
+)
+
+func diff(args []string) error {
+	flags := flag.NewFlagSet("nes diff", flag.ExitOnError)
+	start := flags.String("pc", "", "start at this address, in hex, instead of the reset vector")
+	context := flags.Int("context", 10, "number of instructions to show before the divergence")
+	flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		return fmt.Errorf("usage: nes diff [-pc ADDR] [-context N] LOG [ROM]")
+	}
+
+	reference, err := os.Open(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer reference.Close()
+
+	console, err := load(flags.Arg(1))
+	if err != nil {
+		return err
+	}
+	if err := startAt(console, *start); err != nil {
+		return err
+	}
+
+	divergence, err := nes.DiffTrace(console, reference, *context)
+	if err != nil {
+		return err
+	}
+	if divergence != nil {
+		fmt.Print(divergence)
+		return fmt.Errorf("%s differs at line %d", flags.Arg(0), divergence.Line)
+	}
+
+	fmt.Println("matched", flags.Arg(0))
+	return nil
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+
+	"github.com/DevinRiley/nes/cartridge"
+	"github.com/DevinRiley/nes/cpu"
+)
+
+// disasm lists a PRG bank, with labels on the addresses that code calls,
+// jumps or branches to. The bank is decoded from start to end, so data
+// in it comes out as instructions too.
+func disasm(args []string) error {
+	flags := flag.NewFlagSet("nes disasm", flag.ExitOnError)
+	bank := flags.Int("bank", 0, "PRG bank to disassemble")
+	size := flags.Int("size", 16, "bank size in KB")
+	origin := flags.String("org", "", "address the bank is mapped at, in hex, $8000 by default or the end of memory for the last bank")
+	flags.Parse(args)
+
+	path := flags.Arg(0)
+	if path == "" {
+		path = "nestest.nes"
+	}
+
+	rom, err := cartridge.LoadFile(path)
+	if err != nil {
+		return err
+	}
+
+	bankSize := *size * 1024
+	if bankSize <= 0 || bankSize > 0x8000 {
+		return fmt.Errorf("bank size %dKB is not between 1KB and 32KB", *size)
+	}
+	banks := len(rom.PRGData) / bankSize
+	if *bank < 0 || *bank >= banks {
+		return fmt.Errorf("%s has %d %dKB PRG banks, there is no bank %d", path, banks, *size, *bank)
+	}
+
+	start := 0x8000
+	if *bank == banks-1 {
+		start = 0x10000 - bankSize
+	}
+	if *origin != "" {
+		address, err := strconv.ParseUint(*origin, 16, 16)
+		if err != nil {
+			return fmt.Errorf("bad origin %q: %w", *origin, err)
+		}
+		start = int(address)
+	}
+	end := min(start+bankSize, 0x10000)
+
+	var memory cpu.Memory
+	copy(memory[start:end], rom.PRGData[*bank*bankSize:])
+
+	var program []cpu.Disassembly
+	for address := start; address < end; {
+		instruction := cpu.Disassemble(&memory, uint16(address))
+		program = append(program, instruction)
+		address += len(instruction.Bytes)
+	}
+
+	labels := findLabels(&memory, program, start, end)
+
+	fmt.Printf("; %s PRG bank %d, %dKB at $%04X\n", path, *bank, *size, start)
+	for _, instruction := range program {
+		if label, ok := labels[instruction.Address]; ok {
+			fmt.Printf("\n%s:\n", label)
+		}
+		fmt.Printf("  %s\n", instruction.Listing(labels))
+	}
+
+	return nil
+}
+
+// findLabels names the interrupt handlers after their vectors, the
+// targets of JSR sub_XXXX and the targets of jumps and branches L_XXXX.
+// Only addresses inside the bank get a label.
+func findLabels(memory *cpu.Memory, program []cpu.Disassembly, start int, end int) map[uint16]string {
+	labels := map[uint16]string{}
+	label := func(address uint16, name string) {
+		if _, ok := labels[address]; !ok && int(address) >= start && int(address) < end {
+			labels[address] = name
+		}
+	}
+
+	if end == 0x10000 {
+		for _, vector := range []struct {
+			name    string
+			address uint16
+		}{{"nmi", cpu.NMIVector}, {"reset", cpu.ResetVector}, {"irq", cpu.IRQVector}} {
+			label(uint16(memory[vector.address+1])<<8|uint16(memory[vector.address]), vector.name)
+		}
+	}
+
+	for _, instruction := range program {
+		if instruction.Instruction.Assembly == "JSR" {
+			label(instruction.Operand(), fmt.Sprintf("sub_%04X", instruction.Operand()))
+		}
+	}
+
+	for _, instruction := range program {
+		mode := instruction.Instruction.AddressingMode
+		if mode == cpu.Relative || mode == cpu.Absolute && instruction.Instruction.Assembly == "JMP" {
+			label(instruction.Operand(), fmt.Sprintf("L_%04X", instruction.Operand()))
+		}
+	}
+
+	return labels
+}
@@ -0,0 +1,136 @@
+// Command nes runs a ROM on a Console. By default it prints a trace of
+// every instruction, like nestest.log or in the style of FCEUX or Mesen:
+//
+//	nes [-pc ADDR] [-n COUNT] [-format nestest|fceux|mesen] [ROM]
+//
+// The debug subcommand debugs the ROM interactively over stdin and
+// stdout instead:
+//
+//	nes debug [ROM]
+//
+// The disasm subcommand lists a PRG bank with labels:
+//
+//	nes disasm [-bank N] [-size KB] [-org ADDR] [ROM]
+//
+// The diff subcommand runs the ROM against a reference trace like
+// nestest.log and reports where it first differs:
+//
+//	nes diff [-pc ADDR] [-context N] LOG [ROM]
+//
+// The testrom subcommand runs test ROMs that report their result at
+// $6000, like blargg's, and prints a table of the results. A directory
+// runs every ROM in it, and a ROM can be given its own timeout:
+//
+//	nes testrom [-timeout DURATION] ROM[=TIMEOUT]|DIR...
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/DevinRiley/nes"
+	"github.com/DevinRiley/nes/cartridge"
+	"github.com/DevinRiley/nes/cpu"
+)
+
+var traceFormats = map[string]nes.TraceFormat{
+	"nestest": nes.NestestTrace,
+	"fceux":   nes.FCEUXTrace,
+	"mesen":   nes.MesenTrace,
+}
+
+// subcommands are picked by the first argument, anything else is traced
+var subcommands = map[string]func(args []string) error{
+	"debug":   debug,
+	"diff":    diff,
+	"disasm":  disasm,
+	"testrom": testROMs,
+}
+
+func main() {
+	var err error
+	if len(os.Args) > 1 && subcommands[os.Args[1]] != nil {
+		err = subcommands[os.Args[1]](os.Args[2:])
+	} else {
+		err = trace(os.Args[1:])
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func trace(args []string) error {
+	flags := flag.NewFlagSet("nes", flag.ExitOnError)
+	start := flags.String("pc", "", "start at this address, in hex, instead of the reset vector")
+	count := flags.Int("n", 1000, "number of instructions to run")
+	format := flags.String("format", "nestest", "trace format: nestest, fceux or mesen")
+	flags.Parse(args)
+
+	traceFormat, ok := traceFormats[*format]
+	if !ok {
+		return fmt.Errorf("unknown trace format %q", *format)
+	}
+
+	console, err := load(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	defer out.Flush()
+	console.Tracer = nes.NewTracer(out, traceFormat)
+
+	if err := startAt(console, *start); err != nil {
+		return err
+	}
+
+	for i := 0; i < *count; i++ {
+		if err := console.StepInstruction(); err != nil {
+			if unknown, ok := err.(*cpu.UnknownOpcodeError); ok {
+				fmt.Fprint(out, unknown.Trace())
+			}
+			return err
+		}
+	}
+
+	return console.Tracer.Err()
+}
+
+// startAt moves the PC to start, in hex, unless it's empty
+func startAt(console *nes.Console, start string) error {
+	if start == "" {
+		return nil
+	}
+
+	pc, err := strconv.ParseUint(start, 16, 16)
+	if err != nil {
+		return fmt.Errorf("bad start address %q: %w", start, err)
+	}
+	console.CPU.PC = uint16(pc)
+
+	return nil
+}
+
+// load starts a console running the ROM at path, nestest.nes if it's empty
+func load(path string) (*nes.Console, error) {
+	if path == "" {
+		path = "nestest.nes"
+	}
+
+	rom, err := cartridge.LoadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	console := nes.NewConsole()
+	if err := console.Load(rom); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return console, nil
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/DevinRiley/nes/testrom"
+)
+
+func testROMs(args []string) error {
+	flags := flag.NewFlagSet("nes testrom", flag.ExitOnError)
+	timeout := flags.Duration("timeout", testrom.DefaultTimeout, "emulated time to give each ROM")
+	flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		return fmt.Errorf("usage: nes testrom [-timeout DURATION] ROM[=TIMEOUT]|DIR...")
+	}
+
+	var tests []testrom.Test
+	for _, arg := range flags.Args() {
+		test := testrom.Test{Path: arg, Timeout: *timeout}
+		if path, duration, found := strings.Cut(arg, "="); found {
+			var err error
+			if test.Timeout, err = time.ParseDuration(duration); err != nil {
+				return fmt.Errorf("bad timeout for %s: %w", path, err)
+			}
+			test.Path = path
+		}
+
+		found, err := findROMs(test)
+		if err != nil {
+			return err
+		}
+		tests = append(tests, found...)
+	}
+
+	results := testrom.RunAll(tests)
+	if err := testrom.WriteSummary(os.Stdout, results); err != nil {
+		return err
+	}
+
+	for _, result := range results {
+		if result.Outcome != testrom.Passed {
+			return fmt.Errorf("not every ROM passed")
+		}
+	}
+
+	return nil
+}
+
+// findROMs expands a directory into every .nes file under it
+func findROMs(test testrom.Test) ([]testrom.Test, error) {
+	info, err := os.Stat(test.Path)
+	if err != nil || !info.IsDir() {
+		return []testrom.Test{test}, nil
+	}
+
+	var tests []testrom.Test
+	err = filepath.Walk(test.Path, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() && strings.EqualFold(filepath.Ext(path), ".nes") {
+			tests = append(tests, testrom.Test{Path: path, Timeout: test.Timeout})
+		}
+		return err
+	})
+
+	return tests, err
+}
@@ -0,0 +1,283 @@
+package nes
+
+import (
+	"errors"
+
+	"github.com/DevinRiley/nes/apu"
+	"github.com/DevinRiley/nes/cartridge"
+	"github.com/DevinRiley/nes/cpu"
+	"github.com/DevinRiley/nes/ppu"
+)
+
+// ErrNoCartridge is returned by the Console's step functions before a ROM
+// has been loaded.
+var ErrNoCartridge = errors.New("no cartridge loaded")
+
+// The PPU is clocked 3 times per CPU cycle on NTSC and Dendy consoles,
+// and 3.2 times on PAL consoles. Dots are counted in fifths so that both
+// ratios are whole numbers.
+const (
+	dotsPerCycleNTSC = 15
+	dotsPerCyclePAL  = 16
+	dotFraction      = 5
+)
+
+// Console is an NES: the CPU, PPU, APU, controllers and a cartridge on
+// one bus. The CPU clock drives everything else, every CPU cycle steps
+// the PPU, APU and mapper, so the components always stay in lock step
+// whichever of the step functions is used.
+//
+// The CPU, PPU and APU are replaced by Load, so hold on to the Console
+// rather than its components.
+type Console struct {
+	CPU         *cpu.CPU
+	PPU         *ppu.PPU
+	APU         *apu.APU
+	Bus         *Bus
+	Controllers [2]Controller
+
+	ROM       *cartridge.ROM
+	Cartridge cartridge.Mapper
+
+	// Tracer, when set, writes a line for every instruction
+	Tracer *Tracer
+
+	// SampleRate is the audio sample rate the APU is created with by
+	// Load, in samples per second. No samples are made if it is 0.
+	SampleRate float64
+
+	dotsPerCycle int
+	dots         int // fifths of a PPU dot that are owed
+
+	oamPage   byte // the page OAM DMA is copying
+	oamCycles int  // cycles of OAM DMA left to run
+	oamValue  byte // the byte read by OAM DMA, written on the next cycle
+
+	dmcAddress   uint16 // the sample byte DMC DMA is fetching
+	dmcCycles    int    // cycles of DMC DMA left to run
+	dmcRequested uint   // the CPU cycle DMC DMA was asked for in
+}
+
+// NewConsole returns a console with no cartridge inserted.
+func NewConsole() *Console {
+	return &Console{}
+}
+
+// Load inserts a cartridge and powers the console on.
+func (console *Console) Load(rom *cartridge.ROM) error {
+	mapper, err := cartridge.NewMapper(rom)
+	if err != nil {
+		return err
+	}
+
+	console.ROM = rom
+	console.Cartridge = mapper
+	console.CPU = cpu.NewCPU()
+	console.PPU = ppu.NewPPU(mapper)
+	console.APU = apu.NewAPU(rom.TVSystem, console.SampleRate)
+	console.Bus = &Bus{PPU: console.PPU, IO: &consoleIO{console}, Cartridge: mapper}
+
+	console.dotsPerCycle = dotsPerCycleNTSC
+	if rom.TVSystem == cartridge.PAL {
+		console.dotsPerCycle = dotsPerCyclePAL
+	}
+
+	console.CPU.Bus = console.Bus
+	console.CPU.OnCycle = console.cycle
+	console.CPU.OnInstruction = console.trace
+	console.CPU.DMA = console.dma
+	console.PPU.NMI = console.CPU.TriggerNMI
+	console.APU.IRQ = console.CPU.SetIRQ
+	console.APU.DMCRequest = console.dmcRequest
+
+	console.dots = 0
+	console.oamCycles = 0
+	console.dmcCycles = 0
+	for i := range console.Controllers {
+		console.Controllers[i].strobe = false
+		console.Controllers[i].shift = 0
+	}
+
+	console.CPU.PowerOn()
+
+	return nil
+}
+
+// Reset presses the reset button. RAM and the cartridge keep their
+// contents.
+func (console *Console) Reset() error {
+	if console.Cartridge == nil {
+		return ErrNoCartridge
+	}
+
+	console.PPU.Reset()
+	console.APU.Reset()
+	console.CPU.Reset()
+
+	return nil
+}
+
+// PowerCycle turns the console off and on again. Everything is rebuilt
+// from the ROM, so RAM and the cartridge's RAM are cleared too.
+func (console *Console) PowerCycle() error {
+	if console.ROM == nil {
+		return ErrNoCartridge
+	}
+
+	return console.Load(console.ROM)
+}
+
+// StepInstruction runs the CPU for one instruction, or services an
+// interrupt. While the CPU is stalled by DMA, it runs one cycle instead.
+func (console *Console) StepInstruction() error {
+	if console.Cartridge == nil {
+		return ErrNoCartridge
+	}
+
+	return console.CPU.Exec()
+}
+
+// StepFrame runs whole instructions until the PPU has finished the
+// current frame.
+func (console *Console) StepFrame() error {
+	if console.Cartridge == nil {
+		return ErrNoCartridge
+	}
+
+	frame := console.PPU.Frames
+	for console.PPU.Frames == frame {
+		if err := console.CPU.Exec(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RunCycles runs the console for a number of CPU cycles. It can stop
+// in the middle of an instruction, which the next step function will
+// finish.
+func (console *Console) RunCycles(cycles uint) error {
+	if console.Cartridge == nil {
+		return ErrNoCartridge
+	}
+
+	for i := uint(0); i < cycles; i++ {
+		if err := console.CPU.Tick(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// cycle is called at the start of every CPU cycle
+func (console *Console) cycle() {
+	console.dots += console.dotsPerCycle
+	for console.dots >= dotFraction {
+		console.PPU.Step()
+		console.dots -= dotFraction
+	}
+
+	console.APU.Step()
+	console.Cartridge.Step()
+	console.CPU.SetIRQ(cpu.IRQMapper, console.Cartridge.IRQ())
+}
+
+// dma is called on the cycles the CPU is stalled for and makes the bus
+// access for any DMA that owns the cycle
+func (console *Console) dma() bool {
+	// DMC DMA goes first, from the cycle after the one it was asked for in
+	if console.dmcCycles > 0 && console.CPU.Cycles != console.dmcRequested {
+		console.dmcCycles--
+		if console.dmcCycles > 0 {
+			return false
+		}
+
+		console.APU.FillDMC(console.Bus.Read(console.dmcAddress))
+		return true
+	}
+
+	if console.oamCycles > 0 {
+		return console.oamDMACycle()
+	}
+
+	return false
+}
+
+// dmcRequest starts a DMC DMA. The CPU is halted for 4 cycles and the
+// sample byte is read on the last of them.
+func (console *Console) dmcRequest(address uint16) {
+	console.dmcAddress = address
+	console.dmcCycles = 4
+	console.dmcRequested = console.CPU.Cycles
+	console.CPU.Stall(4)
+}
+
+// oamDMA starts copying a page of CPU memory into OAM. The CPU is halted
+// for 513 cycles, plus one to line up with a read cycle when the DMA
+// starts on an odd cycle. The copy itself is done by dma in the cycles
+// the CPU is halted for, a read and a write to OAMDATA every two cycles,
+// so the PPU, APU and mapper keep running while it happens.
+func (console *Console) oamDMA(page byte) {
+	stall := uint(513)
+	if console.CPU.Cycles%2 == 1 {
+		stall++
+	}
+
+	console.oamPage = page
+	console.oamCycles = int(stall)
+	console.CPU.Stall(stall)
+}
+
+// oamDMACycle is one cycle of OAM DMA. The halt and alignment cycles come
+// first and leave the bus to the halted CPU, then the DMA alternates
+// between reading a byte and writing it to OAMDATA.
+func (console *Console) oamDMACycle() bool {
+	console.oamCycles--
+	if console.oamCycles >= 512 {
+		return false
+	}
+
+	transferred := 511 - console.oamCycles
+	if transferred%2 == 0 {
+		console.oamValue = console.Bus.Read(uint16(console.oamPage)<<8 | uint16(transferred/2))
+	} else {
+		console.Bus.Write(0x2004, console.oamValue)
+	}
+
+	return true
+}
+
+// consoleIO handles $4000-$401F: the APU, OAM DMA and the controllers
+type consoleIO struct {
+	console *Console
+}
+
+func (io *consoleIO) Read(address uint16) byte {
+	console := io.console
+
+	switch address {
+	case 0x4015:
+		return console.APU.Read(address)
+	case 0x4016, 0x4017:
+		// only the low bits are driven, the rest is open bus
+		return console.Bus.openBus&0xE0 | console.Controllers[address-0x4016].read()
+	}
+
+	return console.Bus.openBus
+}
+
+func (io *consoleIO) Write(address uint16, value byte) {
+	console := io.console
+
+	switch address {
+	case 0x4014:
+		console.oamDMA(value)
+	case 0x4016:
+		console.Controllers[0].write(value)
+		console.Controllers[1].write(value)
+	default:
+		console.APU.Write(address, value)
+	}
+}
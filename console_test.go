@@ -0,0 +1,308 @@
+package nes
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/DevinRiley/nes/cartridge"
+	"github.com/DevinRiley/nes/cpu"
+)
+
+// testROM is an NROM cartridge that runs program from $8000 on reset and
+// has an NMI handler at $9000 that loops forever
+func testROM(program ...byte) *cartridge.ROM {
+	rom := &cartridge.ROM{PRGSize: 0x4000, PRGData: make([]byte, 0x4000)}
+	copy(rom.PRGData, program)
+	copy(rom.PRGData[0x1000:], []byte{0x4C, 0x00, 0x90}) // JMP $9000
+	copy(rom.PRGData[0x3FFA:], []byte{0x00, 0x90, 0x00, 0x80, 0x00, 0x80})
+
+	return rom
+}
+
+// loop is a program that jumps to itself
+var loop = []byte{0x4C, 0x00, 0x80}
+
+func newTestConsole(t *testing.T, rom *cartridge.ROM) *Console {
+	console := NewConsole()
+	if err := console.Load(rom); err != nil {
+		t.Fatal(err)
+	}
+
+	return console
+}
+
+func ppuDots(console *Console) int {
+	return console.PPU.Scanline*341 + console.PPU.Dot
+}
+
+func TestConsoleNoCartridge(t *testing.T) {
+	console := NewConsole()
+
+	if err := console.StepFrame(); !errors.Is(err, ErrNoCartridge) {
+		t.Error("stepped a console without a cartridge")
+	}
+}
+
+func TestConsoleLoadUnsupportedMapper(t *testing.T) {
+	rom := testROM(loop...)
+	rom.Mapper = 0xFF
+
+	var unsupported *cartridge.UnsupportedMapperError
+	if err := NewConsole().Load(rom); !errors.As(err, &unsupported) {
+		t.Error("did not return an UnsupportedMapperError, got", err)
+	}
+}
+
+func TestConsoleLoadResets(t *testing.T) {
+	console := newTestConsole(t, testROM(loop...))
+
+	if console.CPU.PC != 0x8000 || console.CPU.SP != 0xFD {
+		t.Errorf("did not run the reset sequence, PC is %04X", console.CPU.PC)
+	}
+}
+
+func TestConsoleNTSCClockRatio(t *testing.T) {
+	console := newTestConsole(t, testROM(loop...))
+	dots := ppuDots(console)
+
+	console.RunCycles(100)
+
+	if ppuDots(console)-dots != 300 {
+		t.Error("stepped the PPU", ppuDots(console)-dots, "dots in 100 cycles")
+	}
+}
+
+func TestConsolePALClockRatio(t *testing.T) {
+	rom := testROM(loop...)
+	rom.TVSystem = cartridge.PAL
+	console := newTestConsole(t, rom)
+	dots := ppuDots(console)
+
+	console.RunCycles(100)
+
+	if ppuDots(console)-dots != 320 {
+		t.Error("stepped the PPU", ppuDots(console)-dots, "dots in 100 cycles")
+	}
+}
+
+func TestConsoleStepFrame(t *testing.T) {
+	console := newTestConsole(t, testROM(loop...))
+	console.StepFrame()
+	cycles := console.CPU.Cycles
+
+	console.StepFrame()
+
+	if console.PPU.Frames != 2 {
+		t.Error("did not step a whole frame")
+	}
+
+	// 89342 dots is 29780.67 cycles, give or take an instruction
+	if frame := console.CPU.Cycles - cycles; frame < 29778 || frame > 29784 {
+		t.Error("a frame took", frame, "cycles")
+	}
+}
+
+func TestConsoleNMI(t *testing.T) {
+	// LDA #$80, STA $2000, JMP $8005
+	console := newTestConsole(t, testROM(0xA9, 0x80, 0x8D, 0x00, 0x20, 0x4C, 0x05, 0x80))
+
+	console.StepFrame()
+	console.StepFrame()
+
+	if console.CPU.PC != 0x9000 {
+		t.Errorf("did not take the NMI at vblank, PC is %04X", console.CPU.PC)
+	}
+}
+
+func TestConsoleOAMDMA(t *testing.T) {
+	// LDA #$02, STA $4014, JMP $8005
+	console := newTestConsole(t, testROM(0xA9, 0x02, 0x8D, 0x14, 0x40, 0x4C, 0x05, 0x80))
+	for i := 0; i < 256; i++ {
+		console.Bus.RAM[0x200+i] = byte(i)
+	}
+
+	console.StepInstruction()
+	console.StepInstruction()
+
+	// while stalled every step is a single cycle
+	stalled := 0
+	for {
+		cycles := console.CPU.Cycles
+		console.StepInstruction()
+		if console.CPU.Cycles-cycles != 1 {
+			break
+		}
+		stalled++
+	}
+
+	if stalled != 513 && stalled != 514 {
+		t.Error("stalled the CPU for", stalled, "cycles")
+	}
+
+	console.PPU.Write(0x2003, 0x7F)
+	if console.PPU.Read(0x2004) != 0x7F {
+		t.Error("did not copy the page into OAM")
+	}
+}
+
+func TestConsoleOAMDMAPerCycle(t *testing.T) {
+	// LDA #$02, STA $4014, JMP $8005
+	console := newTestConsole(t, testROM(0xA9, 0x02, 0x8D, 0x14, 0x40, 0x4C, 0x05, 0x80))
+	for i := 0; i < 256; i++ {
+		console.Bus.RAM[0x200+i] = byte(i + 1)
+	}
+
+	console.StepInstruction()
+	console.StepInstruction()
+	dots := ppuDots(console)
+
+	// one or two halt cycles, then a byte every two cycles
+	for i := 0; i < 101; i++ {
+		console.StepInstruction()
+	}
+
+	if ppuDots(console)-dots != 303 {
+		t.Error("did not run the PPU during the DMA, it ran", ppuDots(console)-dots, "dots")
+	}
+
+	console.PPU.Write(0x2003, 48)
+	copied := console.PPU.Read(0x2004)
+	console.PPU.Write(0x2003, 60)
+	if copied != 49 || console.PPU.Read(0x2004) != 0 {
+		t.Error("did not copy a byte every two cycles")
+	}
+}
+
+// cpuReads counts the reads the CPU makes itself, which leaves out the
+// ones made by DMA
+type cpuReads struct {
+	cpu.Bus
+	count int
+}
+
+func (bus *cpuReads) Read(address uint16) byte {
+	bus.count++
+	return bus.Bus.Read(address)
+}
+
+func TestConsoleOAMDMAOwnsBus(t *testing.T) {
+	// LDA #$02, STA $4014, JMP $8005
+	console := newTestConsole(t, testROM(0xA9, 0x02, 0x8D, 0x14, 0x40, 0x4C, 0x05, 0x80))
+	console.Bus.RAM[0x2FF] = 0xAB
+
+	console.StepInstruction()
+	console.StepInstruction()
+	stall := 513 + int(console.CPU.Cycles%2)
+
+	reads := &cpuReads{Bus: console.CPU.Bus}
+	console.CPU.Bus = reads
+	for i := 0; i < stall; i++ {
+		console.StepInstruction()
+	}
+
+	if reads.count != stall-512 {
+		t.Error("the halted CPU read the bus", reads.count, "times, not just on the halt and alignment cycles")
+	}
+	if console.Bus.openBus != 0xAB {
+		t.Errorf("open bus is %02X, not the last byte the DMA moved", console.Bus.openBus)
+	}
+}
+
+func TestConsoleDMCDMA(t *testing.T) {
+	console := newTestConsole(t, testROM(
+		0xA9, 0x0F, // LDA #$0F
+		0x8D, 0x10, 0x40, // STA $4010
+		0xA9, 0x00, // LDA #$00
+		0x8D, 0x12, 0x40, // STA $4012, the sample is at $C000
+		0x8D, 0x13, 0x40, // STA $4013, 1 byte long
+		0xA9, 0x10, // LDA #$10
+		0x8D, 0x15, 0x40, // STA $4015
+		0x4C, 0x12, 0x80, // JMP $8012
+	))
+	for i := 0; i < 7; i++ {
+		console.StepInstruction()
+	}
+
+	reads := &cpuReads{Bus: console.CPU.Bus}
+	console.CPU.Bus = reads
+	for i := 0; i < 3; i++ {
+		console.StepInstruction()
+	}
+	if console.APU.Read(0x4015)&0x10 == 0 {
+		t.Error("fetched the sample byte before the last stolen cycle")
+	}
+
+	console.StepInstruction()
+	if console.APU.Read(0x4015)&0x10 != 0 || console.Bus.openBus != 0xA9 {
+		t.Error("did not fetch the sample byte on the last stolen cycle")
+	}
+	if reads.count != 3 {
+		t.Error("the halted CPU read the bus", reads.count, "times, not 3")
+	}
+}
+
+func TestConsoleSampleRate(t *testing.T) {
+	console := NewConsole()
+	console.SampleRate = 44100
+	if err := console.Load(testROM(loop...)); err != nil {
+		t.Fatal(err)
+	}
+
+	console.StepFrame()
+	if console.APU.SampleRate != 44100 || len(console.APU.Samples()) == 0 {
+		t.Error("did not make samples at the console's sample rate")
+	}
+}
+
+func TestConsoleController(t *testing.T) {
+	console := newTestConsole(t, testROM(loop...))
+	console.Controllers[0].Buttons = ButtonA | ButtonStart | ButtonRight
+
+	console.Bus.Write(0x4016, 0x01)
+	console.Bus.Write(0x4016, 0x00)
+
+	var buttons byte
+	for i := 0; i < 8; i++ {
+		buttons |= (console.Bus.Read(0x4016) & 0x01) << i
+	}
+
+	if Button(buttons) != ButtonA|ButtonStart|ButtonRight {
+		t.Errorf("read buttons %08b", buttons)
+	}
+
+	if console.Bus.Read(0x4016)&0x01 != 1 {
+		t.Error("did not read 1 after the eighth button")
+	}
+
+	if console.Bus.Read(0x4017)&0x01 != 0 {
+		t.Error("read a button from the second controller")
+	}
+}
+
+func TestConsoleControllerStrobe(t *testing.T) {
+	console := newTestConsole(t, testROM(loop...))
+	console.Controllers[0].Buttons = ButtonA
+	console.Bus.Write(0x4016, 0x01)
+
+	for i := 0; i < 3; i++ {
+		if console.Bus.Read(0x4016)&0x01 != 1 {
+			t.Error("did not keep reading A while strobed")
+		}
+	}
+}
+
+func TestConsolePowerCycle(t *testing.T) {
+	console := newTestConsole(t, testROM(loop...))
+	console.Bus.RAM[0x10] = 0x42
+	console.Cartridge.Write(0x6000, 0x42)
+
+	console.Reset()
+	if console.Bus.RAM[0x10] != 0x42 || console.CPU.PC != 0x8000 {
+		t.Error("reset cleared RAM or did not jump to the reset vector")
+	}
+
+	console.PowerCycle()
+	if console.Bus.RAM[0x10] != 0 || console.Cartridge.Read(0x6000) != 0 {
+		t.Error("power cycle did not clear RAM")
+	}
+}
@@ -0,0 +1,45 @@
+package nes
+
+// Button is a bit in Controller.Buttons
+type Button byte
+
+// Buttons in the order the standard controller reports them
+const (
+	ButtonA Button = 1 << iota
+	ButtonB
+	ButtonSelect
+	ButtonStart
+	ButtonUp
+	ButtonDown
+	ButtonLeft
+	ButtonRight
+)
+
+// Controller is a standard NES controller. Writing 1 to bit 0 of $4016
+// makes it reload a shift register from the buttons continuously, and
+// once the bit is cleared every read of $4016 or $4017 returns the next
+// button, starting with A. After all eight buttons it reads back 1s.
+type Controller struct {
+	Buttons Button // the buttons currently held down
+
+	strobe bool
+	shift  byte
+}
+
+func (controller *Controller) write(value byte) {
+	controller.strobe = value&0x01 != 0
+	if controller.strobe {
+		controller.shift = byte(controller.Buttons)
+	}
+}
+
+func (controller *Controller) read() byte {
+	if controller.strobe {
+		return byte(controller.Buttons) & 0x01
+	}
+
+	bit := controller.shift & 0x01
+	controller.shift = controller.shift>>1 | 0x80
+
+	return bit
+}
@@ -0,0 +1,665 @@
+package cpu
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Assemble translates 6502 assembly into machine code, using the same
+// opcode table the CPU executes from. It is a small assembler for tests
+// and tiny programs, and it understands:
+//
+//	label:          a label for the current address
+//	@label:         a local label, only visible up to the next label
+//	NAME = expr     a constant
+//	.org expr       carry on assembling at another address
+//	.byte expr,...  bytes, quoted strings are a byte per character
+//	.word expr,...  little endian words
+//	; comment
+//
+// Instructions are written the usual way, LDA #$10, STA $0200,X,
+// JMP ($FFFC), LDA ($80),Y, BNE loop. An operand that fits in a byte uses
+// the zero page form when there is one, unless it refers to a label that
+// is defined later. Unofficial opcodes are available by their names,
+// official ones are picked where they have the same name and mode.
+//
+// Expressions are numbers ($hex, %binary, decimal, 'c'), labels, * for
+// the current address, the operators + - * / & | ^ << >> and
+// parentheses, and unary -, ~, < for the low byte and > for the high byte.
+// Assembly starts at address 0 if there's no .org.
+func Assemble(source string) (*Program, error) {
+	assembler := &assembler{labels: map[string]int{}, modes: map[int]AddressingMode{}}
+
+	for pass := 1; pass <= 2; pass++ {
+		assembler.pass, assembler.pc, assembler.scope = pass, 0, ""
+		assembler.program = &Program{Labels: map[string]uint16{}}
+
+		for i, line := range strings.Split(source, "\n") {
+			assembler.line = i + 1
+			if err := assembler.assembleLine(line); err != nil {
+				return nil, &AssemblyError{Line: i + 1, Text: strings.TrimSpace(line), Err: err}
+			}
+		}
+	}
+
+	for name, value := range assembler.labels {
+		assembler.program.Labels[name] = uint16(value)
+	}
+
+	return assembler.program, nil
+}
+
+// AssemblyError is returned by Assemble for a line it can't assemble.
+type AssemblyError struct {
+	Line int
+	Text string
+	Err  error
+}
+
+func (err *AssemblyError) Error() string {
+	return fmt.Sprintf("line %d: %v: %s", err.Line, err.Err, err.Text)
+}
+
+func (err *AssemblyError) Unwrap() error {
+	return err.Err
+}
+
+// Program is assembled machine code.
+type Program struct {
+	Segments []Segment         // in the order they were assembled
+	Labels   map[string]uint16 // local labels are named global@local
+}
+
+// Segment is code assembled to consecutive addresses.
+type Segment struct {
+	Address uint16
+	Bytes   []byte
+}
+
+// Start is the address of the first byte assembled.
+func (program *Program) Start() uint16 {
+	if len(program.Segments) == 0 {
+		return 0
+	}
+
+	return program.Segments[0].Address
+}
+
+// Load writes the program into memory.
+func (program *Program) Load(bus Bus) {
+	for _, segment := range program.Segments {
+		for i, value := range segment.Bytes {
+			bus.Write(segment.Address+uint16(i), value)
+		}
+	}
+}
+
+// Image is the program as size bytes starting at start, for building a
+// ROM. Anything the program doesn't cover is 0.
+func (program *Program) Image(start uint16, size int) []byte {
+	image := make([]byte, size)
+
+	for _, segment := range program.Segments {
+		for i, value := range segment.Bytes {
+			if offset := int(segment.Address) + i - int(start); offset >= 0 && offset < size {
+				image[offset] = value
+			}
+		}
+	}
+
+	return image
+}
+
+// Assemble assembles source into the CPU's memory and points PC at its
+// first byte. It is meant for tests, so they can be written as code
+// rather than bytes.
+func (cpu *CPU) Assemble(source string) (*Program, error) {
+	program, err := Assemble(source)
+	if err != nil {
+		return nil, err
+	}
+
+	program.Load(cpu.Bus)
+	cpu.PC = program.Start()
+
+	return program, nil
+}
+
+// opcodes is instructionMap turned around, mnemonic then addressing mode
+var opcodes = func() map[string]map[AddressingMode]Instruction {
+	opcodes := map[string]map[AddressingMode]Instruction{}
+
+	for opcode := 0; opcode <= 0xFF; opcode++ {
+		instruction, ok := Lookup(byte(opcode))
+		if !ok {
+			continue
+		}
+
+		modes := opcodes[instruction.Assembly]
+		if modes == nil {
+			modes = map[AddressingMode]Instruction{}
+			opcodes[instruction.Assembly] = modes
+		}
+
+		if existing, ok := modes[instruction.AddressingMode]; !ok || existing.Unofficial && !instruction.Unofficial {
+			modes[instruction.AddressingMode] = instruction
+		}
+	}
+
+	return opcodes
+}()
+
+type assembler struct {
+	program *Program
+	pass    int
+	line    int
+	pc      int
+	scope   string // the last global label, local labels belong to it
+	labels  map[string]int
+
+	// modes remembers the addressing mode chosen for each line on the
+	// first pass, so labels defined later don't change any sizes
+	modes map[int]AddressingMode
+}
+
+func (assembler *assembler) assembleLine(line string) error {
+	line = strings.TrimSpace(stripComment(line))
+
+	if name, rest, ok := cutLabel(line); ok {
+		if err := assembler.define(name, assembler.pc, true); err != nil {
+			return err
+		}
+		line = strings.TrimSpace(rest)
+	}
+	if line == "" {
+		return nil
+	}
+
+	if name, expression, ok := strings.Cut(line, "="); ok && isIdentifier(strings.TrimSpace(name)) {
+		value, known, err := assembler.eval(expression)
+		if err != nil || !known {
+			return err
+		}
+		return assembler.define(strings.TrimSpace(name), value, false)
+	}
+
+	word, operand := line, ""
+	if i := strings.IndexAny(line, " \t"); i >= 0 {
+		word, operand = line[:i], strings.TrimSpace(line[i:])
+	}
+
+	switch strings.ToLower(word) {
+	case ".org":
+		value, known, err := assembler.eval(operand)
+		if err != nil {
+			return err
+		}
+		if !known {
+			return fmt.Errorf(".org must not refer to labels defined later")
+		}
+		if value < 0 || value > 0xFFFF {
+			return fmt.Errorf(".org $%X is outside memory", value)
+		}
+		assembler.pc = value
+		assembler.program.Segments = append(assembler.program.Segments, Segment{Address: uint16(value)})
+		return nil
+	case ".byte", ".db":
+		return assembler.data(operand, 1)
+	case ".word", ".dw":
+		return assembler.data(operand, 2)
+	}
+
+	return assembler.instruction(strings.ToUpper(word), operand)
+}
+
+// define names a value. A global label starts a new scope for local
+// labels, constants don't.
+func (assembler *assembler) define(name string, value int, label bool) error {
+	if strings.HasPrefix(name, "@") {
+		name = assembler.scope + name
+	} else if label {
+		assembler.scope = name
+	}
+
+	// constants that use later labels are only defined on the second pass
+	_, defined := assembler.labels[name]
+	if defined && assembler.pass == 1 {
+		return fmt.Errorf("%s is already defined", name)
+	}
+	if !defined {
+		assembler.labels[name] = value
+	}
+
+	return nil
+}
+
+func (assembler *assembler) data(operands string, size int) error {
+	for _, operand := range splitOperands(operands) {
+		if size == 1 && len(operand) >= 2 && operand[0] == '"' && operand[len(operand)-1] == '"' {
+			assembler.emit([]byte(operand[1 : len(operand)-1])...)
+			continue
+		}
+
+		value, _, err := assembler.eval(operand)
+		if err != nil {
+			return err
+		}
+
+		if size == 1 {
+			if value < -0x80 || value > 0xFF {
+				return fmt.Errorf("%s is more than a byte", operand)
+			}
+			assembler.emit(byte(value))
+		} else {
+			if value < -0x8000 || value > 0xFFFF {
+				return fmt.Errorf("%s is more than a word", operand)
+			}
+			assembler.emit(byte(value), byte(value>>8))
+		}
+	}
+
+	return nil
+}
+
+func (assembler *assembler) instruction(mnemonic string, operand string) error {
+	modes, ok := opcodes[mnemonic]
+	if !ok {
+		return fmt.Errorf("unknown instruction %s", mnemonic)
+	}
+
+	mode, expression, err := assembler.addressingMode(modes, operand)
+	if err != nil {
+		return err
+	}
+
+	instruction, ok := modes[mode]
+	if !ok {
+		return fmt.Errorf("%s can't be used with that operand", mnemonic)
+	}
+
+	if mode == Implied || mode == Accumulator {
+		assembler.emit(instruction.Opcode)
+		return nil
+	}
+
+	value, known, err := assembler.eval(expression)
+	if err != nil {
+		return err
+	}
+	if !known {
+		// the first pass only needs the size
+		value = 0
+		if mode == Relative {
+			value = assembler.pc + 2
+		}
+	}
+
+	switch {
+	case mode == Relative:
+		offset := value - (assembler.pc + 2)
+		if offset < -128 || offset > 127 {
+			return fmt.Errorf("branch to $%04X is out of range", value)
+		}
+		assembler.emit(instruction.Opcode, byte(offset))
+	case instruction.Bytes == 2:
+		if value < -0x80 || value > 0xFF {
+			return fmt.Errorf("operand $%X is more than a byte", value)
+		}
+		assembler.emit(instruction.Opcode, byte(value))
+	default:
+		if value < 0 || value > 0xFFFF {
+			return fmt.Errorf("address $%X is outside memory", value)
+		}
+		assembler.emit(instruction.Opcode, byte(value), byte(value>>8))
+	}
+
+	return nil
+}
+
+// addressingMode works out the mode from the operand's syntax, and for
+// the modes that come in zero page and absolute forms, from its value.
+func (assembler *assembler) addressingMode(modes map[AddressingMode]Instruction, operand string) (AddressingMode, string, error) {
+	upper := strings.ToUpper(strings.ReplaceAll(operand, " ", ""))
+	_, hasIndirect := modes[Indirect]
+
+	switch {
+	case operand == "":
+		if _, ok := modes[Accumulator]; ok {
+			return Accumulator, "", nil
+		}
+		return Implied, "", nil
+	case upper == "A":
+		return Accumulator, "", nil
+	case strings.HasPrefix(operand, "#"):
+		return Immediate, operand[1:], nil
+	case strings.HasPrefix(upper, "(") && strings.HasSuffix(upper, ",X)"):
+		return IndexedIndirect, trimIndex(operand[1:len(operand)-1], "X"), nil
+	case strings.HasPrefix(upper, "(") && strings.HasSuffix(upper, "),Y"):
+		pointer := trimIndex(operand, "Y")
+		return IndirectIndexed, pointer[1 : len(pointer)-1], nil
+	case hasIndirect && strings.HasPrefix(upper, "(") && strings.HasSuffix(upper, ")"):
+		return Indirect, operand[1 : len(operand)-1], nil
+	}
+
+	if _, ok := modes[Relative]; ok {
+		return Relative, operand, nil
+	}
+
+	zeroPage, absolute, expression := ZeroPage, Absolute, operand
+	switch {
+	case strings.HasSuffix(upper, ",X"):
+		zeroPage, absolute, expression = ZeroPageX, AbsoluteX, trimIndex(operand, "X")
+	case strings.HasSuffix(upper, ",Y"):
+		zeroPage, absolute, expression = ZeroPageY, AbsoluteY, trimIndex(operand, "Y")
+	}
+
+	if mode, ok := assembler.modes[assembler.line]; ok && assembler.pass == 2 {
+		return mode, expression, nil
+	}
+
+	value, known, err := assembler.eval(expression)
+	if err != nil {
+		return 0, "", err
+	}
+
+	mode := absolute
+	_, hasZeroPage := modes[zeroPage]
+	_, hasAbsolute := modes[absolute]
+	if hasZeroPage && (!hasAbsolute || known && value >= 0 && value <= 0xFF) {
+		mode = zeroPage
+	}
+	assembler.modes[assembler.line] = mode
+
+	return mode, expression, nil
+}
+
+func (assembler *assembler) emit(values ...byte) {
+	program := assembler.program
+	if len(program.Segments) == 0 {
+		program.Segments = append(program.Segments, Segment{Address: uint16(assembler.pc)})
+	}
+
+	segment := &program.Segments[len(program.Segments)-1]
+	segment.Bytes = append(segment.Bytes, values...)
+	assembler.pc += len(values)
+}
+
+// eval works out an expression. Labels that aren't defined yet are
+// allowed on the first pass, known is false when the value needed one.
+func (assembler *assembler) eval(expression string) (value int, known bool, err error) {
+	parser := &expressionParser{assembler: assembler, text: expression, known: true}
+
+	value = parser.binary(0)
+	if parser.err == nil {
+		parser.skipSpace()
+		if parser.position < len(parser.text) {
+			parser.err = fmt.Errorf("unexpected %q in expression", parser.text[parser.position:])
+		}
+	}
+
+	return value, parser.known, parser.err
+}
+
+// binary operators by precedence, loosest first
+var precedence = [][]string{
+	{"|"},
+	{"^"},
+	{"&"},
+	{"<<", ">>"},
+	{"+", "-"},
+	{"*", "/"},
+}
+
+type expressionParser struct {
+	assembler *assembler
+	text      string
+	position  int
+	known     bool
+	err       error
+}
+
+func (parser *expressionParser) binary(level int) int {
+	if level == len(precedence) {
+		return parser.unary()
+	}
+
+	value := parser.binary(level + 1)
+	for parser.err == nil {
+		operator := parser.operator(precedence[level])
+		if operator == "" {
+			return value
+		}
+
+		right := parser.binary(level + 1)
+		if (operator == "<<" || operator == ">>") && (right < 0 || right > 16) {
+			parser.fail(fmt.Sprintf("can't shift by %d", right))
+			return 0
+		}
+
+		switch operator {
+		case "|":
+			value |= right
+		case "^":
+			value ^= right
+		case "&":
+			value &= right
+		case "<<":
+			value <<= right
+		case ">>":
+			value >>= right
+		case "+":
+			value += right
+		case "-":
+			value -= right
+		case "*":
+			value *= right
+		case "/":
+			if right == 0 {
+				if parser.known {
+					parser.err = fmt.Errorf("division by zero")
+				}
+				return 0
+			}
+			value /= right
+		}
+	}
+
+	return value
+}
+
+func (parser *expressionParser) operator(operators []string) string {
+	parser.skipSpace()
+	for _, operator := range operators {
+		if strings.HasPrefix(parser.text[parser.position:], operator) {
+			parser.position += len(operator)
+			return operator
+		}
+	}
+
+	return ""
+}
+
+func (parser *expressionParser) unary() int {
+	switch parser.operator([]string{"-", "~", "<", ">"}) {
+	case "-":
+		return -parser.unary()
+	case "~":
+		return ^parser.unary() & 0xFFFF
+	case "<":
+		return parser.unary() & 0xFF
+	case ">":
+		return parser.unary() >> 8 & 0xFF
+	}
+
+	return parser.primary()
+}
+
+func (parser *expressionParser) primary() int {
+	parser.skipSpace()
+	rest := parser.text[parser.position:]
+
+	switch {
+	case rest == "":
+		parser.fail("missing value")
+		return 0
+	case rest[0] == '(':
+		parser.position++
+		value := parser.binary(0)
+		if parser.operator([]string{")"}) == "" {
+			parser.fail("missing )")
+		}
+		return value
+	case rest[0] == '*':
+		parser.position++
+		return parser.assembler.pc
+	case rest[0] == '\'':
+		if len(rest) < 3 || rest[2] != '\'' {
+			parser.fail("bad character")
+			return 0
+		}
+		parser.position += 3
+		return int(rest[1])
+	case rest[0] == '$' || rest[0] == '%' || rest[0] >= '0' && rest[0] <= '9':
+		return parser.number()
+	}
+
+	name := parser.identifier()
+	if name == "" {
+		parser.fail(fmt.Sprintf("unexpected %q in expression", rest))
+		return 0
+	}
+	if strings.HasPrefix(name, "@") {
+		name = parser.assembler.scope + name
+	}
+
+	value, ok := parser.assembler.labels[name]
+	if !ok {
+		if parser.assembler.pass == 2 {
+			parser.fail(fmt.Sprintf("%s is not defined", name))
+		}
+		parser.known = false
+	}
+
+	return value
+}
+
+func (parser *expressionParser) number() int {
+	base, start := 10, parser.position
+	switch parser.text[start] {
+	case '$':
+		base, start = 16, start+1
+	case '%':
+		base, start = 2, start+1
+	}
+
+	end := start
+	for end < len(parser.text) && strings.ContainsRune("0123456789abcdefABCDEF", rune(parser.text[end])) {
+		end++
+	}
+	parser.position = end
+
+	value, err := strconv.ParseInt(parser.text[start:end], base, 32)
+	if err != nil {
+		parser.fail(fmt.Sprintf("bad number %q", parser.text[start:end]))
+	}
+
+	return int(value)
+}
+
+func (parser *expressionParser) identifier() string {
+	start := parser.position
+	for parser.position < len(parser.text) && isIdentifierByte(parser.text[parser.position], parser.position == start) {
+		parser.position++
+	}
+
+	return parser.text[start:parser.position]
+}
+
+func (parser *expressionParser) skipSpace() {
+	for parser.position < len(parser.text) && (parser.text[parser.position] == ' ' || parser.text[parser.position] == '\t') {
+		parser.position++
+	}
+}
+
+func (parser *expressionParser) fail(message string) {
+	if parser.err == nil {
+		parser.err = fmt.Errorf("%s", message)
+	}
+}
+
+func isIdentifierByte(c byte, first bool) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' ||
+		first && c == '@' || !first && c >= '0' && c <= '9'
+}
+
+func isIdentifier(text string) bool {
+	if text == "" {
+		return false
+	}
+
+	for i := 0; i < len(text); i++ {
+		if !isIdentifierByte(text[i], i == 0) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// cutLabel splits a label definition off the front of a line
+func cutLabel(line string) (name string, rest string, ok bool) {
+	name, rest, ok = strings.Cut(line, ":")
+	if !ok || !isIdentifier(name) {
+		return "", line, false
+	}
+
+	return name, rest, true
+}
+
+func stripComment(line string) string {
+	quoted := false
+	for i, c := range line {
+		switch {
+		case c == '"':
+			quoted = !quoted
+		case c == ';' && !quoted:
+			return line[:i]
+		}
+	}
+
+	return line
+}
+
+// splitOperands splits on commas outside of quotes and parentheses
+func splitOperands(text string) []string {
+	var operands []string
+	depth, quoted, start := 0, false, 0
+
+	for i, c := range text {
+		switch {
+		case c == '"':
+			quoted = !quoted
+		case quoted:
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == ',' && depth == 0:
+			operands = append(operands, strings.TrimSpace(text[start:i]))
+			start = i + 1
+		}
+	}
+
+	return append(operands, strings.TrimSpace(text[start:]))
+}
+
+// trimIndex removes the ,X or ,Y from the end of an operand
+func trimIndex(operand string, register string) string {
+	i := strings.LastIndex(strings.ToUpper(operand), ","+register)
+	if i < 0 {
+		i = strings.LastIndex(strings.ToUpper(operand), ",")
+	}
+
+	return strings.TrimSpace(operand[:i])
+}
@@ -0,0 +1,188 @@
+package cpu
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func assemble(t *testing.T, source string) *Program {
+	program, err := Assemble(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return program
+}
+
+// Every opcode the assembler picks for a mnemonic and mode should come
+// back out of the disassembly of that opcode
+func TestAssembleEveryOpcode(t *testing.T) {
+	for opcode := 0; opcode <= 0xFF; opcode++ {
+		memory := &Memory{}
+		copy(memory[0x1000:], []byte{byte(opcode), 0x34, 0x12})
+
+		disassembly := Disassemble(memory, 0x1000)
+		instruction := disassembly.Instruction
+		if opcodes[instruction.Assembly][instruction.AddressingMode].Opcode != byte(opcode) {
+			continue
+		}
+
+		source := ".org $1000\n" + strings.TrimPrefix(disassembly.String(), "*")
+		program, err := Assemble(source)
+		if err != nil {
+			t.Errorf("did not assemble %02X %s: %v", opcode, disassembly, err)
+			continue
+		}
+
+		if !bytes.Equal(program.Segments[0].Bytes, disassembly.Bytes) {
+			t.Errorf("did not assemble %s to %02X, got %02X", disassembly, disassembly.Bytes, program.Segments[0].Bytes)
+		}
+	}
+}
+
+func TestAssemblePrefersOfficialOpcodes(t *testing.T) {
+	program := assemble(t, "SBC #1\nNOP")
+
+	if !bytes.Equal(program.Segments[0].Bytes, []byte{0xE9, 0x01, 0xEA}) {
+		t.Errorf("did not pick the official opcodes, got %02X", program.Segments[0].Bytes)
+	}
+}
+
+func TestAssembleLabels(t *testing.T) {
+	program := assemble(t, `
+		.org $8000
+start:	LDX #0
+@loop:	INX
+		BNE @loop
+		JSR sub
+		JMP start
+sub:	LDA data,X      ; data is after, so this stays absolute
+@loop:	DEY
+		BPL @loop
+		RTS
+data:	.byte 1, 2
+`)
+
+	expected := []byte{
+		0xA2, 0x00, // LDX #0
+		0xE8,       // INX
+		0xD0, 0xFD, // BNE start@loop
+		0x20, 0x0B, 0x80, // JSR sub
+		0x4C, 0x00, 0x80, // JMP start
+		0xBD, 0x12, 0x80, // LDA data,X
+		0x88,       // DEY
+		0x10, 0xFD, // BPL sub@loop
+		0x60, // RTS
+		0x01, 0x02,
+	}
+	if !bytes.Equal(program.Segments[0].Bytes, expected) {
+		t.Errorf("did not assemble the labels, got % 02X", program.Segments[0].Bytes)
+	}
+
+	if program.Labels["sub"] != 0x800B || program.Labels["sub@loop"] != 0x800E {
+		t.Error("did not return the labels")
+	}
+}
+
+func TestAssembleZeroPage(t *testing.T) {
+	program := assemble(t, "counter = $10\nINC counter\nLDA counter,X\nLDX counter,Y\nSTA $0010")
+
+	expected := []byte{0xE6, 0x10, 0xB5, 0x10, 0xB6, 0x10, 0x85, 0x10}
+	if !bytes.Equal(program.Segments[0].Bytes, expected) {
+		t.Errorf("did not use zero page addressing, got % 02X", program.Segments[0].Bytes)
+	}
+}
+
+func TestAssembleDirectives(t *testing.T) {
+	program := assemble(t, `
+		.org $C000
+		.word $1234, end
+		.byte "hi", 'a', -1
+end:
+		.org $FFFC
+		.word $C000
+`)
+
+	if len(program.Segments) != 2 || program.Start() != 0xC000 || program.Segments[1].Address != 0xFFFC {
+		t.Fatal("did not start a segment at each .org")
+	}
+	if !bytes.Equal(program.Segments[0].Bytes, []byte{0x34, 0x12, 0x08, 0xC0, 'h', 'i', 'a', 0xFF}) {
+		t.Errorf("did not assemble the data, got % 02X", program.Segments[0].Bytes)
+	}
+
+	image := program.Image(0xC000, 0x4000)
+	if image[0] != 0x34 || image[0x3FFC] != 0x00 || image[0x3FFD] != 0xC0 {
+		t.Error("did not build the image")
+	}
+}
+
+func TestAssembleExpressions(t *testing.T) {
+	for expression, expected := range map[string]int{
+		"1+2*3":         7,
+		"(1+2)*3":       9,
+		"$FF & %1010":   0x0A,
+		"1 << 4 | 1":    0x11,
+		"<$1234":        0x34,
+		">$1234":        0x12,
+		"~0 & $FF":      0xFF,
+		"-1 + 2":        1,
+		"*+2":           0x0202,
+		"label - 1":     0x01FF,
+		"'A' ^ $20":     'a',
+		"$100 / 2 >> 1": 0x40,
+	} {
+		program := assemble(t, ".org $0200\nlabel: .word "+expression)
+
+		if value := int(program.Segments[0].Bytes[0]) | int(program.Segments[0].Bytes[1])<<8; value != expected {
+			t.Errorf("did not evaluate %s to %X, got %X", expression, expected, value)
+		}
+	}
+}
+
+func TestAssembleErrors(t *testing.T) {
+	for source, message := range map[string]string{
+		"NOP\nFOO #1":                   "line 2: unknown instruction FOO",
+		"BNE far\n.org $1000\nfar: NOP": "line 1: branch to $1000 is out of range",
+		"JMP nowhere":                   "line 1: nowhere is not defined",
+		"LDA #$100":                     "line 1: operand $100 is more than a byte",
+		"a: NOP\na: NOP":                "line 2: a is already defined",
+		"STX $1234,X":                   "line 1: STX can't be used with that operand",
+	} {
+		_, err := Assemble(source)
+
+		var assemblyError *AssemblyError
+		if !errors.As(err, &assemblyError) || !strings.HasPrefix(err.Error(), message) {
+			t.Errorf("did not return %q, got %v", message, err)
+		}
+	}
+}
+
+func TestCPUAssemble(t *testing.T) {
+	cpu := NewCPU()
+	_, err := cpu.Assemble(`
+		.org $0600
+		LDX #5
+		LDA #0
+		CLC
+@add:	ADC #3
+		DEX
+		BNE @add
+		STA $10
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cpu.PC != 0x0600 {
+		t.Errorf("did not point PC at the program, got %04X", cpu.PC)
+	}
+
+	for cpu.PC != 0x060C {
+		cpu.Exec()
+	}
+	if cpu.Bus.Read(0x10) != 15 {
+		t.Error("did not run the program, got", cpu.Bus.Read(0x10))
+	}
+}
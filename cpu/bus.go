@@ -0,0 +1,41 @@
+package cpu
+
+// Bus is the 16 bit address space the CPU sees. Every memory access the
+// CPU makes, including stack operations and instruction fetches, goes
+// through the bus so that devices can be mapped into the address space.
+type Bus interface {
+	Read(address uint16) byte
+	Write(address uint16, value byte)
+}
+
+// Memory is a flat 64KB address space with nothing mapped into it. It is
+// handy for tests and for running 6502 code outside of an NES.
+type Memory [0x10000]byte
+
+func (memory *Memory) Read(address uint16) byte {
+	return memory[address]
+}
+
+func (memory *Memory) Write(address uint16, value byte) {
+	memory[address] = value
+}
+
+// Peeker is a Bus that can read memory without the side effects a read
+// can have, like reading $2002 clearing the PPU's vblank flag. Debugging
+// tools peek instead of reading when the bus allows it.
+type Peeker interface {
+	Peek(address uint16) byte
+}
+
+func (memory *Memory) Peek(address uint16) byte {
+	return memory[address]
+}
+
+// peek reads through Peek if the bus has it
+func peek(bus Bus, address uint16) byte {
+	if peeker, ok := bus.(Peeker); ok {
+		return peeker.Peek(address)
+	}
+
+	return bus.Read(address)
+}
@@ -0,0 +1,656 @@
+// Package cpu emulates the 6502 core of the NES's 2A03, including the
+// unofficial opcodes, cycle by cycle.
+package cpu
+
+import (
+	"fmt"
+	"iter"
+	"strings"
+)
+
+// Interrupt vectors
+const (
+	NMIVector   uint16 = 0xFFFA
+	ResetVector uint16 = 0xFFFC
+	IRQVector   uint16 = 0xFFFE
+)
+
+// IRQSource identifies a device that can pull the shared IRQ line low.
+// The line stays asserted for as long as any source is holding it.
+type IRQSource uint8
+
+const (
+	IRQExternal IRQSource = 1 << iota
+	IRQFrameCounter
+	IRQDMC
+	IRQMapper
+)
+
+// UnknownOpcodePolicy controls what Exec does when it fetches an opcode
+// that has no entry in the instruction table.
+type UnknownOpcodePolicy int
+
+const (
+	// HaltOnUnknownOpcode halts the CPU and returns an UnknownOpcodeError
+	HaltOnUnknownOpcode UnknownOpcodePolicy = iota
+	// SkipUnknownOpcode treats the opcode as a one byte, two cycle NOP
+	SkipUnknownOpcode
+	// CallbackOnUnknownOpcode hands the error to CPU.OnUnknownOpcode. The
+	// opcode is skipped like a NOP if the callback returns nil, otherwise
+	// the CPU halts and Exec returns the callback's error.
+	CallbackOnUnknownOpcode
+)
+
+// HistorySize is the number of recently executed instructions the CPU
+// remembers for diagnostics.
+const HistorySize = 32
+
+// HistoryEntry is the CPU state just before an instruction was executed.
+type HistoryEntry struct {
+	PC     uint16
+	Opcode byte
+	A      byte
+	X      byte
+	Y      byte
+	P      byte
+	SP     byte
+	Cycles uint
+}
+
+func (entry HistoryEntry) String() string {
+	return fmt.Sprintf("%04X  %02X  A:%02X X:%02X Y:%02X P:%02X SP:%02X CYC:%d",
+		entry.PC, entry.Opcode, entry.A, entry.X, entry.Y, entry.P, entry.SP, entry.Cycles)
+}
+
+// UnknownOpcodeError is returned by Exec when the CPU fetches an opcode
+// that it does not know how to execute.
+type UnknownOpcodeError struct {
+	PC      uint16
+	Opcode  byte
+	History []HistoryEntry // oldest first, the failing fetch is not included
+}
+
+func (err *UnknownOpcodeError) Error() string {
+	return fmt.Sprintf("unknown opcode $%02X at $%04X", err.Opcode, err.PC)
+}
+
+// Trace formats the instructions leading up to the unknown opcode.
+func (err *UnknownOpcodeError) Trace() string {
+	var trace strings.Builder
+
+	for _, entry := range err.History {
+		trace.WriteString(entry.String())
+		trace.WriteString("\n")
+	}
+
+	return trace.String()
+}
+
+type CPU struct {
+	PC     uint16
+	SP     uint8
+	A      uint8
+	X      uint8
+	Y      uint8
+	F      byte // 8 bit status flags
+	NFlag  bool // negative flag
+	VFlag  bool // overflow flag
+	UFlag  bool // unused
+	BFlag  bool // technically unused (doesn't exist on hardware processor)
+	DFlag  bool // decimal flag - no effect on NES
+	IFlag  bool // interrupt disable flag
+	ZFlag  bool // zero flag
+	CFlag  bool // carry flag
+	Cycles uint
+	Bus    Bus
+	Halted bool // set by the KIL opcodes, cleared by Reset
+
+	// OnCycle is called at the start of every CPU cycle, before that
+	// cycle's bus access. It is how the rest of the system is kept in
+	// step with the CPU.
+	OnCycle func()
+
+	// OnInstruction is called before every instruction is fetched, once
+	// any pending interrupt has been serviced. Tracers use it to see
+	// each instruction with the registers as they are before it runs.
+	OnInstruction func()
+
+	// DMA is called on every cycle the CPU is stalled for, after OnCycle.
+	// It returns true when the DMA used the bus in that cycle, otherwise
+	// the halted CPU makes its own read.
+	DMA func() bool
+
+	UnknownOpcodePolicy UnknownOpcodePolicy
+	OnUnknownOpcode     func(*CPU, *UnknownOpcodeError) error
+
+	stall      uint      // cycles the CPU is halted for by DMA
+	nmiPending bool      // latched on the falling edge of the NMI line
+	irqLine    IRQSource // sources currently asserting IRQ
+
+	// Interrupts are polled at the end of every cycle, but the decision
+	// to service one is made on the poll from the second to last cycle
+	// of an instruction. So the CPU keeps the previous cycle's poll.
+	nmiPoll    bool
+	irqPoll    bool
+	nmiService bool
+	irqService bool
+
+	// Tick runs instructions as a coroutine that yields after every
+	// cycle
+	nextCycle func() (error, bool)
+	stopCycle func()
+	yield     func()
+
+	history      [HistorySize]HistoryEntry
+	historyIndex int // next slot to be written
+	historyCount int
+}
+
+// String describes the registers and the cycle count, for logging.
+func (cpu *CPU) String() string {
+	return fmt.Sprintf("PC:%04X A:%02X X:%02X Y:%02X P:%02X SP:%02X CYC:%d",
+		cpu.PC, cpu.A, cpu.X, cpu.Y, cpu.Status(), cpu.SP, cpu.Cycles)
+}
+
+// Exec executes a single instruction, or services a pending interrupt.
+// An error is only returned when an unknown opcode is fetched, see
+// UnknownOpcodePolicy. If Tick has left an instruction half way through,
+// that instruction is completed first.
+func (cpu *CPU) Exec() error {
+	cpu.stopTicking()
+	return cpu.exec()
+}
+
+// Tick advances the CPU by exactly one cycle, which is one bus access.
+// It runs the same instruction code as Exec, suspended between bus
+// accesses, so reads and writes happen at the same point relative to
+// the rest of the system as they do on hardware. An error from an
+// unknown opcode is returned from the Tick after the opcode fetch.
+func (cpu *CPU) Tick() error {
+	if cpu.nextCycle == nil {
+		cpu.nextCycle, cpu.stopCycle = iter.Pull(cpu.cycles)
+	}
+
+	err, _ := cpu.nextCycle()
+	return err
+}
+
+// cycles executes instructions forever, yielding after each cycle.
+func (cpu *CPU) cycles(yield func(error) bool) {
+	var err error
+
+	cpu.yield = func() {
+		if !yield(err) {
+			// Tick has been stopped, finish the current instruction
+			// without yielding again
+			cpu.yield = nil
+		}
+		err = nil
+	}
+
+	for cpu.yield != nil {
+		err = cpu.exec()
+	}
+}
+
+// MidInstruction reports whether Tick has left an instruction, or an
+// interrupt sequence, part way through.
+func (cpu *CPU) MidInstruction() bool {
+	return cpu.nextCycle != nil
+}
+
+func (cpu *CPU) stopTicking() {
+	if cpu.stopCycle != nil {
+		cpu.stopCycle()
+		cpu.nextCycle = nil
+		cpu.stopCycle = nil
+		cpu.yield = nil
+	}
+}
+
+func (cpu *CPU) exec() error {
+	if cpu.Halted {
+		// the data bus is stuck at $FF, so the CPU keeps reading $FFFF
+		cpu.read(0xFFFF)
+		return nil
+	}
+
+	if cpu.stall > 0 {
+		cpu.stallCycle(cpu.PC)
+		return nil
+	}
+
+	if cpu.nmiService || cpu.irqService {
+		cpu.interrupt()
+		return nil
+	}
+
+	if cpu.OnInstruction != nil {
+		cpu.OnInstruction()
+	}
+
+	pc, cycles := cpu.PC, cpu.Cycles
+	opcode := cpu.fetch()
+	instruction, ok := instructionMap[opcode]
+	if !ok || instruction.exec == nil {
+		return cpu.unknownOpcode(pc, opcode)
+	}
+
+	cpu.record(pc, opcode, cycles)
+	context := context(cpu, instruction)
+	instruction.exec(cpu, context)
+
+	return nil
+}
+
+// History returns the most recently executed instructions, oldest first.
+func (cpu *CPU) History() []HistoryEntry {
+	history := make([]HistoryEntry, 0, cpu.historyCount)
+	start := cpu.historyIndex - cpu.historyCount
+
+	for i := 0; i < cpu.historyCount; i++ {
+		history = append(history, cpu.history[(start+i+HistorySize)%HistorySize])
+	}
+
+	return history
+}
+
+func (cpu *CPU) record(pc uint16, opcode byte, cycles uint) {
+	cpu.history[cpu.historyIndex] = HistoryEntry{
+		PC:     pc,
+		Opcode: opcode,
+		A:      cpu.A,
+		X:      cpu.X,
+		Y:      cpu.Y,
+		P:      cpu.Status(),
+		SP:     cpu.SP,
+		Cycles: cycles,
+	}
+	cpu.historyIndex = (cpu.historyIndex + 1) % HistorySize
+	if cpu.historyCount < HistorySize {
+		cpu.historyCount++
+	}
+}
+
+func (cpu *CPU) unknownOpcode(pc uint16, opcode byte) error {
+	err := &UnknownOpcodeError{
+		PC:      pc,
+		Opcode:  opcode,
+		History: cpu.History(),
+	}
+
+	var result error = err
+	switch cpu.UnknownOpcodePolicy {
+	case SkipUnknownOpcode:
+		result = nil
+	case CallbackOnUnknownOpcode:
+		if cpu.OnUnknownOpcode != nil {
+			result = cpu.OnUnknownOpcode(cpu, err)
+		}
+	}
+
+	if result != nil {
+		cpu.PC = pc
+		cpu.Halted = true
+		return result
+	}
+
+	cpu.read(cpu.PC)
+	return nil
+}
+
+// TriggerNMI signals a falling edge on the NMI line. The interrupt is
+// serviced after the current instruction regardless of the I flag, as
+// long as the edge arrives before the instruction's last cycle.
+func (cpu *CPU) TriggerNMI() {
+	cpu.nmiPending = true
+}
+
+// SetIRQ asserts or releases the IRQ line on behalf of a source. IRQ is
+// level triggered, so it is serviced after every instruction for as
+// long as it is asserted and the I flag is clear.
+func (cpu *CPU) SetIRQ(source IRQSource, asserted bool) {
+	if asserted {
+		cpu.irqLine |= source
+	} else {
+		cpu.irqLine &^= source
+	}
+}
+
+// Stall halts the CPU for a number of cycles, which is how DMA takes
+// over the bus. The CPU halts on its next read, so a stall asked for part
+// way through an instruction starts there. Between instructions each
+// Exec or Tick while stalled spends one cycle.
+func (cpu *CPU) Stall(cycles uint) {
+	cpu.stall += cycles
+}
+
+// stallCycle is one cycle of a stall. A halted CPU keeps repeating the
+// read it was about to do, unless DMA takes the bus for that cycle.
+func (cpu *CPU) stallCycle(address uint16) {
+	cpu.stall--
+	cpu.beginCycle()
+	if cpu.DMA == nil || !cpu.DMA() {
+		cpu.Bus.Read(address)
+	}
+	cpu.endCycle()
+}
+
+// Reset runs the 6502 reset sequence. It is the interrupt sequence with
+// the bus held in read mode, so the stack pointer still moves down by
+// three but nothing is written to the stack.
+func (cpu *CPU) Reset() {
+	cpu.stopTicking()
+	cpu.read(cpu.PC)
+	cpu.read(cpu.PC)
+	for i := 0; i < 3; i++ {
+		cpu.read(0x100 | uint16(cpu.SP))
+		cpu.SP -= 1
+	}
+
+	cpu.IFlag = true
+	cpu.Halted = false
+	cpu.clearNMI()
+	cpu.irqPoll = false
+	cpu.irqService = false
+	cpu.PC = cpu.read16(ResetVector)
+}
+
+// PowerOn puts the registers into their power-up state and then runs
+// the reset sequence.
+func (cpu *CPU) PowerOn() {
+	cpu.A = 0x00
+	cpu.X = 0x00
+	cpu.Y = 0x00
+	cpu.SP = 0x00
+	cpu.Cycles = 0
+	cpu.stall = 0
+	cpu.irqLine = 0
+	cpu.SetStatus(0x20)
+	cpu.Reset()
+}
+
+// interrupt runs the hardware interrupt sequence. The opcode fetch is
+// repeated and thrown away, then the return address and status flags
+// are pushed like BRK does. The B flag is clear in the pushed status,
+// which is how an interrupt handler tells an interrupt apart from BRK.
+func (cpu *CPU) interrupt() {
+	cpu.read(cpu.PC)
+	cpu.read(cpu.PC)
+	cpu.interruptSequence(cpu.Status()&^0x10 | 0x20)
+}
+
+// interruptSequence is shared by BRK and the hardware interrupts. An NMI
+// that arrives before the vector is fetched hijacks the sequence, so a
+// BRK or IRQ can end up jumping through the NMI vector.
+func (cpu *CPU) interruptSequence(status byte) {
+	cpu.stackPush16(cpu.PC)
+	cpu.stackPush(status)
+	cpu.IFlag = true
+
+	vector := IRQVector
+	if cpu.nmiPending {
+		cpu.clearNMI()
+		vector = NMIVector
+	}
+
+	cpu.PC = cpu.read16(vector)
+	cpu.irqService = false
+}
+
+func (cpu *CPU) clearNMI() {
+	cpu.nmiPending = false
+	cpu.nmiPoll = false
+	cpu.nmiService = false
+}
+
+// Every bus access takes exactly one cycle. The 6502 never leaves the
+// bus idle, cycles spent on internal work perform a read whose result is
+// thrown away, and these dummy reads are done here too because devices
+// like the PPU registers react to them.
+func (cpu *CPU) read(address uint16) byte {
+	// DMA can only halt the CPU on a read cycle
+	for cpu.stall > 0 {
+		cpu.stallCycle(address)
+	}
+
+	cpu.beginCycle()
+	value := cpu.Bus.Read(address)
+	cpu.endCycle()
+
+	return value
+}
+
+func (cpu *CPU) write(address uint16, value byte) {
+	cpu.beginCycle()
+	cpu.Bus.Write(address, value)
+	cpu.endCycle()
+}
+
+func (cpu *CPU) beginCycle() {
+	cpu.Cycles += 1
+	if cpu.OnCycle != nil {
+		cpu.OnCycle()
+	}
+}
+
+func (cpu *CPU) endCycle() {
+	cpu.nmiService, cpu.nmiPoll = cpu.nmiPoll, cpu.nmiPending
+	cpu.irqService, cpu.irqPoll = cpu.irqPoll, cpu.irqLine != 0 && !cpu.IFlag
+
+	if cpu.yield != nil {
+		cpu.yield()
+	}
+}
+
+func (cpu *CPU) read16(address uint16) uint16 {
+	lo := uint16(cpu.read(address))
+	hi := uint16(cpu.read(address + 1))
+	return hi<<8 | lo
+}
+
+// fetch reads the byte at PC and moves PC past it
+func (cpu *CPU) fetch() byte {
+	value := cpu.read(cpu.PC)
+	cpu.PC += 1
+
+	return value
+}
+
+func (cpu *CPU) fetch16() uint16 {
+	lo := uint16(cpu.fetch())
+	hi := uint16(cpu.fetch())
+	return hi<<8 | lo
+}
+
+func (cpu *CPU) setZeroFlag(n byte) {
+	// set zero flag if input is zero
+	cpu.ZFlag = n == 0
+}
+
+func (cpu *CPU) setNegativeFlag(n byte) {
+	// Set negative flag if input bit 7 is 1
+	cpu.NFlag = n&0x80 > 0
+}
+
+func (cpu *CPU) setZeroAndNegativeFlags(n byte) {
+	cpu.setZeroFlag(n)
+	cpu.setNegativeFlag(n)
+}
+func (cpu *CPU) flagToInt(flag bool) uint8 {
+	if flag {
+		return 1
+	} else {
+		return 0
+	}
+}
+
+func (cpu *CPU) intToFlag(n uint8) bool {
+	return n != 0
+}
+
+// Status packs the flags into the P register's byte layout, NV-BDIZC.
+func (cpu *CPU) Status() byte {
+	return (cpu.flagToInt(cpu.NFlag) << 7) |
+		(cpu.flagToInt(cpu.VFlag) << 6) |
+		(cpu.flagToInt(cpu.UFlag) << 5) |
+		(cpu.flagToInt(cpu.BFlag) << 4) |
+		(cpu.flagToInt(cpu.DFlag) << 3) |
+		(cpu.flagToInt(cpu.IFlag) << 2) |
+		(cpu.flagToInt(cpu.ZFlag) << 1) |
+		(cpu.flagToInt(cpu.CFlag) << 0)
+}
+
+// SetStatus sets the flags from a byte in the P register's layout.
+func (cpu *CPU) SetStatus(flags byte) {
+	cpu.NFlag = cpu.intToFlag(flags & 0x80)
+	cpu.VFlag = cpu.intToFlag(flags & 0x40)
+	cpu.UFlag = cpu.intToFlag(flags & 0x20)
+	cpu.BFlag = cpu.intToFlag(flags & 0x10)
+	cpu.DFlag = cpu.intToFlag(flags & 0x08)
+	cpu.IFlag = cpu.intToFlag(flags & 0x04)
+	cpu.ZFlag = cpu.intToFlag(flags & 0x02)
+	cpu.CFlag = cpu.intToFlag(flags & 0x01)
+}
+
+func (cpu *CPU) stackPush16(value uint16) {
+	cpu.stackPush(byte(value >> 8)) // high byte first
+	cpu.stackPush(byte(value))      // then the low byte
+}
+
+func (cpu *CPU) stackPop16() uint16 {
+	lo := cpu.stackPop()
+	hi := cpu.stackPop()
+	return uint16(hi)<<8 | uint16(lo)
+
+}
+
+func (cpu *CPU) stackPush(value byte) {
+	cpu.write(0x100|uint16(cpu.SP), value)
+	cpu.SP -= 1
+}
+
+func (cpu *CPU) stackPop() byte {
+	cpu.SP += 1
+	return cpu.read(0x100 | uint16(cpu.SP))
+}
+
+// context fetches the operand of an instruction and works out the
+// address it refers to, performing the same bus accesses as hardware
+// along the way. PC is left pointing at the next instruction.
+func context(cpu *CPU, instruction Instruction) *instructionContext {
+	var address uint16
+	var pageCrossed = false
+	var mode = instruction.AddressingMode
+
+	switch mode {
+	case Immediate:
+		address = cpu.PC
+		cpu.PC += 1
+	case Accumulator, Implied:
+		// The byte after the opcode is always read and thrown away.
+		// BRK skips over it, so it is a two byte instruction.
+		cpu.read(cpu.PC)
+		cpu.PC += instruction.Bytes - 1
+	case ZeroPage:
+		address = uint16(cpu.fetch())
+	case ZeroPageX:
+		address = cpu.zeroPageIndexed(cpu.X)
+	case ZeroPageY:
+		address = cpu.zeroPageIndexed(cpu.Y)
+	case Absolute:
+		address = uint16(cpu.fetch())
+		// JSR fetches the high byte of its target after pushing the
+		// return address, see JSR
+		if instruction.Opcode != 0x20 {
+			address |= uint16(cpu.fetch()) << 8
+		}
+	case AbsoluteX:
+		address, pageCrossed = cpu.indexed(cpu.fetch16(), cpu.X, instruction)
+	case AbsoluteY:
+		address, pageCrossed = cpu.indexed(cpu.fetch16(), cpu.Y, instruction)
+	case IndexedIndirect:
+		zeroPageAddress := cpu.fetch()
+		cpu.read(uint16(zeroPageAddress)) // read while X is added
+		intermediateAddress := zeroPageAddress + cpu.X
+		lo := cpu.read(uint16(intermediateAddress))
+		hi := cpu.read(uint16(intermediateAddress + 1))
+		address = uint16(hi)<<8 | uint16(lo)
+	case IndirectIndexed:
+		zeroPageAddress := cpu.fetch()
+		lo := cpu.read(uint16(zeroPageAddress))
+		hi := cpu.read(uint16(zeroPageAddress + 1))
+		intermediateAddress := uint16(hi)<<8 | uint16(lo)
+		address, pageCrossed = cpu.indexed(intermediateAddress, cpu.Y, instruction)
+	case Relative:
+		// convert operand to signed offset
+		relativeAddress := int8(cpu.fetch())
+		// convert signed offset to 16bit unsigned. If we don't convert
+		// to a signed int8 first, we will not preserve the sign bits
+		// meaning that addition will not correctly handle negative
+		// operands
+		address = cpu.PC + uint16(relativeAddress)
+		pageCrossed = (address & 0xFF00) != (cpu.PC & 0xFF00)
+	case Indirect:
+		// NOTE: This addressing mode implements a hardware bug.
+		// The operand of the instruction is an intermediate address.
+		// This intermediate address contains the low byte of the JMP target address.
+		// The high byte of the JMP target address is stored in the following address
+		// (intermediate address + 1). However If the intermediate address falls on a
+		// memory page boundary (i.e. the first byte is on $xxFF, where xx is any number),
+		// it does not correctly look at the next page when reading the high byte of the
+		// JMP target address. A concrete example: If the instruction has the operand $10FF,
+		// it will read the LSB of the JMP address from $10FF, but will read the MSB of the JMP
+		// address from $1000 instead of $1100.
+		intermediateLo := cpu.fetch16()
+		intermediateHi := (intermediateLo & 0xFF00) | ((intermediateLo + 1) & 0x00FF) // this is the bug
+		lo := uint16(cpu.read(intermediateLo))
+		hi := uint16(cpu.read(intermediateHi))
+		address = hi<<8 | lo
+	}
+
+	return &instructionContext{
+		PageCrossed:    pageCrossed,
+		Address:        address,
+		AddressingMode: mode,
+	}
+}
+
+// zeroPageIndexed adds an index register to a zero page address. The base
+// address is read while the index is being added, and the result wraps
+// around within the zero page.
+func (cpu *CPU) zeroPageIndexed(index byte) uint16 {
+	zeroPageAddress := cpu.fetch()
+	cpu.read(uint16(zeroPageAddress))
+
+	return uint16(zeroPageAddress + index)
+}
+
+// indexed adds an index register to a 16 bit base address. The CPU adds
+// the index to the low byte first and reads from that address while it
+// fixes up the high byte, so the read is from the wrong page when a page
+// boundary is crossed. Read instructions skip this extra cycle when no
+// page is crossed, writes and read-modify-write instructions never do.
+func (cpu *CPU) indexed(base uint16, index byte, instruction Instruction) (uint16, bool) {
+	address := base + uint16(index)
+	pageCrossed := (address & 0xFF00) != (base & 0xFF00)
+
+	if pageCrossed || !instruction.AddCycleOnPageCross {
+		cpu.read(base&0xFF00 | address&0x00FF)
+	}
+
+	return address, pageCrossed
+}
+
+func NewCPU() *CPU {
+	return &CPU{
+		PC:     0x00,
+		SP:     0xFD,
+		A:      0x00,
+		X:      0x00,
+		Y:      0x00,
+		F:      0x00,
+		Cycles: 0,
+		Bus:    &Memory{},
+	}
+}
@@ -1,6 +1,9 @@
-package main
+package cpu
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
 	"testing"
 )
 
@@ -10,91 +13,91 @@ type CpuTestHarness struct {
 }
 
 func (h *CpuTestHarness) SetupAccumulator() {
-	h.Cpu.Memory[0] = h.Opcode
+	h.Cpu.Bus.Write(0, h.Opcode)
 	h.Cpu.A = 0x08
 }
 
 func (h *CpuTestHarness) SetupZeroPage() {
-	h.Cpu.Memory[0] = h.Opcode
-	h.Cpu.Memory[1] = 0x17
-	h.Cpu.Memory[0x17] = 0x07
+	h.Cpu.Bus.Write(0, h.Opcode)
+	h.Cpu.Bus.Write(1, 0x17)
+	h.Cpu.Bus.Write(0x17, 0x07)
 }
 
 func (h *CpuTestHarness) SetupZeroPageX() {
 	h.Cpu.X = 0x0F
-	h.Cpu.Memory[0] = h.Opcode
-	h.Cpu.Memory[1] = 0x80
-	h.Cpu.Memory[0x8F] = 0x07
+	h.Cpu.Bus.Write(0, h.Opcode)
+	h.Cpu.Bus.Write(1, 0x80)
+	h.Cpu.Bus.Write(0x8F, 0x07)
 }
 
 func (h *CpuTestHarness) SetupZeroPageY() {
 	h.Cpu.Y = 0x0F
-	h.Cpu.Memory[0] = h.Opcode
-	h.Cpu.Memory[1] = 0x80
-	h.Cpu.Memory[0x8F] = 0x07
+	h.Cpu.Bus.Write(0, h.Opcode)
+	h.Cpu.Bus.Write(1, 0x80)
+	h.Cpu.Bus.Write(0x8F, 0x07)
 }
 
 func (h *CpuTestHarness) SetupAbsolute() {
-	h.Cpu.Memory[0] = h.Opcode
-	h.Cpu.Memory[1] = 0x80
-	h.Cpu.Memory[2] = 0x80
-	h.Cpu.Memory[0x8080] = 0x07
+	h.Cpu.Bus.Write(0, h.Opcode)
+	h.Cpu.Bus.Write(1, 0x80)
+	h.Cpu.Bus.Write(2, 0x80)
+	h.Cpu.Bus.Write(0x8080, 0x07)
 }
 
 func (h *CpuTestHarness) SetupAbsoluteX() {
 	h.Cpu.X = 0x01
-	h.Cpu.Memory[0] = h.Opcode
-	h.Cpu.Memory[1] = 0x80
-	h.Cpu.Memory[2] = 0xFF
-	h.Cpu.Memory[0xFF81] = 0x07
+	h.Cpu.Bus.Write(0, h.Opcode)
+	h.Cpu.Bus.Write(1, 0x80)
+	h.Cpu.Bus.Write(2, 0xFF)
+	h.Cpu.Bus.Write(0xFF81, 0x07)
 }
 
 func (h *CpuTestHarness) SetupAbsoluteXPageCross() {
 	h.Cpu.X = 0x01
-	h.Cpu.Memory[0] = h.Opcode
-	h.Cpu.Memory[1] = 0xFF
-	h.Cpu.Memory[2] = 0xF0
-	h.Cpu.Memory[0xF100] = 0x07
+	h.Cpu.Bus.Write(0, h.Opcode)
+	h.Cpu.Bus.Write(1, 0xFF)
+	h.Cpu.Bus.Write(2, 0xF0)
+	h.Cpu.Bus.Write(0xF100, 0x07)
 }
 
 func (h *CpuTestHarness) SetupAbsoluteY() {
 	h.Cpu.Y = 0x01
-	h.Cpu.Memory[0] = h.Opcode
-	h.Cpu.Memory[1] = 0x80
-	h.Cpu.Memory[2] = 0xFF
-	h.Cpu.Memory[0xFF81] = 0x07
+	h.Cpu.Bus.Write(0, h.Opcode)
+	h.Cpu.Bus.Write(1, 0x80)
+	h.Cpu.Bus.Write(2, 0xFF)
+	h.Cpu.Bus.Write(0xFF81, 0x07)
 }
 
 func (h *CpuTestHarness) SetupAbsoluteYPageCross() {
 	h.Cpu.Y = 0x01
-	h.Cpu.Memory[0] = h.Opcode
-	h.Cpu.Memory[1] = 0xFF
-	h.Cpu.Memory[2] = 0xF0
-	h.Cpu.Memory[0xF100] = 0x07
+	h.Cpu.Bus.Write(0, h.Opcode)
+	h.Cpu.Bus.Write(1, 0xFF)
+	h.Cpu.Bus.Write(2, 0xF0)
+	h.Cpu.Bus.Write(0xF100, 0x07)
 }
 
 func (h *CpuTestHarness) SetupIndexedIndirect() {
 	h.Cpu.X = 0x01
-	h.Cpu.Memory[0] = h.Opcode
-	h.Cpu.Memory[1] = 0xFE
-	h.Cpu.Memory[9] = 0x07
-	h.Cpu.Memory[0xFF] = 0x09
+	h.Cpu.Bus.Write(0, h.Opcode)
+	h.Cpu.Bus.Write(1, 0xFE)
+	h.Cpu.Bus.Write(9, 0x07)
+	h.Cpu.Bus.Write(0xFF, 0x09)
 }
 
 func (h *CpuTestHarness) SetupIndirectIndexed() {
 	h.Cpu.Y = 0x01
-	h.Cpu.Memory[0] = h.Opcode
-	h.Cpu.Memory[1] = 0x02
-	h.Cpu.Memory[2] = 0x05
-	h.Cpu.Memory[6] = 0x07
+	h.Cpu.Bus.Write(0, h.Opcode)
+	h.Cpu.Bus.Write(1, 0x02)
+	h.Cpu.Bus.Write(2, 0x05)
+	h.Cpu.Bus.Write(6, 0x07)
 }
 
 func (h *CpuTestHarness) SetupIndirectIndexedPageCross() {
 	h.Cpu.Y = 0x01
-	h.Cpu.Memory[0] = h.Opcode
-	h.Cpu.Memory[1] = 0x02
-	h.Cpu.Memory[2] = 0xFF
-	h.Cpu.Memory[0x100] = 0x07
+	h.Cpu.Bus.Write(0, h.Opcode)
+	h.Cpu.Bus.Write(1, 0x02)
+	h.Cpu.Bus.Write(2, 0xFF)
+	h.Cpu.Bus.Write(0x100, 0x07)
 }
 
 func (h *CpuTestHarness) Run() {
@@ -105,6 +108,25 @@ func NewCpuTestHarness() *CpuTestHarness {
 	return &CpuTestHarness{Cpu: NewCPU()}
 }
 
+// The stack helpers below set up and inspect the stack directly on the
+// bus, so unlike the CPU's own stack operations they take no cycles
+
+func pushStack(cpu *CPU, value byte) {
+	cpu.Bus.Write(0x100|uint16(cpu.SP), value)
+	cpu.SP -= 1
+}
+
+func popStack(cpu *CPU) byte {
+	cpu.SP += 1
+	return cpu.Bus.Read(0x100 | uint16(cpu.SP))
+}
+
+func popStack16(cpu *CPU) uint16 {
+	lo := uint16(popStack(cpu))
+	hi := uint16(popStack(cpu))
+	return hi<<8 | lo
+}
+
 func TestNewCPUSetsSP(t *testing.T) {
 	cpu := NewCPU()
 	if cpu.SP != 0xFF {
@@ -116,7 +138,7 @@ func TestStackPush(t *testing.T) {
 	cpu := NewCPU()
 	cpu.stackPush(0x17)
 
-	if cpu.Memory[0x1FF] != 0x17 {
+	if cpu.Bus.Read(0x1FF) != 0x17 {
 		t.Error("did not add expected value to top of stack")
 	}
 
@@ -128,7 +150,7 @@ func TestStackPush(t *testing.T) {
 func TestStackPop(t *testing.T) {
 	cpu := NewCPU()
 	cpu.SP = 0xFD
-	cpu.Memory[0x1FE] = 0x50
+	cpu.Bus.Write(0x1FE, 0x50)
 	result := cpu.stackPop()
 
 	if result != 0x50 {
@@ -150,7 +172,7 @@ func TestFlagsToByte(t *testing.T) {
 	cpu.IFlag = true
 	cpu.ZFlag = false
 	cpu.CFlag = true
-	result := cpu.flagsToByte()
+	result := cpu.Status()
 
 	if result != 0x55 {
 		t.Error("did not correctly set bit flags, got", result)
@@ -160,8 +182,8 @@ func TestFlagsToByte(t *testing.T) {
 func TestANDImmediate(t *testing.T) {
 	cpu := NewCPU()
 	cpu.A = 0x01
-	cpu.Memory[0] = 0x29
-	cpu.Memory[1] = 0x01
+	cpu.Bus.Write(0, 0x29)
+	cpu.Bus.Write(1, 0x01)
 	cpu.Exec()
 
 	if cpu.A != 0x01 {
@@ -188,8 +210,8 @@ func TestANDImmediate(t *testing.T) {
 func TestANDImmediateZero(t *testing.T) {
 	cpu := NewCPU()
 	cpu.A = 0x01
-	cpu.Memory[0] = 0x29
-	cpu.Memory[1] = 0x00
+	cpu.Bus.Write(0, 0x29)
+	cpu.Bus.Write(1, 0x00)
 	cpu.Exec()
 
 	if cpu.A != 0x00 {
@@ -212,9 +234,9 @@ func TestANDImmediateZero(t *testing.T) {
 func TestANDZeroPage(t *testing.T) {
 	cpu := NewCPU()
 	cpu.A = 0x02
-	cpu.Memory[0] = 0x25
-	cpu.Memory[1] = 0x09
-	cpu.Memory[9] = 0x02
+	cpu.Bus.Write(0, 0x25)
+	cpu.Bus.Write(1, 0x09)
+	cpu.Bus.Write(9, 0x02)
 	cpu.Exec()
 
 	if cpu.A != 0x02 {
@@ -242,9 +264,9 @@ func TestANDZeroPageX(t *testing.T) {
 	cpu := NewCPU()
 	cpu.A = 0x03
 	cpu.X = 0x01
-	cpu.Memory[0] = 0x35
-	cpu.Memory[1] = 0x08
-	cpu.Memory[9] = 0x03
+	cpu.Bus.Write(0, 0x35)
+	cpu.Bus.Write(1, 0x08)
+	cpu.Bus.Write(9, 0x03)
 	cpu.Exec()
 
 	if cpu.A != 0x03 {
@@ -272,9 +294,9 @@ func TestANDZeroPageXWithOverflow(t *testing.T) {
 	cpu := NewCPU()
 	cpu.A = 0x03
 	cpu.X = 0xFF // should overflow the result and wraparound
-	cpu.Memory[0] = 0x35
-	cpu.Memory[1] = 0x0A
-	cpu.Memory[9] = 0x03
+	cpu.Bus.Write(0, 0x35)
+	cpu.Bus.Write(1, 0x0A)
+	cpu.Bus.Write(9, 0x03)
 	cpu.Exec()
 
 	if cpu.A != 0x03 {
@@ -301,10 +323,10 @@ func TestANDZeroPageXWithOverflow(t *testing.T) {
 func TestANDAbsolute(t *testing.T) {
 	cpu := NewCPU()
 	cpu.A = 0x03
-	cpu.Memory[0] = 0x2D
-	cpu.Memory[1] = 0x01
-	cpu.Memory[2] = 0xFF
-	cpu.Memory[0xFF01] = 0x03
+	cpu.Bus.Write(0, 0x2D)
+	cpu.Bus.Write(1, 0x01)
+	cpu.Bus.Write(2, 0xFF)
+	cpu.Bus.Write(0xFF01, 0x03)
 	cpu.Exec()
 
 	if cpu.A != 0x03 {
@@ -332,10 +354,10 @@ func TestANDAbsoluteX(t *testing.T) {
 	cpu := NewCPU()
 	cpu.A = 0x04
 	cpu.X = 0x01
-	cpu.Memory[0] = 0x3D
-	cpu.Memory[1] = 0x01
-	cpu.Memory[2] = 0xFF
-	cpu.Memory[0xFF02] = 0x04
+	cpu.Bus.Write(0, 0x3D)
+	cpu.Bus.Write(1, 0x01)
+	cpu.Bus.Write(2, 0xFF)
+	cpu.Bus.Write(0xFF02, 0x04)
 	cpu.Exec()
 
 	if cpu.A != 0x04 {
@@ -355,10 +377,10 @@ func TestANDAbsoluteXWithPageCross(t *testing.T) {
 	cpu := NewCPU()
 	cpu.A = 0x04
 	cpu.X = 0x01
-	cpu.Memory[0] = 0x3D
-	cpu.Memory[1] = 0xFF
-	cpu.Memory[2] = 0x00
-	cpu.Memory[0x0100] = 0x04
+	cpu.Bus.Write(0, 0x3D)
+	cpu.Bus.Write(1, 0xFF)
+	cpu.Bus.Write(2, 0x00)
+	cpu.Bus.Write(0x0100, 0x04)
 	cpu.Exec()
 
 	if cpu.A != 0x04 {
@@ -374,10 +396,10 @@ func TestANDAbsoluteY(t *testing.T) {
 	cpu := NewCPU()
 	cpu.A = 0x04
 	cpu.Y = 0x01
-	cpu.Memory[0] = 0x39
-	cpu.Memory[1] = 0x01
-	cpu.Memory[2] = 0xFF
-	cpu.Memory[0xFF02] = 0x04
+	cpu.Bus.Write(0, 0x39)
+	cpu.Bus.Write(1, 0x01)
+	cpu.Bus.Write(2, 0xFF)
+	cpu.Bus.Write(0xFF02, 0x04)
 	cpu.Exec()
 
 	if cpu.A != 0x04 {
@@ -397,10 +419,10 @@ func TestANDAbsoluteYWithPageCross(t *testing.T) {
 	cpu := NewCPU()
 	cpu.A = 0x05
 	cpu.Y = 0x01
-	cpu.Memory[0] = 0x39
-	cpu.Memory[1] = 0xFF
-	cpu.Memory[2] = 0x00
-	cpu.Memory[0x0100] = 0x05
+	cpu.Bus.Write(0, 0x39)
+	cpu.Bus.Write(1, 0xFF)
+	cpu.Bus.Write(2, 0x00)
+	cpu.Bus.Write(0x0100, 0x05)
 	cpu.Exec()
 
 	if cpu.A != 0x05 {
@@ -416,10 +438,10 @@ func TestANDAIndexedIndirect(t *testing.T) {
 	cpu := NewCPU()
 	cpu.A = 0x05
 	cpu.X = 0x01
-	cpu.Memory[0] = 0x21
-	cpu.Memory[1] = 0xFE
-	cpu.Memory[9] = 0x05
-	cpu.Memory[0xFF] = 0x09
+	cpu.Bus.Write(0, 0x21)
+	cpu.Bus.Write(1, 0xFE)
+	cpu.Bus.Write(9, 0x05)
+	cpu.Bus.Write(0xFF, 0x09)
 	cpu.Exec()
 
 	if cpu.A != 0x05 {
@@ -439,10 +461,10 @@ func TestANDIndexedIndirectWithOverflow(t *testing.T) {
 	cpu := NewCPU()
 	cpu.A = 0x05
 	cpu.X = 0x0B
-	cpu.Memory[0] = 0x21
-	cpu.Memory[1] = 0xFF
-	cpu.Memory[9] = 0x05
-	cpu.Memory[0x0A] = 0x09
+	cpu.Bus.Write(0, 0x21)
+	cpu.Bus.Write(1, 0xFF)
+	cpu.Bus.Write(9, 0x05)
+	cpu.Bus.Write(0x0A, 0x09)
 	cpu.Exec()
 
 	if cpu.A != 0x05 {
@@ -458,10 +480,10 @@ func TestANDIndirectIndexed(t *testing.T) {
 	cpu := NewCPU()
 	cpu.A = 0x05
 	cpu.Y = 0x01
-	cpu.Memory[0] = 0x31
-	cpu.Memory[1] = 0x02
-	cpu.Memory[2] = 0x05
-	cpu.Memory[6] = 0x05
+	cpu.Bus.Write(0, 0x31)
+	cpu.Bus.Write(1, 0x02)
+	cpu.Bus.Write(2, 0x05)
+	cpu.Bus.Write(6, 0x05)
 	cpu.Exec()
 
 	if cpu.A != 0x05 {
@@ -481,10 +503,10 @@ func TestANDAIndirectIndexedWithPageCross(t *testing.T) {
 	cpu := NewCPU()
 	cpu.A = 0x05
 	cpu.Y = 0x01
-	cpu.Memory[0] = 0x31
-	cpu.Memory[1] = 0x02
-	cpu.Memory[2] = 0xFF
-	cpu.Memory[0x100] = 0x05
+	cpu.Bus.Write(0, 0x31)
+	cpu.Bus.Write(1, 0x02)
+	cpu.Bus.Write(2, 0xFF)
+	cpu.Bus.Write(0x100, 0x05)
 	cpu.Exec()
 
 	if cpu.A != 0x05 {
@@ -499,8 +521,8 @@ func TestANDAIndirectIndexedWithPageCross(t *testing.T) {
 func TestADCImmediate(t *testing.T) {
 	cpu := NewCPU()
 	cpu.A = 0x01
-	cpu.Memory[0] = 0x69
-	cpu.Memory[1] = 0x80
+	cpu.Bus.Write(0, 0x69)
+	cpu.Bus.Write(1, 0x80)
 	cpu.Exec()
 
 	if cpu.A != 0x81 {
@@ -536,8 +558,8 @@ func TestADCImmediateWithCarryIn(t *testing.T) {
 	cpu := NewCPU()
 	cpu.A = 0x00
 	cpu.CFlag = true
-	cpu.Memory[0] = 0x69
-	cpu.Memory[1] = 0x00
+	cpu.Bus.Write(0, 0x69)
+	cpu.Bus.Write(1, 0x00)
 	cpu.Exec()
 
 	if cpu.A != 0x01 {
@@ -552,8 +574,8 @@ func TestADCImmediateWithCarryIn(t *testing.T) {
 func TestADCImmediateWithCarryOut(t *testing.T) {
 	cpu := NewCPU()
 	cpu.A = 0xFF
-	cpu.Memory[0] = 0x69
-	cpu.Memory[1] = 0x01
+	cpu.Bus.Write(0, 0x69)
+	cpu.Bus.Write(1, 0x01)
 	cpu.Exec()
 
 	if cpu.A != 0x00 {
@@ -577,8 +599,8 @@ func TestADCImmediateWithCarryOut(t *testing.T) {
 func TestADCImmediateWithOverflow(t *testing.T) {
 	cpu := NewCPU()
 	cpu.A = 0x7F
-	cpu.Memory[0] = 0x69
-	cpu.Memory[1] = 0x01
+	cpu.Bus.Write(0, 0x69)
+	cpu.Bus.Write(1, 0x01)
 	cpu.Exec()
 
 	if cpu.A != 0x80 {
@@ -593,9 +615,9 @@ func TestADCImmediateWithOverflow(t *testing.T) {
 func TestADCZeroPage(t *testing.T) {
 	cpu := NewCPU()
 	cpu.A = 0x01
-	cpu.Memory[0] = 0x65
-	cpu.Memory[1] = 0x09
-	cpu.Memory[9] = 0x80
+	cpu.Bus.Write(0, 0x65)
+	cpu.Bus.Write(1, 0x09)
+	cpu.Bus.Write(9, 0x80)
 	cpu.Exec()
 
 	if cpu.A != 0x81 {
@@ -615,9 +637,9 @@ func TestADCZeroPageX(t *testing.T) {
 	cpu := NewCPU()
 	cpu.A = 0x01
 	cpu.X = 0x01
-	cpu.Memory[0] = 0x75
-	cpu.Memory[1] = 0x08
-	cpu.Memory[9] = 0x03
+	cpu.Bus.Write(0, 0x75)
+	cpu.Bus.Write(1, 0x08)
+	cpu.Bus.Write(9, 0x03)
 	cpu.Exec()
 
 	if cpu.A != 0x04 {
@@ -636,10 +658,10 @@ func TestADCZeroPageX(t *testing.T) {
 func TestADCAbsolute(t *testing.T) {
 	cpu := NewCPU()
 	cpu.A = 0x03
-	cpu.Memory[0] = 0x6D
-	cpu.Memory[1] = 0x01
-	cpu.Memory[2] = 0xFF
-	cpu.Memory[0xFF01] = 0x03
+	cpu.Bus.Write(0, 0x6D)
+	cpu.Bus.Write(1, 0x01)
+	cpu.Bus.Write(2, 0xFF)
+	cpu.Bus.Write(0xFF01, 0x03)
 	cpu.Exec()
 
 	if cpu.A != 0x06 {
@@ -659,10 +681,10 @@ func TestADCAbsoluteX(t *testing.T) {
 	cpu := NewCPU()
 	cpu.A = 0x01
 	cpu.X = 0x01
-	cpu.Memory[0] = 0x7D
-	cpu.Memory[1] = 0x01
-	cpu.Memory[2] = 0xFF
-	cpu.Memory[0xFF02] = 0x04
+	cpu.Bus.Write(0, 0x7D)
+	cpu.Bus.Write(1, 0x01)
+	cpu.Bus.Write(2, 0xFF)
+	cpu.Bus.Write(0xFF02, 0x04)
 	cpu.Exec()
 
 	if cpu.A != 0x05 {
@@ -682,10 +704,10 @@ func TestADCAbsoluteXWithPageCross(t *testing.T) {
 	cpu := NewCPU()
 	cpu.A = 0x04
 	cpu.X = 0x01
-	cpu.Memory[0] = 0x7D
-	cpu.Memory[1] = 0xFF
-	cpu.Memory[2] = 0x00
-	cpu.Memory[0x0100] = 0x04
+	cpu.Bus.Write(0, 0x7D)
+	cpu.Bus.Write(1, 0xFF)
+	cpu.Bus.Write(2, 0x00)
+	cpu.Bus.Write(0x0100, 0x04)
 	cpu.Exec()
 
 	if cpu.A != 0x08 {
@@ -701,10 +723,10 @@ func TestADCAbsoluteY(t *testing.T) {
 	cpu := NewCPU()
 	cpu.A = 0x04
 	cpu.Y = 0x01
-	cpu.Memory[0] = 0x79
-	cpu.Memory[1] = 0xFE
-	cpu.Memory[2] = 0x00
-	cpu.Memory[0xFF] = 0x04
+	cpu.Bus.Write(0, 0x79)
+	cpu.Bus.Write(1, 0xFE)
+	cpu.Bus.Write(2, 0x00)
+	cpu.Bus.Write(0xFF, 0x04)
 	cpu.Exec()
 
 	if cpu.A != 0x08 {
@@ -724,10 +746,10 @@ func TestADCAbsoluteYWithPageCross(t *testing.T) {
 	cpu := NewCPU()
 	cpu.A = 0x04
 	cpu.Y = 0x01
-	cpu.Memory[0] = 0x79
-	cpu.Memory[1] = 0xFF
-	cpu.Memory[2] = 0x00
-	cpu.Memory[0x100] = 0x04
+	cpu.Bus.Write(0, 0x79)
+	cpu.Bus.Write(1, 0xFF)
+	cpu.Bus.Write(2, 0x00)
+	cpu.Bus.Write(0x100, 0x04)
 	cpu.Exec()
 
 	if cpu.A != 0x08 {
@@ -747,10 +769,10 @@ func TestADCIndexedIndirect(t *testing.T) {
 	cpu := NewCPU()
 	cpu.A = 0x05
 	cpu.X = 0x01
-	cpu.Memory[0] = 0x61
-	cpu.Memory[1] = 0xFE
-	cpu.Memory[9] = 0x05
-	cpu.Memory[0xFF] = 0x09
+	cpu.Bus.Write(0, 0x61)
+	cpu.Bus.Write(1, 0xFE)
+	cpu.Bus.Write(9, 0x05)
+	cpu.Bus.Write(0xFF, 0x09)
 	cpu.Exec()
 
 	if cpu.A != 0x0A {
@@ -770,10 +792,10 @@ func TestADCIndirectIndexed(t *testing.T) {
 	cpu := NewCPU()
 	cpu.A = 0x05
 	cpu.Y = 0x01
-	cpu.Memory[0] = 0x71
-	cpu.Memory[1] = 0x02
-	cpu.Memory[2] = 0x05
-	cpu.Memory[6] = 0x0A
+	cpu.Bus.Write(0, 0x71)
+	cpu.Bus.Write(1, 0x02)
+	cpu.Bus.Write(2, 0x05)
+	cpu.Bus.Write(6, 0x0A)
 	cpu.Exec()
 
 	if cpu.A != 0x0F {
@@ -793,10 +815,10 @@ func TestADCIndirectIndexedWithPageCross(t *testing.T) {
 	cpu := NewCPU()
 	cpu.A = 0x05
 	cpu.Y = 0x01
-	cpu.Memory[0] = 0x71
-	cpu.Memory[1] = 0x02
-	cpu.Memory[2] = 0xFF
-	cpu.Memory[0x100] = 0x05
+	cpu.Bus.Write(0, 0x71)
+	cpu.Bus.Write(1, 0x02)
+	cpu.Bus.Write(2, 0xFF)
+	cpu.Bus.Write(0x100, 0x05)
 	cpu.Exec()
 
 	if cpu.Cycles != 6 {
@@ -807,7 +829,7 @@ func TestADCIndirectIndexedWithPageCross(t *testing.T) {
 func TestASLAccumulator(t *testing.T) {
 	cpu := NewCPU()
 	cpu.A = 0x04
-	cpu.Memory[0] = 0x0A
+	cpu.Bus.Write(0, 0x0A)
 	cpu.Exec()
 
 	if cpu.A != 0x08 {
@@ -838,7 +860,7 @@ func TestASLAccumulator(t *testing.T) {
 func TestASLAccumulatorWithCarry(t *testing.T) {
 	cpu := NewCPU()
 	cpu.A = 0xC0
-	cpu.Memory[0] = 0x0A
+	cpu.Bus.Write(0, 0x0A)
 	cpu.Exec()
 
 	if cpu.A != 0x80 {
@@ -857,9 +879,9 @@ func TestASLAccumulatorWithCarry(t *testing.T) {
 
 func TestASLZeroPage(t *testing.T) {
 	cpu := NewCPU()
-	cpu.Memory[0] = 0x06
-	cpu.Memory[1] = 0x09
-	cpu.Memory[9] = 0x02
+	cpu.Bus.Write(0, 0x06)
+	cpu.Bus.Write(1, 0x09)
+	cpu.Bus.Write(9, 0x02)
 	cpu.Exec()
 
 	if cpu.A != 0x04 {
@@ -878,9 +900,9 @@ func TestASLZeroPage(t *testing.T) {
 func TestASLZeroPageX(t *testing.T) {
 	cpu := NewCPU()
 	cpu.X = 0x01
-	cpu.Memory[0] = 0x16
-	cpu.Memory[1] = 0x08
-	cpu.Memory[9] = 0x04
+	cpu.Bus.Write(0, 0x16)
+	cpu.Bus.Write(1, 0x08)
+	cpu.Bus.Write(9, 0x04)
 	cpu.Exec()
 
 	if cpu.A != 0x08 {
@@ -898,10 +920,10 @@ func TestASLZeroPageX(t *testing.T) {
 
 func TestASLAbsolute(t *testing.T) {
 	cpu := NewCPU()
-	cpu.Memory[0] = 0x0E
-	cpu.Memory[1] = 0x01
-	cpu.Memory[2] = 0xFF
-	cpu.Memory[0xFF01] = 0x07
+	cpu.Bus.Write(0, 0x0E)
+	cpu.Bus.Write(1, 0x01)
+	cpu.Bus.Write(2, 0xFF)
+	cpu.Bus.Write(0xFF01, 0x07)
 	cpu.Exec()
 
 	if cpu.A != 0x0E {
@@ -920,10 +942,10 @@ func TestASLAbsolute(t *testing.T) {
 func TestASLAbsoluteX(t *testing.T) {
 	cpu := NewCPU()
 	cpu.X = 0x01
-	cpu.Memory[0] = 0x1E
-	cpu.Memory[1] = 0x01
-	cpu.Memory[2] = 0xFF
-	cpu.Memory[0xFF02] = 0x04
+	cpu.Bus.Write(0, 0x1E)
+	cpu.Bus.Write(1, 0x01)
+	cpu.Bus.Write(2, 0xFF)
+	cpu.Bus.Write(0xFF02, 0x04)
 	cpu.Exec()
 
 	if cpu.A != 0x08 {
@@ -942,8 +964,8 @@ func TestASLAbsoluteX(t *testing.T) {
 func TestBCC(t *testing.T) {
 	cpu := NewCPU()
 	cpu.CFlag = false
-	cpu.Memory[0] = 0x90
-	cpu.Memory[1] = 0x10
+	cpu.Bus.Write(0, 0x90)
+	cpu.Bus.Write(1, 0x10)
 	cpu.Exec()
 
 	if cpu.PC != 0x12 {
@@ -958,8 +980,8 @@ func TestBCC(t *testing.T) {
 func TestBCCNoBranch(t *testing.T) {
 	cpu := NewCPU()
 	cpu.CFlag = true
-	cpu.Memory[0] = 0x90
-	cpu.Memory[1] = 0x10
+	cpu.Bus.Write(0, 0x90)
+	cpu.Bus.Write(1, 0x10)
 	cpu.Exec()
 
 	if cpu.PC != 0x2 {
@@ -975,8 +997,8 @@ func TestBCCNegativeRelativeAddress(t *testing.T) {
 	cpu := NewCPU()
 	cpu.PC = 0x01
 	cpu.CFlag = false
-	cpu.Memory[1] = 0x90
-	cpu.Memory[2] = 0xFF // -1 in two's complement
+	cpu.Bus.Write(1, 0x90)
+	cpu.Bus.Write(2, 0xFF) // -1 in two's complement
 	cpu.Exec()
 
 	// the reason the expected value is 2 is that the offset specified in a branch instruction
@@ -996,11 +1018,11 @@ func TestBCCWithPageCross(t *testing.T) {
 	cpu := NewCPU()
 	cpu.CFlag = false
 	cpu.PC = 0xF1
-	cpu.Memory[0xF1] = 0x90
-	cpu.Memory[0xF2] = 0x0F
+	cpu.Bus.Write(0xF1, 0x90)
+	cpu.Bus.Write(0xF2, 0x0F)
 	cpu.Exec()
 
-	if cpu.Cycles != 5 {
+	if cpu.Cycles != 4 {
 		t.Error("did not correctly set cycles flag")
 	}
 }
@@ -1008,8 +1030,8 @@ func TestBCCWithPageCross(t *testing.T) {
 func TestBCS(t *testing.T) {
 	cpu := NewCPU()
 	cpu.CFlag = true
-	cpu.Memory[0] = 0xB0
-	cpu.Memory[1] = 0x10
+	cpu.Bus.Write(0, 0xB0)
+	cpu.Bus.Write(1, 0x10)
 	cpu.Exec()
 
 	if cpu.PC != 0x12 {
@@ -1024,8 +1046,8 @@ func TestBCS(t *testing.T) {
 func TestBCSNoBranch(t *testing.T) {
 	cpu := NewCPU()
 	cpu.CFlag = false
-	cpu.Memory[0] = 0xB0
-	cpu.Memory[1] = 0x10
+	cpu.Bus.Write(0, 0xB0)
+	cpu.Bus.Write(1, 0x10)
 	cpu.Exec()
 
 	if cpu.PC != 0x2 {
@@ -1041,8 +1063,8 @@ func TestBCSNegativeRelativeAddress(t *testing.T) {
 	cpu := NewCPU()
 	cpu.CFlag = true
 	cpu.PC = 0x10A
-	cpu.Memory[0x10A] = 0xB0
-	cpu.Memory[0x10B] = 0xF4 // -10
+	cpu.Bus.Write(0x10A, 0xB0)
+	cpu.Bus.Write(0x10B, 0xF4) // -10
 	cpu.Exec()
 
 	if cpu.PC != 0x100 {
@@ -1058,11 +1080,11 @@ func TestBCSWithPageCross(t *testing.T) {
 	cpu := NewCPU()
 	cpu.CFlag = true
 	cpu.PC = 0xF1
-	cpu.Memory[0xF1] = 0xB0
-	cpu.Memory[0xF2] = 0x0F
+	cpu.Bus.Write(0xF1, 0xB0)
+	cpu.Bus.Write(0xF2, 0x0F)
 	cpu.Exec()
 
-	if cpu.Cycles != 5 {
+	if cpu.Cycles != 4 {
 		t.Error("did not correctly set cycles flag")
 	}
 }
@@ -1070,8 +1092,8 @@ func TestBCSWithPageCross(t *testing.T) {
 func TestBEQ(t *testing.T) {
 	cpu := NewCPU()
 	cpu.ZFlag = true
-	cpu.Memory[0] = 0xF0
-	cpu.Memory[1] = 0x10
+	cpu.Bus.Write(0, 0xF0)
+	cpu.Bus.Write(1, 0x10)
 	cpu.Exec()
 
 	if cpu.PC != 0x12 {
@@ -1086,8 +1108,8 @@ func TestBEQ(t *testing.T) {
 func TestBEQNoBranch(t *testing.T) {
 	cpu := NewCPU()
 	cpu.ZFlag = false
-	cpu.Memory[0] = 0xF0
-	cpu.Memory[1] = 0x10
+	cpu.Bus.Write(0, 0xF0)
+	cpu.Bus.Write(1, 0x10)
 	cpu.Exec()
 
 	if cpu.PC != 0x2 {
@@ -1103,8 +1125,8 @@ func TestBEQWithNegativeRelativeAddress(t *testing.T) {
 	cpu := NewCPU()
 	cpu.ZFlag = true
 	cpu.PC = 0x10A
-	cpu.Memory[0x10A] = 0xF0
-	cpu.Memory[0x10B] = 0xF4 // -10
+	cpu.Bus.Write(0x10A, 0xF0)
+	cpu.Bus.Write(0x10B, 0xF4) // -10
 	cpu.Exec()
 
 	if cpu.PC != 0x100 {
@@ -1120,11 +1142,11 @@ func TestBEQWithPageCross(t *testing.T) {
 	cpu := NewCPU()
 	cpu.ZFlag = true
 	cpu.PC = 0xF1
-	cpu.Memory[0xF1] = 0xF0
-	cpu.Memory[0xF2] = 0x0F
+	cpu.Bus.Write(0xF1, 0xF0)
+	cpu.Bus.Write(0xF2, 0x0F)
 	cpu.Exec()
 
-	if cpu.Cycles != 5 {
+	if cpu.Cycles != 4 {
 		t.Error("did not correctly set cycles flag")
 	}
 }
@@ -1136,9 +1158,9 @@ func TestBITZeroPage(t *testing.T) {
 	cpu.VFlag = false
 	cpu.A = 0x00
 	cpu.PC = 0x01
-	cpu.Memory[1] = 0x24
-	cpu.Memory[2] = 0x03
-	cpu.Memory[3] = 0xFF
+	cpu.Bus.Write(1, 0x24)
+	cpu.Bus.Write(2, 0x03)
+	cpu.Bus.Write(3, 0xFF)
 	cpu.Exec()
 
 	if cpu.ZFlag != true {
@@ -1169,9 +1191,9 @@ func TestBITZeroPageWithNonZeroValue(t *testing.T) {
 	cpu.VFlag = false
 	cpu.A = 0x01
 	cpu.PC = 0x00
-	cpu.Memory[0] = 0x24
-	cpu.Memory[1] = 0x02
-	cpu.Memory[2] = 0x01
+	cpu.Bus.Write(0, 0x24)
+	cpu.Bus.Write(1, 0x02)
+	cpu.Bus.Write(2, 0x01)
 	cpu.Exec()
 
 	if cpu.ZFlag != false {
@@ -1200,9 +1222,9 @@ func TestBITAbsolute(t *testing.T) {
 	cpu.ZFlag = false
 	cpu.A = 0x00
 	cpu.PC = 0x01
-	cpu.Memory[1] = 0x2C
-	cpu.Memory[2] = 0xFF
-	cpu.Memory[0xFF01] = 0x01
+	cpu.Bus.Write(1, 0x2C)
+	cpu.Bus.Write(2, 0xFF)
+	cpu.Bus.Write(0xFF01, 0x01)
 	cpu.Exec()
 
 	if cpu.ZFlag != true {
@@ -1221,8 +1243,8 @@ func TestBITAbsolute(t *testing.T) {
 func TestBMI(t *testing.T) {
 	cpu := NewCPU()
 	cpu.NFlag = true
-	cpu.Memory[0] = 0x30
-	cpu.Memory[1] = 0x10
+	cpu.Bus.Write(0, 0x30)
+	cpu.Bus.Write(1, 0x10)
 	cpu.Exec()
 
 	if cpu.PC != 0x12 {
@@ -1237,8 +1259,8 @@ func TestBMI(t *testing.T) {
 func TestBMINoBranch(t *testing.T) {
 	cpu := NewCPU()
 	cpu.NFlag = false
-	cpu.Memory[0] = 0x30
-	cpu.Memory[1] = 0x10
+	cpu.Bus.Write(0, 0x30)
+	cpu.Bus.Write(1, 0x10)
 	cpu.Exec()
 
 	if cpu.PC != 0x02 {
@@ -1254,11 +1276,11 @@ func TestBMIWithPageCross(t *testing.T) {
 	cpu := NewCPU()
 	cpu.NFlag = true
 	cpu.PC = 0xF1
-	cpu.Memory[0xF1] = 0x30
-	cpu.Memory[0xF2] = 0x0F
+	cpu.Bus.Write(0xF1, 0x30)
+	cpu.Bus.Write(0xF2, 0x0F)
 	cpu.Exec()
 
-	if cpu.Cycles != 5 {
+	if cpu.Cycles != 4 {
 		t.Error("did not correctly set cycles flag")
 	}
 }
@@ -1266,8 +1288,8 @@ func TestBMIWithPageCross(t *testing.T) {
 func TestBNE(t *testing.T) {
 	cpu := NewCPU()
 	cpu.ZFlag = false
-	cpu.Memory[0] = 0xD0
-	cpu.Memory[1] = 0x0F
+	cpu.Bus.Write(0, 0xD0)
+	cpu.Bus.Write(1, 0x0F)
 	cpu.Exec()
 
 	if cpu.PC != 0x11 {
@@ -1282,8 +1304,8 @@ func TestBNE(t *testing.T) {
 func TestBNENoBranch(t *testing.T) {
 	cpu := NewCPU()
 	cpu.ZFlag = true
-	cpu.Memory[0] = 0xD0
-	cpu.Memory[1] = 0x10
+	cpu.Bus.Write(0, 0xD0)
+	cpu.Bus.Write(1, 0x10)
 	cpu.Exec()
 
 	if cpu.PC != 0x02 {
@@ -1299,15 +1321,15 @@ func TestBNEWithPageCross(t *testing.T) {
 	cpu := NewCPU()
 	cpu.ZFlag = false
 	cpu.PC = 0xF1
-	cpu.Memory[0xF1] = 0xD0
-	cpu.Memory[0xF2] = 0x0F
+	cpu.Bus.Write(0xF1, 0xD0)
+	cpu.Bus.Write(0xF2, 0x0F)
 	cpu.Exec()
 
 	if cpu.PC != 0x102 {
 		t.Error("failed to correctly set PC, got", cpu.PC)
 	}
 
-	if cpu.Cycles != 5 {
+	if cpu.Cycles != 4 {
 		t.Error("did not correctly set cycles flag")
 	}
 }
@@ -1316,8 +1338,8 @@ func TestBNEWithNegativeRelativeAddress(t *testing.T) {
 	cpu := NewCPU()
 	cpu.ZFlag = false
 	cpu.PC = 0x10A
-	cpu.Memory[0x10A] = 0xD0
-	cpu.Memory[0x10B] = 0xF4 // -10
+	cpu.Bus.Write(0x10A, 0xD0)
+	cpu.Bus.Write(0x10B, 0xF4) // -10
 	cpu.Exec()
 
 	if cpu.PC != 0x100 {
@@ -1332,8 +1354,8 @@ func TestBNEWithNegativeRelativeAddress(t *testing.T) {
 func TestBPL(t *testing.T) {
 	cpu := NewCPU()
 	cpu.NFlag = false
-	cpu.Memory[0] = 0x10
-	cpu.Memory[1] = 0x0F
+	cpu.Bus.Write(0, 0x10)
+	cpu.Bus.Write(1, 0x0F)
 	cpu.Exec()
 
 	if cpu.PC != 0x11 {
@@ -1348,8 +1370,8 @@ func TestBPL(t *testing.T) {
 func TestBPLNoBranch(t *testing.T) {
 	cpu := NewCPU()
 	cpu.NFlag = true
-	cpu.Memory[0] = 0x10
-	cpu.Memory[1] = 0x10
+	cpu.Bus.Write(0, 0x10)
+	cpu.Bus.Write(1, 0x10)
 	cpu.Exec()
 
 	if cpu.PC != 0x02 {
@@ -1365,15 +1387,15 @@ func TestBPLWithPageCross(t *testing.T) {
 	cpu := NewCPU()
 	cpu.NFlag = false
 	cpu.PC = 0xF1
-	cpu.Memory[0xF1] = 0x10
-	cpu.Memory[0xF2] = 0x0F
+	cpu.Bus.Write(0xF1, 0x10)
+	cpu.Bus.Write(0xF2, 0x0F)
 	cpu.Exec()
 
 	if cpu.PC != 0x102 {
 		t.Error("failed to correctly set PC, got", cpu.PC)
 	}
 
-	if cpu.Cycles != 5 {
+	if cpu.Cycles != 4 {
 		t.Error("did not correctly set cycles flag")
 	}
 }
@@ -1382,8 +1404,8 @@ func TestBPLWithNegativeRelativeAddress(t *testing.T) {
 	cpu := NewCPU()
 	cpu.NFlag = false
 	cpu.PC = 0x10A
-	cpu.Memory[0x10A] = 0x10
-	cpu.Memory[0x10B] = 0xF4 // -10
+	cpu.Bus.Write(0x10A, 0x10)
+	cpu.Bus.Write(0x10B, 0xF4) // -10
 	cpu.Exec()
 
 	if cpu.PC != 0x100 {
@@ -1398,8 +1420,8 @@ func TestBPLWithNegativeRelativeAddress(t *testing.T) {
 func TestBVC(t *testing.T) {
 	cpu := NewCPU()
 	cpu.VFlag = false
-	cpu.Memory[0] = 0x50
-	cpu.Memory[1] = 0x0F
+	cpu.Bus.Write(0, 0x50)
+	cpu.Bus.Write(1, 0x0F)
 	cpu.Exec()
 
 	if cpu.PC != 0x11 {
@@ -1414,8 +1436,8 @@ func TestBVC(t *testing.T) {
 func TestBVCNoBranch(t *testing.T) {
 	cpu := NewCPU()
 	cpu.VFlag = true
-	cpu.Memory[0] = 0x50
-	cpu.Memory[1] = 0x50
+	cpu.Bus.Write(0, 0x50)
+	cpu.Bus.Write(1, 0x50)
 	cpu.Exec()
 
 	if cpu.PC != 0x02 {
@@ -1431,15 +1453,15 @@ func TestBVCWithPageCross(t *testing.T) {
 	cpu := NewCPU()
 	cpu.VFlag = false
 	cpu.PC = 0xF1
-	cpu.Memory[0xF1] = 0x50
-	cpu.Memory[0xF2] = 0x0F
+	cpu.Bus.Write(0xF1, 0x50)
+	cpu.Bus.Write(0xF2, 0x0F)
 	cpu.Exec()
 
 	if cpu.PC != 0x102 {
 		t.Error("failed to correctly set PC, got", cpu.PC)
 	}
 
-	if cpu.Cycles != 5 {
+	if cpu.Cycles != 4 {
 		t.Error("did not correctly set cycles flag")
 	}
 }
@@ -1448,8 +1470,8 @@ func TestBVCWithNegativeRelativeAddress(t *testing.T) {
 	cpu := NewCPU()
 	cpu.VFlag = false
 	cpu.PC = 0x50A
-	cpu.Memory[0x50A] = 0x10
-	cpu.Memory[0x50B] = 0xF4 // -10
+	cpu.Bus.Write(0x50A, 0x10)
+	cpu.Bus.Write(0x50B, 0xF4) // -10
 	cpu.Exec()
 
 	if cpu.PC != 0x500 {
@@ -1464,8 +1486,8 @@ func TestBVCWithNegativeRelativeAddress(t *testing.T) {
 func TestBVS(t *testing.T) {
 	cpu := NewCPU()
 	cpu.VFlag = true
-	cpu.Memory[0] = 0x70
-	cpu.Memory[1] = 0x0F
+	cpu.Bus.Write(0, 0x70)
+	cpu.Bus.Write(1, 0x0F)
 	cpu.Exec()
 
 	if cpu.PC != 0x11 {
@@ -1480,8 +1502,8 @@ func TestBVS(t *testing.T) {
 func TestBVSNoBranch(t *testing.T) {
 	cpu := NewCPU()
 	cpu.VFlag = false
-	cpu.Memory[0] = 0x70
-	cpu.Memory[1] = 0x50
+	cpu.Bus.Write(0, 0x70)
+	cpu.Bus.Write(1, 0x50)
 	cpu.Exec()
 
 	if cpu.PC != 0x02 {
@@ -1497,15 +1519,15 @@ func TestBVSWithPageCross(t *testing.T) {
 	cpu := NewCPU()
 	cpu.VFlag = true
 	cpu.PC = 0xF1
-	cpu.Memory[0xF1] = 0x70
-	cpu.Memory[0xF2] = 0x0F
+	cpu.Bus.Write(0xF1, 0x70)
+	cpu.Bus.Write(0xF2, 0x0F)
 	cpu.Exec()
 
 	if cpu.PC != 0x102 {
 		t.Error("failed to correctly set PC, got", cpu.PC)
 	}
 
-	if cpu.Cycles != 5 {
+	if cpu.Cycles != 4 {
 		t.Error("did not correctly set cycles flag")
 	}
 }
@@ -1514,8 +1536,8 @@ func TestBVSWithNegativeRelativeAddress(t *testing.T) {
 	cpu := NewCPU()
 	cpu.VFlag = true
 	cpu.PC = 0x50A
-	cpu.Memory[0x50A] = 0x70
-	cpu.Memory[0x50B] = 0xF4 // -10
+	cpu.Bus.Write(0x50A, 0x70)
+	cpu.Bus.Write(0x50B, 0xF4) // -10
 	cpu.Exec()
 
 	if cpu.PC != 0x500 {
@@ -1529,8 +1551,8 @@ func TestBVSWithNegativeRelativeAddress(t *testing.T) {
 
 func TestBRK(t *testing.T) {
 	cpu := NewCPU()
-	cpu.Memory[0xFFFE] = 0x0A
-	cpu.Memory[0xFFFF] = 0x02
+	cpu.Bus.Write(0xFFFE, 0x0A)
+	cpu.Bus.Write(0xFFFF, 0x02)
 	cpu.PC = 0x00
 	cpu.Exec()
 
@@ -1551,7 +1573,7 @@ func TestCLC(t *testing.T) {
 	cpu := NewCPU()
 	cpu.CFlag = true
 	cpu.PC = 0x01
-	cpu.Memory[1] = 0x18
+	cpu.Bus.Write(1, 0x18)
 	cpu.Exec()
 
 	if cpu.CFlag != false {
@@ -1563,7 +1585,7 @@ func TestCLCFlagUnset(t *testing.T) {
 	cpu := NewCPU()
 	cpu.CFlag = false
 	cpu.PC = 0x01
-	cpu.Memory[1] = 0x18
+	cpu.Bus.Write(1, 0x18)
 	cpu.Exec()
 
 	if cpu.CFlag != false {
@@ -1575,7 +1597,7 @@ func TestCLI(t *testing.T) {
 	cpu := NewCPU()
 	cpu.IFlag = true
 	cpu.PC = 0x01
-	cpu.Memory[1] = 0x58
+	cpu.Bus.Write(1, 0x58)
 	cpu.Exec()
 
 	if cpu.CFlag != false {
@@ -1587,7 +1609,7 @@ func TestCLIFlagUnset(t *testing.T) {
 	cpu := NewCPU()
 	cpu.IFlag = false
 	cpu.PC = 0x01
-	cpu.Memory[1] = 0x58
+	cpu.Bus.Write(1, 0x58)
 	cpu.Exec()
 
 	if cpu.CFlag != false {
@@ -1599,7 +1621,7 @@ func TestCLV(t *testing.T) {
 	cpu := NewCPU()
 	cpu.VFlag = true
 	cpu.PC = 0x01
-	cpu.Memory[1] = 0xB8
+	cpu.Bus.Write(1, 0xB8)
 	cpu.Exec()
 
 	if cpu.CFlag != false {
@@ -1611,7 +1633,7 @@ func TestCLVFlagUnset(t *testing.T) {
 	cpu := NewCPU()
 	cpu.VFlag = false
 	cpu.PC = 0x01
-	cpu.Memory[1] = 0xB8
+	cpu.Bus.Write(1, 0xB8)
 	cpu.Exec()
 
 	if cpu.CFlag != false {
@@ -1626,8 +1648,8 @@ func TestCMPImmediate(t *testing.T) {
 	cpu.CFlag = false
 	cpu.A = 0x02
 	cpu.PC = 0x01
-	cpu.Memory[1] = 0xC9
-	cpu.Memory[2] = 0x01
+	cpu.Bus.Write(1, 0xC9)
+	cpu.Bus.Write(2, 0x01)
 	cpu.Exec()
 
 	if cpu.CFlag != true {
@@ -1658,8 +1680,8 @@ func TestCMPImmediateWithEqualInput(t *testing.T) {
 	cpu.CFlag = false
 	cpu.A = 0x01
 	cpu.PC = 0x01
-	cpu.Memory[1] = 0xC9
-	cpu.Memory[2] = 0x01
+	cpu.Bus.Write(1, 0xC9)
+	cpu.Bus.Write(2, 0x01)
 	cpu.Exec()
 
 	if cpu.CFlag != true {
@@ -1682,8 +1704,8 @@ func TestCMPImmediateWithOperandGreater(t *testing.T) {
 	cpu.CFlag = false
 	cpu.A = 0x01
 	cpu.PC = 0x01
-	cpu.Memory[1] = 0xC9
-	cpu.Memory[2] = 0x02
+	cpu.Bus.Write(1, 0xC9)
+	cpu.Bus.Write(2, 0x02)
 	cpu.Exec()
 
 	if cpu.CFlag != false {
@@ -1707,9 +1729,9 @@ func TestCMPZeroPage(t *testing.T) {
 	cpu.CFlag = false
 	cpu.A = 0x01
 	cpu.PC = 0x01
-	cpu.Memory[1] = 0xC5
-	cpu.Memory[2] = 0x09
-	cpu.Memory[9] = 0x02
+	cpu.Bus.Write(1, 0xC5)
+	cpu.Bus.Write(2, 0x09)
+	cpu.Bus.Write(9, 0x02)
 	cpu.Exec()
 
 	if cpu.CFlag != false {
@@ -1729,9 +1751,9 @@ func TestCMPZeroPageX(t *testing.T) {
 	cpu := NewCPU()
 	cpu.A = 0x03
 	cpu.X = 0x01
-	cpu.Memory[0] = 0xD5
-	cpu.Memory[1] = 0x08
-	cpu.Memory[9] = 0x03
+	cpu.Bus.Write(0, 0xD5)
+	cpu.Bus.Write(1, 0x08)
+	cpu.Bus.Write(9, 0x03)
 	cpu.Exec()
 
 	if cpu.ZFlag != true {
@@ -1754,10 +1776,10 @@ func TestCMPZeroPageX(t *testing.T) {
 func TestCMPAbsolute(t *testing.T) {
 	cpu := NewCPU()
 	cpu.A = 0x05
-	cpu.Memory[0] = 0xCD
-	cpu.Memory[1] = 0x01
-	cpu.Memory[2] = 0xFF
-	cpu.Memory[0xFF01] = 0x03
+	cpu.Bus.Write(0, 0xCD)
+	cpu.Bus.Write(1, 0x01)
+	cpu.Bus.Write(2, 0xFF)
+	cpu.Bus.Write(0xFF01, 0x03)
 	cpu.Exec()
 
 	if cpu.ZFlag != false {
@@ -1785,10 +1807,10 @@ func TestCMPAbsoluteX(t *testing.T) {
 	cpu := NewCPU()
 	cpu.A = 0x05
 	cpu.X = 0x05
-	cpu.Memory[0] = 0xDD
-	cpu.Memory[1] = 0x01
-	cpu.Memory[2] = 0xFF
-	cpu.Memory[0xFF06] = 0x06
+	cpu.Bus.Write(0, 0xDD)
+	cpu.Bus.Write(1, 0x01)
+	cpu.Bus.Write(2, 0xFF)
+	cpu.Bus.Write(0xFF06, 0x06)
 	cpu.Exec()
 
 	if cpu.ZFlag != false {
@@ -1816,10 +1838,10 @@ func TestCMPAbsoluteXWithPageCross(t *testing.T) {
 	cpu := NewCPU()
 	cpu.A = 0x05
 	cpu.X = 0xFF
-	cpu.Memory[0] = 0xDD
-	cpu.Memory[1] = 0x01
-	cpu.Memory[2] = 0xFE
-	cpu.Memory[0xFF00] = 0x0F
+	cpu.Bus.Write(0, 0xDD)
+	cpu.Bus.Write(1, 0x01)
+	cpu.Bus.Write(2, 0xFE)
+	cpu.Bus.Write(0xFF00, 0x0F)
 	cpu.Exec()
 
 	if cpu.NFlag != true {
@@ -1839,10 +1861,10 @@ func TestCMPAbsoluteY(t *testing.T) {
 	cpu := NewCPU()
 	cpu.A = 0x05
 	cpu.Y = 0x05
-	cpu.Memory[0] = 0xD9
-	cpu.Memory[1] = 0x01
-	cpu.Memory[2] = 0xFF
-	cpu.Memory[0xFF06] = 0x06
+	cpu.Bus.Write(0, 0xD9)
+	cpu.Bus.Write(1, 0x01)
+	cpu.Bus.Write(2, 0xFF)
+	cpu.Bus.Write(0xFF06, 0x06)
 	cpu.Exec()
 
 	if cpu.ZFlag != false {
@@ -1870,10 +1892,10 @@ func TestCMPAbsoluteYWithPageCross(t *testing.T) {
 	cpu := NewCPU()
 	cpu.A = 0x05
 	cpu.Y = 0xFF
-	cpu.Memory[0] = 0xD9
-	cpu.Memory[1] = 0x01
-	cpu.Memory[2] = 0xFE
-	cpu.Memory[0xFF00] = 0x0F
+	cpu.Bus.Write(0, 0xD9)
+	cpu.Bus.Write(1, 0x01)
+	cpu.Bus.Write(2, 0xFE)
+	cpu.Bus.Write(0xFF00, 0x0F)
 	cpu.Exec()
 
 	if cpu.NFlag != true {
@@ -1893,10 +1915,10 @@ func TestCMPIndexedIndirect(t *testing.T) {
 	cpu := NewCPU()
 	cpu.A = 0x05
 	cpu.X = 0x01
-	cpu.Memory[0] = 0xC1
-	cpu.Memory[1] = 0xFE
-	cpu.Memory[9] = 0x06
-	cpu.Memory[0xFF] = 0x09
+	cpu.Bus.Write(0, 0xC1)
+	cpu.Bus.Write(1, 0xFE)
+	cpu.Bus.Write(9, 0x06)
+	cpu.Bus.Write(0xFF, 0x09)
 	cpu.Exec()
 
 	if cpu.NFlag != true {
@@ -1916,10 +1938,10 @@ func TestCMPIndexedIndirectWithOverflow(t *testing.T) {
 	cpu := NewCPU()
 	cpu.A = 0x05
 	cpu.X = 0x0B
-	cpu.Memory[0] = 0xC1
-	cpu.Memory[1] = 0xFF
-	cpu.Memory[9] = 0x06
-	cpu.Memory[0x0A] = 0x09
+	cpu.Bus.Write(0, 0xC1)
+	cpu.Bus.Write(1, 0xFF)
+	cpu.Bus.Write(9, 0x06)
+	cpu.Bus.Write(0x0A, 0x09)
 	cpu.Exec()
 
 	if cpu.NFlag != true {
@@ -1939,10 +1961,10 @@ func TestCMPIndirectIndexed(t *testing.T) {
 	cpu := NewCPU()
 	cpu.A = 0x05
 	cpu.Y = 0x01
-	cpu.Memory[0] = 0xD1
-	cpu.Memory[1] = 0x02
-	cpu.Memory[2] = 0x05
-	cpu.Memory[6] = 0x06
+	cpu.Bus.Write(0, 0xD1)
+	cpu.Bus.Write(1, 0x02)
+	cpu.Bus.Write(2, 0x05)
+	cpu.Bus.Write(6, 0x06)
 	cpu.Exec()
 
 	if cpu.NFlag != true {
@@ -1962,10 +1984,10 @@ func TestCMPIndirectIndexedWithPageCross(t *testing.T) {
 	cpu := NewCPU()
 	cpu.A = 0x05
 	cpu.Y = 0x01
-	cpu.Memory[0] = 0xD1
-	cpu.Memory[1] = 0x02
-	cpu.Memory[2] = 0xFF
-	cpu.Memory[0x100] = 0x06
+	cpu.Bus.Write(0, 0xD1)
+	cpu.Bus.Write(1, 0x02)
+	cpu.Bus.Write(2, 0xFF)
+	cpu.Bus.Write(0x100, 0x06)
 	cpu.Exec()
 
 	if cpu.NFlag != true {
@@ -1984,8 +2006,8 @@ func TestCMPIndirectIndexedWithPageCross(t *testing.T) {
 func TestCPXImmediate(t *testing.T) {
 	cpu := NewCPU()
 	cpu.X = 0x02
-	cpu.Memory[0] = 0xE0
-	cpu.Memory[1] = 0x01
+	cpu.Bus.Write(0, 0xE0)
+	cpu.Bus.Write(1, 0x01)
 	cpu.Exec()
 
 	if cpu.CFlag != true {
@@ -2013,8 +2035,8 @@ func TestCPXImmediateWithEqualInput(t *testing.T) {
 	cpu := NewCPU()
 	cpu.X = 0x01
 	cpu.PC = 0x01
-	cpu.Memory[1] = 0xE0
-	cpu.Memory[2] = 0x01
+	cpu.Bus.Write(1, 0xE0)
+	cpu.Bus.Write(2, 0x01)
 	cpu.Exec()
 
 	if cpu.CFlag != true {
@@ -2037,8 +2059,8 @@ func TestCPXImmediateWithOperandGreater(t *testing.T) {
 	cpu.CFlag = false
 	cpu.A = 0x01
 	cpu.PC = 0x01
-	cpu.Memory[1] = 0xE0
-	cpu.Memory[2] = 0x02
+	cpu.Bus.Write(1, 0xE0)
+	cpu.Bus.Write(2, 0x02)
 	cpu.Exec()
 
 	if cpu.CFlag != false {
@@ -2058,9 +2080,9 @@ func TestCPXImmediateWithOperandGreater(t *testing.T) {
 func TestCPXZeroPage(t *testing.T) {
 	cpu := NewCPU()
 	cpu.X = 0x01
-	cpu.Memory[0] = 0xE4
-	cpu.Memory[1] = 0x09
-	cpu.Memory[9] = 0x02
+	cpu.Bus.Write(0, 0xE4)
+	cpu.Bus.Write(1, 0x09)
+	cpu.Bus.Write(9, 0x02)
 	cpu.Exec()
 
 	if cpu.CFlag != false {
@@ -2079,10 +2101,10 @@ func TestCPXZeroPage(t *testing.T) {
 func TestCPXAbsolute(t *testing.T) {
 	cpu := NewCPU()
 	cpu.X = 0x05
-	cpu.Memory[0] = 0xEC
-	cpu.Memory[1] = 0x01
-	cpu.Memory[2] = 0xFF
-	cpu.Memory[0xFF01] = 0x03
+	cpu.Bus.Write(0, 0xEC)
+	cpu.Bus.Write(1, 0x01)
+	cpu.Bus.Write(2, 0xFF)
+	cpu.Bus.Write(0xFF01, 0x03)
 	cpu.Exec()
 
 	if cpu.ZFlag != false {
@@ -2109,8 +2131,8 @@ func TestCPXAbsolute(t *testing.T) {
 func TestCPYImmediate(t *testing.T) {
 	cpu := NewCPU()
 	cpu.X = 0x02
-	cpu.Memory[0] = 0xE0
-	cpu.Memory[1] = 0x01
+	cpu.Bus.Write(0, 0xE0)
+	cpu.Bus.Write(1, 0x01)
 	cpu.Exec()
 
 	if cpu.CFlag != true {
@@ -2138,8 +2160,8 @@ func TestCPYImmediateWithEqualInput(t *testing.T) {
 	cpu := NewCPU()
 	cpu.X = 0x01
 	cpu.PC = 0x01
-	cpu.Memory[1] = 0xE0
-	cpu.Memory[2] = 0x01
+	cpu.Bus.Write(1, 0xE0)
+	cpu.Bus.Write(2, 0x01)
 	cpu.Exec()
 
 	if cpu.CFlag != true {
@@ -2162,8 +2184,8 @@ func TestCPYImmediateWithOperandGreater(t *testing.T) {
 	cpu.CFlag = false
 	cpu.A = 0x01
 	cpu.PC = 0x01
-	cpu.Memory[1] = 0xE0
-	cpu.Memory[2] = 0x02
+	cpu.Bus.Write(1, 0xE0)
+	cpu.Bus.Write(2, 0x02)
 	cpu.Exec()
 
 	if cpu.CFlag != false {
@@ -2183,9 +2205,9 @@ func TestCPYImmediateWithOperandGreater(t *testing.T) {
 func TestCPYZeroPage(t *testing.T) {
 	cpu := NewCPU()
 	cpu.X = 0x01
-	cpu.Memory[0] = 0xE4
-	cpu.Memory[1] = 0x09
-	cpu.Memory[9] = 0x02
+	cpu.Bus.Write(0, 0xE4)
+	cpu.Bus.Write(1, 0x09)
+	cpu.Bus.Write(9, 0x02)
 	cpu.Exec()
 
 	if cpu.CFlag != false {
@@ -2204,10 +2226,10 @@ func TestCPYZeroPage(t *testing.T) {
 func TestCPYAbsolute(t *testing.T) {
 	cpu := NewCPU()
 	cpu.Y = 0x05
-	cpu.Memory[0] = 0xCC
-	cpu.Memory[1] = 0x01
-	cpu.Memory[2] = 0xFF
-	cpu.Memory[0xFF01] = 0x03
+	cpu.Bus.Write(0, 0xCC)
+	cpu.Bus.Write(1, 0x01)
+	cpu.Bus.Write(2, 0xFF)
+	cpu.Bus.Write(0xFF01, 0x03)
 	cpu.Exec()
 
 	if cpu.ZFlag != false {
@@ -2235,13 +2257,13 @@ func TestDECZeroPage(t *testing.T) {
 	cpu := NewCPU()
 	cpu.ZFlag = true
 	cpu.NFlag = true
-	cpu.Memory[0] = 0xC6
-	cpu.Memory[1] = 0x0A
-	cpu.Memory[10] = 0x02
+	cpu.Bus.Write(0, 0xC6)
+	cpu.Bus.Write(1, 0x0A)
+	cpu.Bus.Write(10, 0x02)
 	cpu.Exec()
 
-	if cpu.Memory[10] != 0x01 {
-		t.Error("failed to update memory value correclty, got", cpu.Memory[10])
+	if cpu.Bus.Read(10) != 0x01 {
+		t.Error("failed to update memory value correclty, got", cpu.Bus.Read(10))
 	}
 
 	if cpu.ZFlag != false {
@@ -2265,13 +2287,13 @@ func TestDECZeroPageWithNegativeResult(t *testing.T) {
 	cpu := NewCPU()
 	cpu.ZFlag = true
 	cpu.NFlag = true
-	cpu.Memory[0] = 0xC6
-	cpu.Memory[1] = 0x0A
-	cpu.Memory[10] = 0x00
+	cpu.Bus.Write(0, 0xC6)
+	cpu.Bus.Write(1, 0x0A)
+	cpu.Bus.Write(10, 0x00)
 	cpu.Exec()
 
-	if cpu.Memory[10] != 0xFF {
-		t.Error("failed to update memory value correclty, got", cpu.Memory[10])
+	if cpu.Bus.Read(10) != 0xFF {
+		t.Error("failed to update memory value correclty, got", cpu.Bus.Read(10))
 	}
 
 	if cpu.ZFlag != false {
@@ -2295,13 +2317,13 @@ func TestDECZeroPageX(t *testing.T) {
 	cpu := NewCPU()
 	cpu.NFlag = true
 	cpu.X = 0x1
-	cpu.Memory[0] = 0xD6
-	cpu.Memory[1] = 0x09
-	cpu.Memory[10] = 0x01
+	cpu.Bus.Write(0, 0xD6)
+	cpu.Bus.Write(1, 0x09)
+	cpu.Bus.Write(10, 0x01)
 	cpu.Exec()
 
-	if cpu.Memory[10] != 0x00 {
-		t.Error("failed to update memory value correclty, got", cpu.Memory[10])
+	if cpu.Bus.Read(10) != 0x00 {
+		t.Error("failed to update memory value correclty, got", cpu.Bus.Read(10))
 	}
 
 	if cpu.ZFlag != true {
@@ -2325,14 +2347,14 @@ func TestDECAbsolute(t *testing.T) {
 	cpu := NewCPU()
 	cpu.NFlag = true
 	cpu.ZFlag = true
-	cpu.Memory[0] = 0xCE
-	cpu.Memory[1] = 0x09
-	cpu.Memory[2] = 0x09
-	cpu.Memory[0x0909] = 0x02
+	cpu.Bus.Write(0, 0xCE)
+	cpu.Bus.Write(1, 0x09)
+	cpu.Bus.Write(2, 0x09)
+	cpu.Bus.Write(0x0909, 0x02)
 	cpu.Exec()
 
-	if cpu.Memory[0x0909] != 0x01 {
-		t.Error("failed to update memory value correclty, got", cpu.Memory[10])
+	if cpu.Bus.Read(0x0909) != 0x01 {
+		t.Error("failed to update memory value correclty, got", cpu.Bus.Read(10))
 	}
 
 	if cpu.ZFlag != false {
@@ -2357,14 +2379,14 @@ func TestDECAbsoluteX(t *testing.T) {
 	cpu.X = 0x01
 	cpu.NFlag = true
 	cpu.ZFlag = true
-	cpu.Memory[0] = 0xDE
-	cpu.Memory[1] = 0x09
-	cpu.Memory[2] = 0x09
-	cpu.Memory[0x090A] = 0x02
+	cpu.Bus.Write(0, 0xDE)
+	cpu.Bus.Write(1, 0x09)
+	cpu.Bus.Write(2, 0x09)
+	cpu.Bus.Write(0x090A, 0x02)
 	cpu.Exec()
 
-	if cpu.Memory[0x090A] != 0x01 {
-		t.Error("failed to update memory value correclty, got", cpu.Memory[10])
+	if cpu.Bus.Read(0x090A) != 0x01 {
+		t.Error("failed to update memory value correclty, got", cpu.Bus.Read(10))
 	}
 
 	if cpu.ZFlag != false {
@@ -2389,7 +2411,7 @@ func TestDEX(t *testing.T) {
 	cpu.X = 0x02
 	cpu.NFlag = true
 	cpu.ZFlag = true
-	cpu.Memory[0] = 0xCA
+	cpu.Bus.Write(0, 0xCA)
 	cpu.Exec()
 
 	if cpu.X != 0x01 {
@@ -2418,7 +2440,7 @@ func TestDEY(t *testing.T) {
 	cpu.Y = 0x02
 	cpu.NFlag = true
 	cpu.ZFlag = true
-	cpu.Memory[0] = 0x88
+	cpu.Bus.Write(0, 0x88)
 	cpu.Exec()
 
 	if cpu.Y != 0x01 {
@@ -2447,8 +2469,8 @@ func TestEORImmediate(t *testing.T) {
 	cpu.ZFlag = true
 	cpu.NFlag = true
 	cpu.A = 0x06
-	cpu.Memory[0] = 0x49
-	cpu.Memory[1] = 0x05
+	cpu.Bus.Write(0, 0x49)
+	cpu.Bus.Write(1, 0x05)
 	cpu.Exec()
 
 	if cpu.A != 0x03 {
@@ -2475,9 +2497,9 @@ func TestEORImmediate(t *testing.T) {
 func TestEORZeroPage(t *testing.T) {
 	cpu := NewCPU()
 	cpu.A = 0x00
-	cpu.Memory[0] = 0x45
-	cpu.Memory[1] = 0x05
-	cpu.Memory[5] = 0x00
+	cpu.Bus.Write(0, 0x45)
+	cpu.Bus.Write(1, 0x05)
+	cpu.Bus.Write(5, 0x00)
 	cpu.Exec()
 
 	if cpu.A != 0x00 {
@@ -2501,9 +2523,9 @@ func TestEORZeroPageX(t *testing.T) {
 	cpu := NewCPU()
 	cpu.A = 0x80
 	cpu.X = 0x01
-	cpu.Memory[0] = 0x55
-	cpu.Memory[1] = 0x04
-	cpu.Memory[5] = 0x01
+	cpu.Bus.Write(0, 0x55)
+	cpu.Bus.Write(1, 0x04)
+	cpu.Bus.Write(5, 0x01)
 	cpu.Exec()
 
 	if cpu.A != 0x81 {
@@ -2526,10 +2548,10 @@ func TestEORZeroPageX(t *testing.T) {
 func TestEORAbsolute(t *testing.T) {
 	cpu := NewCPU()
 	cpu.A = 0x03
-	cpu.Memory[0] = 0x4D
-	cpu.Memory[1] = 0x01
-	cpu.Memory[2] = 0xFF
-	cpu.Memory[0xFF01] = 0x00
+	cpu.Bus.Write(0, 0x4D)
+	cpu.Bus.Write(1, 0x01)
+	cpu.Bus.Write(2, 0xFF)
+	cpu.Bus.Write(0xFF01, 0x00)
 	cpu.Exec()
 
 	if cpu.A != 0x03 {
@@ -2549,10 +2571,10 @@ func TestEORAbsoluteX(t *testing.T) {
 	cpu := NewCPU()
 	cpu.A = 0x04
 	cpu.X = 0x01
-	cpu.Memory[0] = 0x5D
-	cpu.Memory[1] = 0x01
-	cpu.Memory[2] = 0xFF
-	cpu.Memory[0xFF02] = 0x01
+	cpu.Bus.Write(0, 0x5D)
+	cpu.Bus.Write(1, 0x01)
+	cpu.Bus.Write(2, 0xFF)
+	cpu.Bus.Write(0xFF02, 0x01)
 	cpu.Exec()
 
 	if cpu.A != 0x05 {
@@ -2572,10 +2594,10 @@ func TestEORAbsoluteXWithPageCross(t *testing.T) {
 	cpu := NewCPU()
 	cpu.A = 0x04
 	cpu.X = 0x01
-	cpu.Memory[0] = 0x5D
-	cpu.Memory[1] = 0xFF
-	cpu.Memory[2] = 0x00
-	cpu.Memory[0x0100] = 0x01
+	cpu.Bus.Write(0, 0x5D)
+	cpu.Bus.Write(1, 0xFF)
+	cpu.Bus.Write(2, 0x00)
+	cpu.Bus.Write(0x0100, 0x01)
 	cpu.Exec()
 
 	if cpu.A != 0x05 {
@@ -2591,10 +2613,10 @@ func TestEORAbsoluteY(t *testing.T) {
 	cpu := NewCPU()
 	cpu.A = 0x04
 	cpu.Y = 0x01
-	cpu.Memory[0] = 0x59
-	cpu.Memory[1] = 0x01
-	cpu.Memory[2] = 0xFF
-	cpu.Memory[0xFF02] = 0x01
+	cpu.Bus.Write(0, 0x59)
+	cpu.Bus.Write(1, 0x01)
+	cpu.Bus.Write(2, 0xFF)
+	cpu.Bus.Write(0xFF02, 0x01)
 	cpu.Exec()
 
 	if cpu.A != 0x05 {
@@ -2614,10 +2636,10 @@ func TestEORAbsoluteYWithPageCross(t *testing.T) {
 	cpu := NewCPU()
 	cpu.A = 0x04
 	cpu.Y = 0x01
-	cpu.Memory[0] = 0x59
-	cpu.Memory[1] = 0xFF
-	cpu.Memory[2] = 0x00
-	cpu.Memory[0x0100] = 0x01
+	cpu.Bus.Write(0, 0x59)
+	cpu.Bus.Write(1, 0xFF)
+	cpu.Bus.Write(2, 0x00)
+	cpu.Bus.Write(0x0100, 0x01)
 	cpu.Exec()
 
 	if cpu.A != 0x05 {
@@ -2633,10 +2655,10 @@ func TestEORIndexedIndirect(t *testing.T) {
 	cpu := NewCPU()
 	cpu.A = 0x05
 	cpu.X = 0x01
-	cpu.Memory[0] = 0x41
-	cpu.Memory[1] = 0xFE
-	cpu.Memory[9] = 0x01
-	cpu.Memory[0xFF] = 0x09
+	cpu.Bus.Write(0, 0x41)
+	cpu.Bus.Write(1, 0xFE)
+	cpu.Bus.Write(9, 0x01)
+	cpu.Bus.Write(0xFF, 0x09)
 	cpu.Exec()
 
 	if cpu.A != 0x04 {
@@ -2656,10 +2678,10 @@ func TestEORIndirectIndexed(t *testing.T) {
 	cpu := NewCPU()
 	cpu.A = 0x04
 	cpu.Y = 0x01
-	cpu.Memory[0] = 0x51
-	cpu.Memory[1] = 0x02
-	cpu.Memory[2] = 0x05
-	cpu.Memory[6] = 0x05
+	cpu.Bus.Write(0, 0x51)
+	cpu.Bus.Write(1, 0x02)
+	cpu.Bus.Write(2, 0x05)
+	cpu.Bus.Write(6, 0x05)
 	cpu.Exec()
 
 	if cpu.A != 0x01 {
@@ -2679,10 +2701,10 @@ func TestEORIndirectIndexedWithPageCross(t *testing.T) {
 	cpu := NewCPU()
 	cpu.A = 0x05
 	cpu.Y = 0x01
-	cpu.Memory[0] = 0x51
-	cpu.Memory[1] = 0x02
-	cpu.Memory[2] = 0xFF
-	cpu.Memory[0x100] = 0x01
+	cpu.Bus.Write(0, 0x51)
+	cpu.Bus.Write(1, 0x02)
+	cpu.Bus.Write(2, 0xFF)
+	cpu.Bus.Write(0x100, 0x01)
 	cpu.Exec()
 
 	if cpu.A != 0x04 {
@@ -2698,13 +2720,13 @@ func TestINCZeroPage(t *testing.T) {
 	cpu := NewCPU()
 	cpu.ZFlag = true
 	cpu.NFlag = true
-	cpu.Memory[0] = 0xE6
-	cpu.Memory[1] = 0x0A
-	cpu.Memory[10] = 0x02
+	cpu.Bus.Write(0, 0xE6)
+	cpu.Bus.Write(1, 0x0A)
+	cpu.Bus.Write(10, 0x02)
 	cpu.Exec()
 
-	if cpu.Memory[10] != 0x03 {
-		t.Error("failed to update memory value correclty, got", cpu.Memory[10])
+	if cpu.Bus.Read(10) != 0x03 {
+		t.Error("failed to update memory value correclty, got", cpu.Bus.Read(10))
 	}
 
 	if cpu.ZFlag != false {
@@ -2727,13 +2749,13 @@ func TestINCZeroPage(t *testing.T) {
 func TestINCZeroPageWithNegativeResult(t *testing.T) {
 	cpu := NewCPU()
 	cpu.ZFlag = true
-	cpu.Memory[0] = 0xE6
-	cpu.Memory[1] = 0x0A
-	cpu.Memory[10] = 0x7F
+	cpu.Bus.Write(0, 0xE6)
+	cpu.Bus.Write(1, 0x0A)
+	cpu.Bus.Write(10, 0x7F)
 	cpu.Exec()
 
-	if cpu.Memory[10] != 0x80 {
-		t.Error("failed to update memory value correclty, got", cpu.Memory[10])
+	if cpu.Bus.Read(10) != 0x80 {
+		t.Error("failed to update memory value correclty, got", cpu.Bus.Read(10))
 	}
 
 	if cpu.ZFlag != false {
@@ -2758,13 +2780,13 @@ func TestINCZeroPageX(t *testing.T) {
 	cpu.NFlag = true
 	cpu.ZFlag = true
 	cpu.X = 0x1
-	cpu.Memory[0] = 0xF6
-	cpu.Memory[1] = 0x09
-	cpu.Memory[10] = 0x01
+	cpu.Bus.Write(0, 0xF6)
+	cpu.Bus.Write(1, 0x09)
+	cpu.Bus.Write(10, 0x01)
 	cpu.Exec()
 
-	if cpu.Memory[10] != 0x02 {
-		t.Error("failed to update memory value correclty, got", cpu.Memory[10])
+	if cpu.Bus.Read(10) != 0x02 {
+		t.Error("failed to update memory value correclty, got", cpu.Bus.Read(10))
 	}
 
 	if cpu.ZFlag != false {
@@ -2788,14 +2810,14 @@ func TestINCAbsolute(t *testing.T) {
 	cpu := NewCPU()
 	cpu.NFlag = true
 	cpu.ZFlag = false
-	cpu.Memory[0] = 0xEE
-	cpu.Memory[1] = 0x09
-	cpu.Memory[2] = 0x09
-	cpu.Memory[0x0909] = 0xFF
+	cpu.Bus.Write(0, 0xEE)
+	cpu.Bus.Write(1, 0x09)
+	cpu.Bus.Write(2, 0x09)
+	cpu.Bus.Write(0x0909, 0xFF)
 	cpu.Exec()
 
-	if cpu.Memory[0x0909] != 0x00 {
-		t.Error("failed to update memory value correclty, got", cpu.Memory[0x0909])
+	if cpu.Bus.Read(0x0909) != 0x00 {
+		t.Error("failed to update memory value correclty, got", cpu.Bus.Read(0x0909))
 	}
 
 	if cpu.ZFlag != true {
@@ -2820,14 +2842,14 @@ func TestINCAbsoluteX(t *testing.T) {
 	cpu.X = 0x01
 	cpu.NFlag = true
 	cpu.ZFlag = true
-	cpu.Memory[0] = 0xFE
-	cpu.Memory[1] = 0x09
-	cpu.Memory[2] = 0x09
-	cpu.Memory[0x090A] = 0x02
+	cpu.Bus.Write(0, 0xFE)
+	cpu.Bus.Write(1, 0x09)
+	cpu.Bus.Write(2, 0x09)
+	cpu.Bus.Write(0x090A, 0x02)
 	cpu.Exec()
 
-	if cpu.Memory[0x090A] != 0x03 {
-		t.Error("failed to update memory value correclty, got", cpu.Memory[0x090A])
+	if cpu.Bus.Read(0x090A) != 0x03 {
+		t.Error("failed to update memory value correclty, got", cpu.Bus.Read(0x090A))
 	}
 
 	if cpu.ZFlag != false {
@@ -2852,7 +2874,7 @@ func TestINX(t *testing.T) {
 	cpu.X = 0x02
 	cpu.NFlag = true
 	cpu.ZFlag = true
-	cpu.Memory[0] = 0xE8
+	cpu.Bus.Write(0, 0xE8)
 	cpu.Exec()
 
 	if cpu.X != 0x03 {
@@ -2881,7 +2903,7 @@ func TestINY(t *testing.T) {
 	cpu.Y = 0x02
 	cpu.NFlag = true
 	cpu.ZFlag = true
-	cpu.Memory[0] = 0xC8
+	cpu.Bus.Write(0, 0xC8)
 	cpu.Exec()
 
 	if cpu.Y != 0x03 {
@@ -2907,9 +2929,9 @@ func TestINY(t *testing.T) {
 
 func TestJMPAbsolute(t *testing.T) {
 	cpu := NewCPU()
-	cpu.Memory[0] = 0x4C
-	cpu.Memory[1] = 0x01
-	cpu.Memory[2] = 0x01
+	cpu.Bus.Write(0, 0x4C)
+	cpu.Bus.Write(1, 0x01)
+	cpu.Bus.Write(2, 0x01)
 	cpu.Exec()
 
 	if cpu.PC != 0x0101 {
@@ -2923,11 +2945,11 @@ func TestJMPAbsolute(t *testing.T) {
 
 func TestJMPIndirect(t *testing.T) {
 	cpu := NewCPU()
-	cpu.Memory[0] = 0x6C
-	cpu.Memory[1] = 0x01
-	cpu.Memory[2] = 0x02
-	cpu.Memory[0x0201] = 0x07
-	cpu.Memory[0x0202] = 0x01
+	cpu.Bus.Write(0, 0x6C)
+	cpu.Bus.Write(1, 0x01)
+	cpu.Bus.Write(2, 0x02)
+	cpu.Bus.Write(0x0201, 0x07)
+	cpu.Bus.Write(0x0202, 0x01)
 	cpu.Exec()
 
 	if cpu.PC != 0x0107 {
@@ -2941,11 +2963,11 @@ func TestJMPIndirect(t *testing.T) {
 
 func TestJMPIndirectWithHardwareBug(t *testing.T) {
 	cpu := NewCPU()
-	cpu.Memory[0] = 0x6C
-	cpu.Memory[1] = 0xFF
-	cpu.Memory[2] = 0x02
-	cpu.Memory[0x02FF] = 0x07
-	cpu.Memory[0x0200] = 0x01
+	cpu.Bus.Write(0, 0x6C)
+	cpu.Bus.Write(1, 0xFF)
+	cpu.Bus.Write(2, 0x02)
+	cpu.Bus.Write(0x02FF, 0x07)
+	cpu.Bus.Write(0x0200, 0x01)
 	cpu.Exec()
 
 	if cpu.PC != 0x0107 {
@@ -2959,16 +2981,16 @@ func TestJMPIndirectWithHardwareBug(t *testing.T) {
 
 func TestJSR(t *testing.T) {
 	cpu := NewCPU()
-	cpu.Memory[0] = 0x20
-	cpu.Memory[1] = 0x02
-	cpu.Memory[2] = 0x02
+	cpu.Bus.Write(0, 0x20)
+	cpu.Bus.Write(1, 0x02)
+	cpu.Bus.Write(2, 0x02)
 	cpu.Exec()
 
 	if cpu.PC != 0x0202 {
 		t.Error("did not correctly update PC, got", cpu.PC)
 	}
 
-	returnAddress := cpu.stackPop16()
+	returnAddress := popStack16(cpu)
 	if returnAddress != 0x02 {
 		t.Error("did not push the expected return address onto the stack, got:", returnAddress)
 	}
@@ -2980,8 +3002,8 @@ func TestJSR(t *testing.T) {
 
 func TestLDAImmediate(t *testing.T) {
 	cpu := NewCPU()
-	cpu.Memory[0] = 0xA9
-	cpu.Memory[1] = 0x07
+	cpu.Bus.Write(0, 0xA9)
+	cpu.Bus.Write(1, 0x07)
 	cpu.Exec()
 
 	if cpu.A != 0x07 {
@@ -2999,8 +3021,8 @@ func TestLDAImmediate(t *testing.T) {
 
 func TestLDAImmediateSetsZeroFlag(t *testing.T) {
 	cpu := NewCPU()
-	cpu.Memory[0] = 0xA9
-	cpu.Memory[1] = 0x00
+	cpu.Bus.Write(0, 0xA9)
+	cpu.Bus.Write(1, 0x00)
 	cpu.Exec()
 
 	if cpu.ZFlag != true {
@@ -3014,8 +3036,8 @@ func TestLDAImmediateSetsZeroFlag(t *testing.T) {
 
 func TestLDAImmediateSetsNegativeFlag(t *testing.T) {
 	cpu := NewCPU()
-	cpu.Memory[0] = 0xA9
-	cpu.Memory[1] = 0x80
+	cpu.Bus.Write(0, 0xA9)
+	cpu.Bus.Write(1, 0x80)
 	cpu.Exec()
 
 	if cpu.ZFlag != false {
@@ -3211,8 +3233,8 @@ func TestLDAIndirectIndexedWithPageCross(t *testing.T) {
 
 func TestLDXImmediate(t *testing.T) {
 	cpu := NewCPU()
-	cpu.Memory[0] = 0xA2
-	cpu.Memory[1] = 0x07
+	cpu.Bus.Write(0, 0xA2)
+	cpu.Bus.Write(1, 0x07)
 	cpu.Exec()
 
 	if cpu.X != 0x07 {
@@ -3230,8 +3252,8 @@ func TestLDXImmediate(t *testing.T) {
 
 func TestLDXImmediateSetsZeroFlag(t *testing.T) {
 	cpu := NewCPU()
-	cpu.Memory[0] = 0xA2
-	cpu.Memory[1] = 0x00
+	cpu.Bus.Write(0, 0xA2)
+	cpu.Bus.Write(1, 0x00)
 	cpu.Exec()
 
 	if cpu.ZFlag != true {
@@ -3245,8 +3267,8 @@ func TestLDXImmediateSetsZeroFlag(t *testing.T) {
 
 func TestLDXImmediateSetsNegativeFlag(t *testing.T) {
 	cpu := NewCPU()
-	cpu.Memory[0] = 0xA2
-	cpu.Memory[1] = 0x80
+	cpu.Bus.Write(0, 0xA2)
+	cpu.Bus.Write(1, 0x80)
 	cpu.Exec()
 
 	if cpu.ZFlag != false {
@@ -3351,8 +3373,8 @@ func TestLDXAbsoluteYWithPageCross(t *testing.T) {
 
 func TestLDYImmediate(t *testing.T) {
 	cpu := NewCPU()
-	cpu.Memory[0] = 0xA0
-	cpu.Memory[1] = 0x07
+	cpu.Bus.Write(0, 0xA0)
+	cpu.Bus.Write(1, 0x07)
 	cpu.Exec()
 
 	if cpu.Y != 0x07 {
@@ -3370,8 +3392,8 @@ func TestLDYImmediate(t *testing.T) {
 
 func TestLDYImmediateSetsZeroFlag(t *testing.T) {
 	cpu := NewCPU()
-	cpu.Memory[0] = 0xA0
-	cpu.Memory[1] = 0x00
+	cpu.Bus.Write(0, 0xA0)
+	cpu.Bus.Write(1, 0x00)
 	cpu.Exec()
 
 	if cpu.ZFlag != true {
@@ -3385,8 +3407,8 @@ func TestLDYImmediateSetsZeroFlag(t *testing.T) {
 
 func TestLDYImmediateSetsNegativeFlag(t *testing.T) {
 	cpu := NewCPU()
-	cpu.Memory[0] = 0xA0
-	cpu.Memory[1] = 0x80
+	cpu.Bus.Write(0, 0xA0)
+	cpu.Bus.Write(1, 0x80)
 	cpu.Exec()
 
 	if cpu.ZFlag != false {
@@ -3494,7 +3516,7 @@ func TestLSRAccumulator(t *testing.T) {
 	cpu.A = 0x08
 	cpu.CFlag = true
 	cpu.NFlag = true
-	cpu.Memory[0] = 0x4A
+	cpu.Bus.Write(0, 0x4A)
 	cpu.Exec()
 
 	if cpu.A != 0x04 {
@@ -3513,7 +3535,7 @@ func TestLSRAccumulator(t *testing.T) {
 func TestLSRAccumulatorWithCarry(t *testing.T) {
 	cpu := NewCPU()
 	cpu.A = 0x80
-	cpu.Memory[0] = 0x4A
+	cpu.Bus.Write(0, 0x4A)
 	cpu.Exec()
 
 	if cpu.A != 0x40 {
@@ -3531,8 +3553,8 @@ func TestLSRZeroPage(t *testing.T) {
 	harness.SetupZeroPage()
 	harness.Run()
 
-	if harness.Cpu.Memory[0x17] != 0x03 {
-		t.Error("did not correctly set Memory, got: ", harness.Cpu.Memory[0x17])
+	if harness.Cpu.Bus.Read(0x17) != 0x03 {
+		t.Error("did not correctly set Memory, got: ", harness.Cpu.Bus.Read(0x17))
 	}
 
 	if harness.Cpu.PC != 0x02 {
@@ -3550,8 +3572,8 @@ func TestLSRZeroPageX(t *testing.T) {
 	harness.SetupZeroPageX()
 	harness.Run()
 
-	if harness.Cpu.Memory[0x8F] != 0x03 {
-		t.Error("did not correctly set Memory, got: ", harness.Cpu.Memory[0x8F])
+	if harness.Cpu.Bus.Read(0x8F) != 0x03 {
+		t.Error("did not correctly set Memory, got: ", harness.Cpu.Bus.Read(0x8F))
 	}
 
 	if harness.Cpu.PC != 0x02 {
@@ -3569,8 +3591,8 @@ func TestLSRAbsolute(t *testing.T) {
 	harness.SetupAbsolute()
 	harness.Run()
 
-	if harness.Cpu.Memory[0x8080] != 0x03 {
-		t.Error("did not correctly set Memory, got: ", harness.Cpu.Memory[0x8080])
+	if harness.Cpu.Bus.Read(0x8080) != 0x03 {
+		t.Error("did not correctly set Memory, got: ", harness.Cpu.Bus.Read(0x8080))
 	}
 
 	if harness.Cpu.PC != 0x03 {
@@ -3588,8 +3610,8 @@ func TestLSRAbsoluteX(t *testing.T) {
 	harness.SetupAbsoluteX()
 	harness.Run()
 
-	if harness.Cpu.Memory[0xFF81] != 0x03 {
-		t.Error("did not correctly set Memory, got: ", harness.Cpu.Memory[0xFF81])
+	if harness.Cpu.Bus.Read(0xFF81) != 0x03 {
+		t.Error("did not correctly set Memory, got: ", harness.Cpu.Bus.Read(0xFF81))
 	}
 
 	if harness.Cpu.PC != 0x03 {
@@ -3603,7 +3625,7 @@ func TestLSRAbsoluteX(t *testing.T) {
 
 func TestNOP(t *testing.T) {
 	cpu := NewCPU()
-	cpu.Memory[0] = 0xEA
+	cpu.Bus.Write(0, 0xEA)
 	cpu.Exec()
 
 	if cpu.PC != 0x01 {
@@ -3620,8 +3642,8 @@ func TestORAImmediate(t *testing.T) {
 	cpu.ZFlag = true
 	cpu.NFlag = true
 	cpu.A = 0x03
-	cpu.Memory[0] = 0x09
-	cpu.Memory[1] = 0x01
+	cpu.Bus.Write(0, 0x09)
+	cpu.Bus.Write(1, 0x01)
 	cpu.Exec()
 
 	if cpu.A != 0x03 {
@@ -3838,10 +3860,10 @@ func TestORAIndirectIndexedWithPageCross(t *testing.T) {
 func TestPHA(t *testing.T) {
 	cpu := NewCPU()
 	cpu.A = 0xD3
-	cpu.Memory[0] = 0x48
+	cpu.Bus.Write(0, 0x48)
 	cpu.Exec()
 
-	stack := cpu.stackPop()
+	stack := popStack(cpu)
 
 	if stack != 0xD3 {
 		t.Error("did not correctly push A onto stack, got: ", stack)
@@ -3866,10 +3888,10 @@ func TestPHP(t *testing.T) {
 	cpu.IFlag = true
 	cpu.ZFlag = true
 	cpu.CFlag = true
-	cpu.Memory[0] = 0x08
+	cpu.Bus.Write(0, 0x08)
 	cpu.Exec()
 
-	stack := cpu.stackPop()
+	stack := popStack(cpu)
 
 	if stack&0x30 != 0x30 {
 		t.Error("did not set bits 5 and 4")
@@ -3892,8 +3914,8 @@ func TestPLA(t *testing.T) {
 	cpu := NewCPU()
 	cpu.NFlag = true
 	cpu.ZFlag = true
-	cpu.stackPush(0x07)
-	cpu.Memory[0] = 0x68
+	pushStack(cpu, 0x07)
+	cpu.Bus.Write(0, 0x68)
 	cpu.Exec()
 
 	if cpu.A != 0x07 {
@@ -3911,8 +3933,8 @@ func TestPLA(t *testing.T) {
 
 func TestPLP(t *testing.T) {
 	cpu := NewCPU()
-	cpu.stackPush(0xFF)
-	cpu.Memory[0] = 0x28
+	pushStack(cpu, 0xFF)
+	cpu.Bus.Write(0, 0x28)
 	cpu.Exec()
 
 	if cpu.PC != 0x01 {
@@ -3990,8 +4012,8 @@ func TestROLZeroPage(t *testing.T) {
 	harness.SetupZeroPage()
 	harness.Run()
 
-	if harness.Cpu.Memory[0x17] != 0x0E {
-		t.Error("did not correctly set memory value, got: ", harness.Cpu.Memory[0x17])
+	if harness.Cpu.Bus.Read(0x17) != 0x0E {
+		t.Error("did not correctly set memory value, got: ", harness.Cpu.Bus.Read(0x17))
 	}
 
 	if harness.Cpu.PC != 0x02 {
@@ -4009,8 +4031,8 @@ func TestROLZeroPageX(t *testing.T) {
 	harness.SetupZeroPageX()
 	harness.Run()
 
-	if harness.Cpu.Memory[0x8F] != 0x0E {
-		t.Error("did not correctly set memory value, got: ", harness.Cpu.Memory[0x8F])
+	if harness.Cpu.Bus.Read(0x8F) != 0x0E {
+		t.Error("did not correctly set memory value, got: ", harness.Cpu.Bus.Read(0x8F))
 	}
 
 	if harness.Cpu.PC != 0x02 {
@@ -4028,8 +4050,8 @@ func TestROLAbsolute(t *testing.T) {
 	harness.SetupAbsolute()
 	harness.Run()
 
-	if harness.Cpu.Memory[0x8080] != 0x0E {
-		t.Error("did not correctly set memory value, got: ", harness.Cpu.Memory[0x8080])
+	if harness.Cpu.Bus.Read(0x8080) != 0x0E {
+		t.Error("did not correctly set memory value, got: ", harness.Cpu.Bus.Read(0x8080))
 	}
 
 	if harness.Cpu.PC != 0x03 {
@@ -4047,8 +4069,8 @@ func TestROLAbsoluteX(t *testing.T) {
 	harness.SetupAbsoluteX()
 	harness.Run()
 
-	if harness.Cpu.Memory[0xFF81] != 0x0E {
-		t.Error("did not correctly set memory value, got: ", harness.Cpu.Memory[0xFF81])
+	if harness.Cpu.Bus.Read(0xFF81) != 0x0E {
+		t.Error("did not correctly set memory value, got: ", harness.Cpu.Bus.Read(0xFF81))
 	}
 
 	if harness.Cpu.PC != 0x03 {
@@ -4072,7 +4094,8 @@ func TestROR(t *testing.T) {
 		t.Error("did not correctly set Accumulator, got: ", harness.Cpu.A)
 	}
 
-	if harness.Cpu.CFlag != true {
+	// bit 0 of 0x08 is clear, so nothing is rotated into the carry
+	if harness.Cpu.CFlag != false {
 		t.Error("did not correctly set CFlag, got: ", harness.Cpu.CFlag)
 	}
 
@@ -4095,8 +4118,8 @@ func TestRORZeroPage(t *testing.T) {
 	harness.SetupZeroPage()
 	harness.Run()
 
-	if harness.Cpu.Memory[0x17] != 0x03 {
-		t.Error("did not correctly set memory value, got: ", harness.Cpu.Memory[0x17])
+	if harness.Cpu.Bus.Read(0x17) != 0x03 {
+		t.Error("did not correctly set memory value, got: ", harness.Cpu.Bus.Read(0x17))
 	}
 
 	if harness.Cpu.PC != 0x02 {
@@ -4114,8 +4137,8 @@ func TestRORZeroPageX(t *testing.T) {
 	harness.SetupZeroPageX()
 	harness.Run()
 
-	if harness.Cpu.Memory[0x8F] != 0x03 {
-		t.Error("did not correctly set memory value, got: ", harness.Cpu.Memory[0x8F])
+	if harness.Cpu.Bus.Read(0x8F) != 0x03 {
+		t.Error("did not correctly set memory value, got: ", harness.Cpu.Bus.Read(0x8F))
 	}
 
 	if harness.Cpu.PC != 0x02 {
@@ -4133,8 +4156,8 @@ func TestRORAbsolute(t *testing.T) {
 	harness.SetupAbsolute()
 	harness.Run()
 
-	if harness.Cpu.Memory[0x8080] != 0x03 {
-		t.Error("did not correctly set memory value, got: ", harness.Cpu.Memory[0x8080])
+	if harness.Cpu.Bus.Read(0x8080) != 0x03 {
+		t.Error("did not correctly set memory value, got: ", harness.Cpu.Bus.Read(0x8080))
 	}
 
 	if harness.Cpu.PC != 0x03 {
@@ -4152,8 +4175,8 @@ func TestRORAbsoluteX(t *testing.T) {
 	harness.SetupAbsoluteX()
 	harness.Run()
 
-	if harness.Cpu.Memory[0xFF81] != 0x03 {
-		t.Error("did not correctly set memory value, got: ", harness.Cpu.Memory[0xFF81])
+	if harness.Cpu.Bus.Read(0xFF81) != 0x03 {
+		t.Error("did not correctly set memory value, got: ", harness.Cpu.Bus.Read(0xFF81))
 	}
 
 	if harness.Cpu.PC != 0x03 {
@@ -4167,8 +4190,8 @@ func TestRORAbsoluteX(t *testing.T) {
 
 func TestRTI(t *testing.T) {
 	cpu := NewCPU()
-	cpu.stackPush(0xFF)
-	cpu.Memory[0] = 0x40
+	pushStack(cpu, 0xFF)
+	cpu.Bus.Write(0, 0x40)
 	cpu.Exec()
 
 	if cpu.PC != 0x01 {
@@ -4213,8 +4236,8 @@ func TestRTI(t *testing.T) {
 
 func TestRTS(t *testing.T) {
 	cpu := NewCPU()
-	cpu.stackPush(0xFA)
-	cpu.Memory[0] = 0x60
+	pushStack(cpu, 0xFA)
+	cpu.Bus.Write(0, 0x60)
 	cpu.Exec()
 
 	if cpu.PC != 0xFB {
@@ -4230,8 +4253,8 @@ func TestSBC(t *testing.T) {
 	cpu := NewCPU()
 	cpu.A = 0x0A
 	cpu.CFlag = true
-	cpu.Memory[0] = 0xE9
-	cpu.Memory[1] = 0x09
+	cpu.Bus.Write(0, 0xE9)
+	cpu.Bus.Write(1, 0x09)
 	cpu.Exec()
 
 	if cpu.A != 1 {
@@ -4259,8 +4282,8 @@ func TestSBCWithOverflow(t *testing.T) {
 	cpu := NewCPU()
 	cpu.A = 0x50
 	cpu.CFlag = true
-	cpu.Memory[0] = 0xE9
-	cpu.Memory[1] = 0xB0
+	cpu.Bus.Write(0, 0xE9)
+	cpu.Bus.Write(1, 0xB0)
 	cpu.Exec()
 
 	if cpu.A != 160 {
@@ -4280,8 +4303,8 @@ func TestSBCWithOverflowWithoutBorrow(t *testing.T) {
 	cpu := NewCPU()
 	cpu.A = 0xD0
 	cpu.CFlag = true
-	cpu.Memory[0] = 0xE9
-	cpu.Memory[1] = 0x70
+	cpu.Bus.Write(0, 0xE9)
+	cpu.Bus.Write(1, 0x70)
 	cpu.Exec()
 
 	if cpu.A != 96 {
@@ -4300,8 +4323,8 @@ func TestSBCWithOverflowWithoutBorrow(t *testing.T) {
 func TestSBCWithBorrow(t *testing.T) {
 	cpu := NewCPU()
 	cpu.A = 0x02
-	cpu.Memory[0] = 0xE9
-	cpu.Memory[1] = 0x01
+	cpu.Bus.Write(0, 0xE9)
+	cpu.Bus.Write(1, 0x01)
 	cpu.Exec()
 
 	if cpu.A != 0 {
@@ -4529,7 +4552,7 @@ func TestSBCIndirectIndexedPageCross(t *testing.T) {
 
 func TestSEC(t *testing.T) {
 	cpu := NewCPU()
-	cpu.Memory[0] = 0x38
+	cpu.Bus.Write(0, 0x38)
 	cpu.Exec()
 
 	if cpu.CFlag != true {
@@ -4547,7 +4570,7 @@ func TestSEC(t *testing.T) {
 
 func TestSED(t *testing.T) {
 	cpu := NewCPU()
-	cpu.Memory[0] = 0xF8
+	cpu.Bus.Write(0, 0xF8)
 	cpu.Exec()
 
 	if cpu.DFlag != true {
@@ -4565,7 +4588,7 @@ func TestSED(t *testing.T) {
 
 func TestSEI(t *testing.T) {
 	cpu := NewCPU()
-	cpu.Memory[0] = 0x78
+	cpu.Bus.Write(0, 0x78)
 	cpu.Exec()
 
 	if cpu.IFlag != true {
@@ -4588,8 +4611,8 @@ func TestSTAZeroPage(t *testing.T) {
 	harness.SetupZeroPage()
 	harness.Run()
 
-	if harness.Cpu.Memory[0x17] != 0xDD {
-		t.Error("did not correctly set memory, got: ", harness.Cpu.Memory[0x17])
+	if harness.Cpu.Bus.Read(0x17) != 0xDD {
+		t.Error("did not correctly set memory, got: ", harness.Cpu.Bus.Read(0x17))
 	}
 
 	if harness.Cpu.PC != 2 {
@@ -4608,8 +4631,8 @@ func TestSTAZeroPageX(t *testing.T) {
 	harness.SetupZeroPageX()
 	harness.Run()
 
-	if harness.Cpu.Memory[0x8F] != 0xDD {
-		t.Error("did not correctly set memory, got: ", harness.Cpu.Memory[0x8F])
+	if harness.Cpu.Bus.Read(0x8F) != 0xDD {
+		t.Error("did not correctly set memory, got: ", harness.Cpu.Bus.Read(0x8F))
 	}
 
 	if harness.Cpu.PC != 2 {
@@ -4628,8 +4651,8 @@ func TestSTAAbsolute(t *testing.T) {
 	harness.SetupAbsolute()
 	harness.Run()
 
-	if harness.Cpu.Memory[0x8080] != 0xDD {
-		t.Error("did not correctly set memory, got: ", harness.Cpu.Memory[0x8080])
+	if harness.Cpu.Bus.Read(0x8080) != 0xDD {
+		t.Error("did not correctly set memory, got: ", harness.Cpu.Bus.Read(0x8080))
 	}
 
 	if harness.Cpu.PC != 3 {
@@ -4648,8 +4671,8 @@ func TestSTAAbsoluteX(t *testing.T) {
 	harness.SetupAbsoluteX()
 	harness.Run()
 
-	if harness.Cpu.Memory[0xFF81] != 0xDD {
-		t.Error("did not correctly set memory, got: ", harness.Cpu.Memory[0xFF81])
+	if harness.Cpu.Bus.Read(0xFF81) != 0xDD {
+		t.Error("did not correctly set memory, got: ", harness.Cpu.Bus.Read(0xFF81))
 	}
 
 	if harness.Cpu.PC != 3 {
@@ -4668,8 +4691,8 @@ func TestSTAAbsoluteY(t *testing.T) {
 	harness.SetupAbsoluteY()
 	harness.Run()
 
-	if harness.Cpu.Memory[0xFF81] != 0xDD {
-		t.Error("did not correctly set memory, got: ", harness.Cpu.Memory[0xFF81])
+	if harness.Cpu.Bus.Read(0xFF81) != 0xDD {
+		t.Error("did not correctly set memory, got: ", harness.Cpu.Bus.Read(0xFF81))
 	}
 
 	if harness.Cpu.PC != 3 {
@@ -4688,8 +4711,8 @@ func TestSTAIndexedIndirect(t *testing.T) {
 	harness.SetupIndexedIndirect()
 	harness.Run()
 
-	if harness.Cpu.Memory[0x09] != 0xDD {
-		t.Error("did not correctly set memory, got: ", harness.Cpu.Memory[0x09])
+	if harness.Cpu.Bus.Read(0x09) != 0xDD {
+		t.Error("did not correctly set memory, got: ", harness.Cpu.Bus.Read(0x09))
 	}
 
 	if harness.Cpu.PC != 2 {
@@ -4708,8 +4731,8 @@ func TestSTAIndirectIndexed(t *testing.T) {
 	harness.SetupIndirectIndexed()
 	harness.Run()
 
-	if harness.Cpu.Memory[0x06] != 0xDD {
-		t.Error("did not correctly set memory, got: ", harness.Cpu.Memory[0x09])
+	if harness.Cpu.Bus.Read(0x06) != 0xDD {
+		t.Error("did not correctly set memory, got: ", harness.Cpu.Bus.Read(0x09))
 	}
 
 	if harness.Cpu.PC != 2 {
@@ -4728,8 +4751,8 @@ func TestSTXZeroPage(t *testing.T) {
 	harness.SetupZeroPage()
 	harness.Run()
 
-	if harness.Cpu.Memory[0x17] != 0xDD {
-		t.Error("did not correctly set memory, got: ", harness.Cpu.Memory[0x17])
+	if harness.Cpu.Bus.Read(0x17) != 0xDD {
+		t.Error("did not correctly set memory, got: ", harness.Cpu.Bus.Read(0x17))
 	}
 
 	if harness.Cpu.PC != 2 {
@@ -4748,8 +4771,8 @@ func TestSTXZeroPageY(t *testing.T) {
 	harness.SetupZeroPageY()
 	harness.Run()
 
-	if harness.Cpu.Memory[0x8F] != 0xDD {
-		t.Error("did not correctly set memory, got: ", harness.Cpu.Memory[0x8F])
+	if harness.Cpu.Bus.Read(0x8F) != 0xDD {
+		t.Error("did not correctly set memory, got: ", harness.Cpu.Bus.Read(0x8F))
 	}
 
 	if harness.Cpu.PC != 2 {
@@ -4768,8 +4791,8 @@ func TestSTXAbsolute(t *testing.T) {
 	harness.SetupAbsolute()
 	harness.Run()
 
-	if harness.Cpu.Memory[0x8080] != 0xDD {
-		t.Error("did not correctly set memory, got: ", harness.Cpu.Memory[0x8080])
+	if harness.Cpu.Bus.Read(0x8080) != 0xDD {
+		t.Error("did not correctly set memory, got: ", harness.Cpu.Bus.Read(0x8080))
 	}
 
 	if harness.Cpu.PC != 3 {
@@ -4788,8 +4811,8 @@ func TestSTYZeroPage(t *testing.T) {
 	harness.SetupZeroPage()
 	harness.Run()
 
-	if harness.Cpu.Memory[0x17] != 0xDD {
-		t.Error("did not correctly set memory, got: ", harness.Cpu.Memory[0x17])
+	if harness.Cpu.Bus.Read(0x17) != 0xDD {
+		t.Error("did not correctly set memory, got: ", harness.Cpu.Bus.Read(0x17))
 	}
 
 	if harness.Cpu.PC != 2 {
@@ -4808,8 +4831,8 @@ func TestSTYZeroPageX(t *testing.T) {
 	harness.SetupZeroPageX()
 	harness.Run()
 
-	if harness.Cpu.Memory[0x8F] != 0xDD {
-		t.Error("did not correctly set memory, got: ", harness.Cpu.Memory[0x8F])
+	if harness.Cpu.Bus.Read(0x8F) != 0xDD {
+		t.Error("did not correctly set memory, got: ", harness.Cpu.Bus.Read(0x8F))
 	}
 
 	if harness.Cpu.PC != 2 {
@@ -4828,8 +4851,8 @@ func TestSTYAbsolute(t *testing.T) {
 	harness.SetupAbsolute()
 	harness.Run()
 
-	if harness.Cpu.Memory[0x8080] != 0xDD {
-		t.Error("did not correctly set memory, got: ", harness.Cpu.Memory[0x8080])
+	if harness.Cpu.Bus.Read(0x8080) != 0xDD {
+		t.Error("did not correctly set memory, got: ", harness.Cpu.Bus.Read(0x8080))
 	}
 
 	if harness.Cpu.PC != 3 {
@@ -4845,7 +4868,7 @@ func TestTAX(t *testing.T) {
 	cpu := NewCPU()
 	cpu.A = 0x87
 	cpu.ZFlag = true
-	cpu.Memory[0] = 0xAA
+	cpu.Bus.Write(0, 0xAA)
 	cpu.Exec()
 
 	if cpu.X != 0x87 {
@@ -4873,7 +4896,7 @@ func TestTAY(t *testing.T) {
 	cpu := NewCPU()
 	cpu.ZFlag = true
 	cpu.A = 0x87
-	cpu.Memory[0] = 0xA8
+	cpu.Bus.Write(0, 0xA8)
 	cpu.Exec()
 
 	if cpu.Y != 0x87 {
@@ -4901,7 +4924,7 @@ func TestTSX(t *testing.T) {
 	cpu := NewCPU()
 	cpu.ZFlag = true
 	cpu.SP = 0x87
-	cpu.Memory[0] = 0xBA
+	cpu.Bus.Write(0, 0xBA)
 	cpu.Exec()
 
 	if cpu.X != 0x87 {
@@ -4929,7 +4952,7 @@ func TestTXA(t *testing.T) {
 	cpu := NewCPU()
 	cpu.ZFlag = true
 	cpu.X = 0x87
-	cpu.Memory[0] = 0x8A
+	cpu.Bus.Write(0, 0x8A)
 	cpu.Exec()
 
 	if cpu.A != 0x87 {
@@ -4957,7 +4980,7 @@ func TestTXS(t *testing.T) {
 	cpu := NewCPU()
 	cpu.ZFlag = true
 	cpu.X = 0x87
-	cpu.Memory[0] = 0x9A
+	cpu.Bus.Write(0, 0x9A)
 	cpu.Exec()
 
 	if cpu.SP != 0x87 {
@@ -4985,7 +5008,7 @@ func TestTYA(t *testing.T) {
 	cpu := NewCPU()
 	cpu.ZFlag = true
 	cpu.Y = 0x87
-	cpu.Memory[0] = 0x98
+	cpu.Bus.Write(0, 0x98)
 	cpu.Exec()
 
 	if cpu.A != 0x87 {
@@ -5012,7 +5035,7 @@ func TestTYA(t *testing.T) {
 func TestCLD(t *testing.T) {
 	cpu := NewCPU()
 	cpu.DFlag = true
-	cpu.Memory[0] = 0xD8
+	cpu.Bus.Write(0, 0xD8)
 	cpu.Exec()
 
 	if cpu.PC != 1 {
@@ -5027,3 +5050,727 @@ func TestCLD(t *testing.T) {
 		t.Error("did not clear DFlag")
 	}
 }
+
+func TestReset(t *testing.T) {
+	cpu := NewCPU()
+	cpu.Bus.Write(0xFFFC, 0x04)
+	cpu.Bus.Write(0xFFFD, 0xC0)
+	cpu.PowerOn()
+
+	if cpu.PC != 0xC004 {
+		t.Error("did not load PC from reset vector, got", cpu.PC)
+	}
+
+	if cpu.SP != 0xFD {
+		t.Error("did not correctly set SP, got", cpu.SP)
+	}
+
+	if cpu.Status() != 0x24 {
+		t.Error("did not correctly set flags, got", cpu.Status())
+	}
+
+	if cpu.Cycles != 7 {
+		t.Error("did not correctly update cycles, got", cpu.Cycles)
+	}
+}
+
+func TestNMI(t *testing.T) {
+	cpu := NewCPU()
+	cpu.Bus.Write(0xFFFA, 0x00)
+	cpu.Bus.Write(0xFFFB, 0x90)
+	cpu.Bus.Write(0x1234, 0xEA) // NOP
+	cpu.PC = 0x1234
+	cpu.IFlag = true
+	cpu.CFlag = true
+	cpu.TriggerNMI()
+	cpu.Exec()
+	cpu.Exec()
+
+	if cpu.PC != 0x9000 {
+		t.Error("did not load PC from NMI vector, got", cpu.PC)
+	}
+
+	if cpu.Cycles != 2+7 {
+		t.Error("did not correctly update cycles, got", cpu.Cycles)
+	}
+
+	if popStack(cpu) != 0x25 {
+		t.Error("did not push flags with B clear")
+	}
+
+	if popStack16(cpu) != 0x1235 {
+		t.Error("did not push return address")
+	}
+}
+
+func TestIRQ(t *testing.T) {
+	cpu := NewCPU()
+	cpu.Bus.Write(0xFFFE, 0x00)
+	cpu.Bus.Write(0xFFFF, 0xA0)
+	cpu.Bus.Write(0x0200, 0xEA) // NOP
+	cpu.PC = 0x0200
+	cpu.SetIRQ(IRQExternal, true)
+	cpu.Exec()
+	cpu.Exec()
+
+	if cpu.PC != 0xA000 {
+		t.Error("did not load PC from IRQ vector, got", cpu.PC)
+	}
+
+	if cpu.IFlag != true {
+		t.Error("did not set interrupt disable flag")
+	}
+
+	if popStack(cpu)&0x10 != 0 {
+		t.Error("pushed flags with B set")
+	}
+}
+
+func TestIRQIgnoredWhenDisabled(t *testing.T) {
+	cpu := NewCPU()
+	cpu.PC = 0x0200
+	cpu.Bus.Write(0x0200, 0xEA) // NOP
+	cpu.Bus.Write(0x0201, 0xEA) // NOP
+	cpu.IFlag = true
+	cpu.Exec()
+	cpu.SetIRQ(IRQExternal, true)
+	cpu.Exec()
+
+	if cpu.PC != 0x0202 {
+		t.Error("serviced IRQ while interrupts were disabled, PC is", cpu.PC)
+	}
+}
+
+func TestCLIDelaysIRQByOneInstruction(t *testing.T) {
+	cpu := NewCPU()
+	cpu.Bus.Write(0xFFFE, 0x00)
+	cpu.Bus.Write(0xFFFF, 0xA0)
+	cpu.PC = 0x0200
+	cpu.Bus.Write(0x0200, 0x58) // CLI
+	cpu.Bus.Write(0x0201, 0xEA) // NOP
+	cpu.IFlag = true
+	cpu.SetIRQ(IRQExternal, true)
+	cpu.Exec()
+	cpu.Exec()
+
+	if cpu.PC != 0x0202 {
+		t.Error("did not run the instruction after CLI first, PC is", cpu.PC)
+	}
+
+	cpu.Exec()
+
+	if cpu.PC != 0xA000 {
+		t.Error("did not service IRQ after CLI, PC is", cpu.PC)
+	}
+}
+
+func TestNMIDuringLastCycleIsDelayed(t *testing.T) {
+	cpu := NewCPU()
+	cpu.Bus.Write(0xFFFA, 0x00)
+	cpu.Bus.Write(0xFFFB, 0x90)
+	cpu.Bus.Write(0x0000, 0xEA) // NOP
+	cpu.Bus.Write(0x0001, 0xEA) // NOP
+	cpu.Bus.Write(0x0002, 0xEA) // NOP
+	cpu.Exec()
+	cpu.OnCycle = func() {
+		// assert NMI during the second (last) cycle of the next NOP
+		if cpu.Cycles == 4 {
+			cpu.TriggerNMI()
+		}
+	}
+	cpu.Exec()
+	cpu.Exec()
+
+	if cpu.PC != 0x0003 {
+		t.Error("serviced NMI that arrived during the last cycle, PC is", cpu.PC)
+	}
+
+	cpu.Exec()
+
+	if cpu.PC != 0x9000 {
+		t.Error("did not service delayed NMI, PC is", cpu.PC)
+	}
+}
+
+func TestEveryOpcodeIsImplemented(t *testing.T) {
+	for opcode := 0; opcode < 0x100; opcode++ {
+		if _, ok := Lookup(uint8(opcode)); !ok {
+			t.Errorf("opcode %02X is not implemented", opcode)
+		}
+	}
+}
+
+func TestLAXZeroPage(t *testing.T) {
+	harness := NewCpuTestHarness()
+	harness.Opcode = 0xA7
+	harness.SetupZeroPage()
+	harness.Run()
+
+	if harness.Cpu.A != 0x07 || harness.Cpu.X != 0x07 {
+		t.Error("did not load A and X, got", harness.Cpu.A, harness.Cpu.X)
+	}
+
+	if harness.Cpu.Cycles != 3 {
+		t.Error("did not correctly set cycles")
+	}
+}
+
+func TestSAXAbsolute(t *testing.T) {
+	harness := NewCpuTestHarness()
+	harness.Opcode = 0x8F
+	harness.SetupAbsolute()
+	harness.Cpu.A = 0xF3
+	harness.Cpu.X = 0x3C
+	harness.Run()
+
+	if harness.Cpu.Bus.Read(0x8080) != 0x30 {
+		t.Error("did not store A & X, got", harness.Cpu.Bus.Read(0x8080))
+	}
+
+	if harness.Cpu.Cycles != 4 {
+		t.Error("did not correctly set cycles")
+	}
+}
+
+func TestDCPZeroPage(t *testing.T) {
+	harness := NewCpuTestHarness()
+	harness.Opcode = 0xC7
+	harness.SetupZeroPage()
+	harness.Cpu.A = 0x06
+	harness.Run()
+
+	if harness.Cpu.Bus.Read(0x17) != 0x06 {
+		t.Error("did not decrement memory, got", harness.Cpu.Bus.Read(0x17))
+	}
+
+	if harness.Cpu.ZFlag != true || harness.Cpu.CFlag != true {
+		t.Error("did not compare accumulator with decremented value")
+	}
+
+	if harness.Cpu.Cycles != 5 {
+		t.Error("did not correctly set cycles")
+	}
+}
+
+func TestISBAbsoluteX(t *testing.T) {
+	harness := NewCpuTestHarness()
+	harness.Opcode = 0xFF
+	harness.SetupAbsoluteX()
+	harness.Cpu.A = 0x10
+	harness.Cpu.CFlag = true
+	harness.Run()
+
+	if harness.Cpu.Bus.Read(0xFF81) != 0x08 {
+		t.Error("did not increment memory, got", harness.Cpu.Bus.Read(0xFF81))
+	}
+
+	if harness.Cpu.A != 0x08 {
+		t.Error("did not subtract incremented value, got", harness.Cpu.A)
+	}
+
+	if harness.Cpu.Cycles != 7 {
+		t.Error("did not correctly set cycles")
+	}
+}
+
+func TestSLOIndirectIndexed(t *testing.T) {
+	harness := NewCpuTestHarness()
+	harness.Opcode = 0x13
+	harness.SetupIndirectIndexed()
+	harness.Cpu.A = 0x01
+	harness.Run()
+
+	if harness.Cpu.Bus.Read(6) != 0x0E {
+		t.Error("did not shift memory, got", harness.Cpu.Bus.Read(6))
+	}
+
+	if harness.Cpu.A != 0x0F {
+		t.Error("did not OR shifted value into accumulator, got", harness.Cpu.A)
+	}
+
+	if harness.Cpu.Cycles != 8 {
+		t.Error("did not correctly set cycles")
+	}
+}
+
+func TestANC(t *testing.T) {
+	cpu := NewCPU()
+	cpu.A = 0xF0
+	cpu.Bus.Write(0, 0x0B)
+	cpu.Bus.Write(1, 0x81)
+	cpu.Exec()
+
+	if cpu.A != 0x80 || cpu.CFlag != true || cpu.NFlag != true {
+		t.Error("did not AND and copy N into C")
+	}
+}
+
+func TestALR(t *testing.T) {
+	cpu := NewCPU()
+	cpu.A = 0xFF
+	cpu.Bus.Write(0, 0x4B)
+	cpu.Bus.Write(1, 0x03)
+	cpu.Exec()
+
+	if cpu.A != 0x01 || cpu.CFlag != true {
+		t.Error("did not AND then shift right, got", cpu.A)
+	}
+}
+
+func TestARR(t *testing.T) {
+	cpu := NewCPU()
+	cpu.A = 0xFF
+	cpu.CFlag = true
+	cpu.Bus.Write(0, 0x6B)
+	cpu.Bus.Write(1, 0x80)
+	cpu.Exec()
+
+	if cpu.A != 0xC0 {
+		t.Error("did not AND then rotate right, got", cpu.A)
+	}
+
+	if cpu.CFlag != true || cpu.VFlag != true {
+		t.Error("did not set C and V from bits 6 and 5")
+	}
+}
+
+func TestAXS(t *testing.T) {
+	cpu := NewCPU()
+	cpu.A = 0x0F
+	cpu.X = 0xFC
+	cpu.Bus.Write(0, 0xCB)
+	cpu.Bus.Write(1, 0x02)
+	cpu.Exec()
+
+	if cpu.X != 0x0A || cpu.CFlag != true {
+		t.Error("did not set X to (A & X) - operand, got", cpu.X)
+	}
+}
+
+func TestSHYPageCross(t *testing.T) {
+	cpu := NewCPU()
+	cpu.X = 0x01
+	cpu.Y = 0x7F
+	cpu.Bus.Write(0, 0x9C)
+	cpu.Bus.Write(1, 0xFF)
+	cpu.Bus.Write(2, 0x10)
+	cpu.Exec()
+
+	// Y & (high byte + 1) = 0x7F & 0x11, which also replaces the high
+	// byte of the target address
+	if cpu.Bus.Read(0x1100) != 0x11 {
+		t.Error("did not store Y & (H + 1) to the corrupted address")
+	}
+}
+
+func TestKIL(t *testing.T) {
+	cpu := NewCPU()
+	cpu.Bus.Write(0, 0x02)
+	cpu.Exec()
+	cpu.Exec()
+
+	if cpu.Halted != true {
+		t.Error("did not halt the CPU")
+	}
+
+	if cpu.PC != 0x00 {
+		t.Error("did not stay on the KIL opcode, PC is", cpu.PC)
+	}
+
+	cpu.Reset()
+
+	if cpu.Halted != false {
+		t.Error("reset did not recover from KIL")
+	}
+}
+
+func TestADCCarryWithOperandWrap(t *testing.T) {
+	cpu := NewCPU()
+	cpu.A = 0xFF
+	cpu.CFlag = true
+	cpu.Bus.Write(0, 0x69)
+	cpu.Bus.Write(1, 0xFF)
+	cpu.Exec()
+
+	if cpu.A != 0xFF || cpu.CFlag != true {
+		t.Error("did not carry when operand plus carry wraps, got", cpu.A, cpu.CFlag)
+	}
+}
+
+func withoutOpcode(opcode byte, test func()) {
+	instruction := instructionMap[opcode]
+	delete(instructionMap, opcode)
+	defer func() { instructionMap[opcode] = instruction }()
+
+	test()
+}
+
+func TestUnknownOpcodeHalts(t *testing.T) {
+	withoutOpcode(0x02, func() {
+		cpu := NewCPU()
+		cpu.Bus.Write(0, 0xEA) // NOP
+		cpu.Bus.Write(1, 0x02)
+		cpu.Exec()
+		err := cpu.Exec()
+
+		unknown, ok := err.(*UnknownOpcodeError)
+		if !ok {
+			t.Fatal("did not return UnknownOpcodeError, got", err)
+		}
+
+		if unknown.PC != 0x01 || unknown.Opcode != 0x02 {
+			t.Error("did not record PC and opcode, got", unknown)
+		}
+
+		if len(unknown.History) != 1 || unknown.History[0].Opcode != 0xEA {
+			t.Error("did not record instruction history, got", unknown.History)
+		}
+
+		if cpu.Halted != true {
+			t.Error("did not halt the CPU")
+		}
+	})
+}
+
+func TestUnknownOpcodeSkip(t *testing.T) {
+	withoutOpcode(0x02, func() {
+		cpu := NewCPU()
+		cpu.UnknownOpcodePolicy = SkipUnknownOpcode
+		cpu.Bus.Write(0, 0x02)
+
+		if err := cpu.Exec(); err != nil {
+			t.Error("returned an error when skipping, got", err)
+		}
+
+		if cpu.PC != 0x01 || cpu.Cycles != 2 {
+			t.Error("did not treat the opcode as a NOP")
+		}
+	})
+}
+
+func TestUnknownOpcodeCallback(t *testing.T) {
+	withoutOpcode(0x02, func() {
+		var called *UnknownOpcodeError
+		cpu := NewCPU()
+		cpu.UnknownOpcodePolicy = CallbackOnUnknownOpcode
+		cpu.OnUnknownOpcode = func(cpu *CPU, err *UnknownOpcodeError) error {
+			called = err
+			return nil
+		}
+		cpu.Bus.Write(0, 0x02)
+
+		if err := cpu.Exec(); err != nil {
+			t.Error("returned an error when callback succeeded, got", err)
+		}
+
+		if called == nil || called.Opcode != 0x02 {
+			t.Error("did not call the callback")
+		}
+
+		if cpu.Halted != false {
+			t.Error("halted after callback succeeded")
+		}
+	})
+}
+
+func TestHistoryWrapsAround(t *testing.T) {
+	cpu := NewCPU()
+	for i := 0; i < HistorySize+5; i++ {
+		cpu.Bus.Write(uint16(i), 0xEA)
+	}
+
+	for i := 0; i < HistorySize+5; i++ {
+		cpu.Exec()
+	}
+
+	history := cpu.History()
+	if len(history) != HistorySize {
+		t.Fatal("did not keep a bounded history, got", len(history))
+	}
+
+	if history[0].PC != 5 || history[HistorySize-1].PC != HistorySize+4 {
+		t.Error("did not order history oldest first")
+	}
+}
+
+type busAccess struct {
+	Address uint16
+	Value   byte
+	Write   bool
+}
+
+// recordingBus is flat memory that remembers every access made to it
+type recordingBus struct {
+	Memory
+	accesses []busAccess
+}
+
+func (bus *recordingBus) Read(address uint16) byte {
+	value := bus.Memory.Read(address)
+	bus.accesses = append(bus.accesses, busAccess{address, value, false})
+	return value
+}
+
+func (bus *recordingBus) Write(address uint16, value byte) {
+	bus.accesses = append(bus.accesses, busAccess{address, value, true})
+	bus.Memory.Write(address, value)
+}
+
+func TestInstructionCyclesMatchTable(t *testing.T) {
+	for opcode, instruction := range instructionMap {
+		if instruction.AddressingMode == Relative || instruction.Assembly == "KIL" {
+			continue
+		}
+
+		bus := &recordingBus{}
+		cpu := NewCPU()
+		cpu.Bus = bus
+		cpu.PC = 0x0200
+		bus.Memory[0x0200] = opcode
+		bus.Memory[0x0201] = 0x10
+		bus.Memory[0x0202] = 0x03
+		cpu.Exec()
+
+		if cpu.Cycles != instruction.Cycles {
+			t.Errorf("%s (opcode %02X) took %d cycles, expected %d",
+				instruction.Assembly, opcode, cpu.Cycles, instruction.Cycles)
+		}
+
+		if uint(len(bus.accesses)) != cpu.Cycles {
+			t.Errorf("%s (opcode %02X) made %d bus accesses in %d cycles",
+				instruction.Assembly, opcode, len(bus.accesses), cpu.Cycles)
+		}
+	}
+}
+
+func TestDummyReadOnPageCross(t *testing.T) {
+	bus := &recordingBus{}
+	cpu := NewCPU()
+	cpu.Bus = bus
+	cpu.X = 0x01
+	bus.Memory[0] = 0xBD // LDA $20FF,X
+	bus.Memory[1] = 0xFF
+	bus.Memory[2] = 0x20
+	cpu.Exec()
+
+	expected := []busAccess{
+		{0x0000, 0xBD, false},
+		{0x0001, 0xFF, false},
+		{0x0002, 0x20, false},
+		{0x2000, 0x00, false}, // high byte not fixed up yet
+		{0x2100, 0x00, false},
+	}
+
+	if !equalAccesses(bus.accesses, expected) {
+		t.Error("did not perform the expected bus accesses, got", bus.accesses)
+	}
+}
+
+func TestReadModifyWriteWritesTwice(t *testing.T) {
+	bus := &recordingBus{}
+	cpu := NewCPU()
+	cpu.Bus = bus
+	bus.Memory[0] = 0xEE // INC $2007
+	bus.Memory[1] = 0x07
+	bus.Memory[2] = 0x20
+	bus.Memory[0x2007] = 0x41
+	cpu.Exec()
+
+	expected := []busAccess{
+		{0x0000, 0xEE, false},
+		{0x0001, 0x07, false},
+		{0x0002, 0x20, false},
+		{0x2007, 0x41, false},
+		{0x2007, 0x41, true},
+		{0x2007, 0x42, true},
+	}
+
+	if !equalAccesses(bus.accesses, expected) {
+		t.Error("did not perform the expected bus accesses, got", bus.accesses)
+	}
+}
+
+func TestTickAdvancesOneCycle(t *testing.T) {
+	bus := &recordingBus{}
+	cpu := NewCPU()
+	cpu.Bus = bus
+	bus.Memory[0] = 0xEE // INC $0010
+	bus.Memory[1] = 0x10
+	bus.Memory[2] = 0x00
+	bus.Memory[3] = 0xEA // NOP
+	bus.Memory[4] = 0xEA // NOP
+
+	for cycle := 1; cycle <= 6; cycle++ {
+		if err := cpu.Tick(); err != nil {
+			t.Fatal(err)
+		}
+
+		if cpu.Cycles != uint(cycle) || len(bus.accesses) != cycle {
+			t.Fatalf("tick %d advanced to cycle %d with %d accesses", cycle, cpu.Cycles, len(bus.accesses))
+		}
+	}
+
+	if bus.Memory[0x10] != 0x01 {
+		t.Error("did not complete INC after six ticks")
+	}
+
+	// Exec finishes the instruction started by Tick before running the next
+	cpu.Tick()
+	cpu.Exec()
+
+	if cpu.PC != 0x05 || cpu.Cycles != 10 {
+		t.Error("Exec did not finish the instruction started by Tick, PC is", cpu.PC)
+	}
+}
+
+func equalAccesses(actual []busAccess, expected []busAccess) bool {
+	if len(actual) != len(expected) {
+		return false
+	}
+
+	for i := range actual {
+		if actual[i] != expected[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestStall(t *testing.T) {
+	bus := &recordingBus{}
+	cpu := NewCPU()
+	cpu.Bus = bus
+	bus.Memory[0] = 0xEA // NOP
+	cpu.Stall(2)
+
+	cpu.Exec()
+	cpu.Exec()
+
+	if cpu.Cycles != 2 || cpu.PC != 0 {
+		t.Error("did not stall for two cycles before the next instruction")
+	}
+
+	if bus.accesses[0].Address != 0 || bus.accesses[1].Address != 0 {
+		t.Error("did not repeat the opcode fetch while stalled")
+	}
+
+	cpu.Exec()
+
+	if cpu.Cycles != 4 || cpu.PC != 1 {
+		t.Error("did not execute the NOP after the stall")
+	}
+}
+
+func TestStallDMA(t *testing.T) {
+	bus := &recordingBus{}
+	cpu := NewCPU()
+	cpu.Bus = bus
+	cpu.Stall(3)
+
+	// the DMA takes the bus on the last two cycles
+	dmaCycles := 0
+	cpu.DMA = func() bool {
+		dmaCycles++
+		return dmaCycles > 1
+	}
+
+	cpu.Exec()
+	cpu.Exec()
+	cpu.Exec()
+
+	if cpu.Cycles != 3 || len(bus.accesses) != 1 {
+		t.Error("did not leave the bus to the DMA, the CPU made", len(bus.accesses), "accesses")
+	}
+}
+
+func TestStallMidInstruction(t *testing.T) {
+	bus := &recordingBus{}
+	cpu := NewCPU()
+	cpu.Bus = bus
+	copy(bus.Memory[:], []byte{0xAD, 0x34, 0x12}) // LDA $1234
+
+	// DMA asks for the bus during the second cycle
+	cpu.OnCycle = func() {
+		if cpu.Cycles == 2 {
+			cpu.Stall(2)
+		}
+	}
+	cpu.Exec()
+
+	var addresses []uint16
+	for _, access := range bus.accesses {
+		addresses = append(addresses, access.Address)
+	}
+	want := []uint16{0x0000, 0x0001, 0x0002, 0x0002, 0x0002, 0x1234}
+	if fmt.Sprint(addresses) != fmt.Sprint(want) {
+		t.Error("did not halt on the next read, the CPU read", addresses)
+	}
+}
+
+func TestCPUSaveState(t *testing.T) {
+	cpu := NewCPU()
+	cpu.PC, cpu.A, cpu.X, cpu.Y, cpu.SP = 0x1234, 0x01, 0x02, 0x03, 0xF0
+	cpu.CFlag, cpu.NFlag = true, true
+	cpu.Cycles = 99
+	cpu.Stall(2)
+	cpu.SetIRQ(IRQMapper, true)
+
+	var state bytes.Buffer
+	if err := cpu.SaveState(&state); err != nil {
+		t.Fatal(err)
+	}
+
+	restored := NewCPU()
+	if err := restored.LoadState(&state); err != nil {
+		t.Fatal(err)
+	}
+
+	if restored.PC != 0x1234 || restored.A != 0x01 || restored.X != 0x02 || restored.Y != 0x03 || restored.SP != 0xF0 {
+		t.Error("did not restore the registers")
+	}
+
+	if restored.Status() != cpu.Status() || restored.Cycles != 99 || restored.stall != 2 || restored.irqLine != IRQMapper {
+		t.Error("did not restore the flags, cycles and interrupt state")
+	}
+}
+
+func TestCPUSaveStateDoesNotRun(t *testing.T) {
+	bus := &recordingBus{}
+	cpu := NewCPU()
+	cpu.Bus = bus
+	cpu.PC = 0x0200
+	copy(bus.Memory[0x0200:], []byte{0xE6, 0x10, 0xE8}) // INC $10, INX
+
+	cpu.Tick()
+	cpu.Tick()
+	before, accesses := *cpu, len(bus.accesses)
+
+	var state bytes.Buffer
+	if err := cpu.SaveState(&state); !errors.Is(err, ErrMidInstruction) {
+		t.Error("did not refuse to save part way through INC, got", err)
+	}
+	if cpu.PC != before.PC || cpu.Cycles != before.Cycles || len(bus.accesses) != accesses || bus.Memory[0x10] != 0 {
+		t.Error("did not leave the CPU where Tick stopped it")
+	}
+
+	cpu.Exec()
+	before, accesses = *cpu, len(bus.accesses)
+	if err := cpu.SaveState(&state); err != nil {
+		t.Fatal(err)
+	}
+	if cpu.PC != before.PC || cpu.Cycles != before.Cycles || cpu.X != before.X || cpu.Status() != before.Status() || len(bus.accesses) != accesses {
+		t.Error("saving between instructions changed the CPU")
+	}
+}
+
+func TestCPUString(t *testing.T) {
+	cpu := NewCPU()
+	cpu.PC, cpu.A, cpu.X, cpu.Y, cpu.SP = 0xC000, 0x01, 0x02, 0x03, 0xFD
+	cpu.SetStatus(0x24)
+	cpu.Cycles = 7
+
+	if cpu.String() != "PC:C000 A:01 X:02 Y:03 P:24 SP:FD CYC:7" {
+		t.Error("did not describe the registers, got", cpu.String())
+	}
+}
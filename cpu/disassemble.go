@@ -0,0 +1,205 @@
+package cpu
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Disassembly is an instruction decoded from memory.
+type Disassembly struct {
+	Address     uint16
+	Bytes       []byte      // the opcode followed by the operand
+	Instruction Instruction // Assembly is empty for unknown opcodes
+}
+
+// Disassemble decodes the instruction at address. Memory is peeked when
+// the bus allows it. Opcodes the CPU doesn't implement decode as a
+// single byte.
+func Disassemble(bus Bus, address uint16) Disassembly {
+	opcode := peek(bus, address)
+	instruction, ok := Lookup(opcode)
+
+	size := instruction.Bytes
+	if !ok {
+		instruction, size = Instruction{Opcode: opcode}, 1
+	} else if instruction.AddressingMode == Implied {
+		// BRK skips a padding byte, but it isn't part of the instruction
+		size = 1
+	}
+
+	disassembly := Disassembly{Address: address, Instruction: instruction}
+	for i := uint16(0); i < size; i++ {
+		disassembly.Bytes = append(disassembly.Bytes, peek(bus, address+i))
+	}
+
+	return disassembly
+}
+
+// Next is the address of the instruction that follows this one.
+func (disassembly Disassembly) Next() uint16 {
+	return disassembly.Address + uint16(len(disassembly.Bytes))
+}
+
+// Operand is the byte or word after the opcode. For branches it is the
+// address the branch goes to rather than the offset.
+func (disassembly Disassembly) Operand() uint16 {
+	bytes := disassembly.Bytes
+
+	switch {
+	case len(bytes) == 3:
+		return uint16(bytes[2])<<8 | uint16(bytes[1])
+	case len(bytes) == 2 && disassembly.Instruction.AddressingMode == Relative:
+		return disassembly.Next() + uint16(int8(bytes[1]))
+	case len(bytes) == 2:
+		return uint16(bytes[1])
+	}
+
+	return 0
+}
+
+// Hex is the instruction's bytes in hex, "4C F5 C5".
+func (disassembly Disassembly) Hex() string {
+	hex := make([]string, len(disassembly.Bytes))
+	for i, value := range disassembly.Bytes {
+		hex[i] = fmt.Sprintf("%02X", value)
+	}
+
+	return strings.Join(hex, " ")
+}
+
+// String formats the instruction as assembly, like LDA ($80),Y.
+// Unofficial opcodes are marked with a * like nestest.log does, and
+// unknown opcodes are shown as a .byte directive.
+func (disassembly Disassembly) String() string {
+	return disassembly.Format(nil)
+}
+
+// Format is String with any address that has a label replaced by it.
+func (disassembly Disassembly) Format(labels map[uint16]string) string {
+	instruction := disassembly.Instruction
+	if instruction.Assembly == "" {
+		return fmt.Sprintf(".byte $%02X", disassembly.Bytes[0])
+	}
+
+	mnemonic := instruction.Assembly
+	if instruction.Unofficial {
+		mnemonic = "*" + mnemonic
+	}
+
+	operand := disassembly.Operand()
+	address := func(digits int) string {
+		if label, ok := labels[operand]; ok {
+			return label
+		}
+		return fmt.Sprintf("$%0*X", digits, operand)
+	}
+
+	switch instruction.AddressingMode {
+	case Accumulator:
+		return mnemonic + " A"
+	case Immediate:
+		return fmt.Sprintf("%s #$%02X", mnemonic, operand)
+	case ZeroPage:
+		return mnemonic + " " + address(2)
+	case ZeroPageX:
+		return mnemonic + " " + address(2) + ",X"
+	case ZeroPageY:
+		return mnemonic + " " + address(2) + ",Y"
+	case Absolute, Relative:
+		return mnemonic + " " + address(4)
+	case AbsoluteX:
+		return mnemonic + " " + address(4) + ",X"
+	case AbsoluteY:
+		return mnemonic + " " + address(4) + ",Y"
+	case Indirect:
+		return mnemonic + " (" + address(4) + ")"
+	case IndexedIndirect:
+		return mnemonic + " (" + address(2) + ",X)"
+	case IndirectIndexed:
+		return mnemonic + " (" + address(2) + "),Y"
+	}
+
+	return mnemonic
+}
+
+// Listing is a line of a nestest.log style listing, the address, the
+// bytes and the assembly, with the * of an unofficial opcode sitting in
+// the column before the mnemonic:
+//
+//	C000  4C F5 C5  JMP $C5F5
+//	C6BD  04 A9    *NOP $A9
+func (disassembly Disassembly) Listing(labels map[uint16]string) string {
+	text := disassembly.Format(labels)
+	if !disassembly.Instruction.Unofficial {
+		text = " " + text
+	}
+
+	return fmt.Sprintf("%04X  %-8s %s", disassembly.Address, disassembly.Hex(), text)
+}
+
+// EffectiveAddress works out the address an instruction is about to
+// read or write, from the registers as they are before it runs. ok is
+// false for instructions without a memory operand, and for JMP and JSR,
+// whose operand is where they go rather than data.
+func (cpu *CPU) EffectiveAddress(disassembly Disassembly) (address uint16, ok bool) {
+	operand := disassembly.Operand()
+	pointer := func(address byte) uint16 {
+		return uint16(peek(cpu.Bus, uint16(address+1)))<<8 | uint16(peek(cpu.Bus, uint16(address)))
+	}
+
+	switch disassembly.Instruction.AddressingMode {
+	case ZeroPage:
+		return operand, true
+	case ZeroPageX:
+		return uint16(byte(operand) + cpu.X), true
+	case ZeroPageY:
+		return uint16(byte(operand) + cpu.Y), true
+	case Absolute:
+		assembly := disassembly.Instruction.Assembly
+		return operand, assembly != "JMP" && assembly != "JSR"
+	case AbsoluteX:
+		return operand + uint16(cpu.X), true
+	case AbsoluteY:
+		return operand + uint16(cpu.Y), true
+	case IndexedIndirect:
+		return pointer(byte(operand) + cpu.X), true
+	case IndirectIndexed:
+		return pointer(byte(operand)) + uint16(cpu.Y), true
+	}
+
+	return 0, false
+}
+
+// Annotate describes the memory an instruction is about to use, the way
+// nestest.log does. It is "= 5A" for the value at an address given in
+// the operand, "@ 0300 = 5A" for an effective address and its value, and
+// indirect addressing shows the pointer too. Call it before the
+// instruction runs, it uses the registers as they are.
+func (cpu *CPU) Annotate(disassembly Disassembly) string {
+	operand := disassembly.Operand()
+
+	if disassembly.Instruction.AddressingMode == Indirect {
+		// the high byte comes from the same page, like the CPU's JMP bug
+		high := operand&0xFF00 | (operand+1)&0x00FF
+		return fmt.Sprintf("= %04X", uint16(peek(cpu.Bus, high))<<8|uint16(peek(cpu.Bus, operand)))
+	}
+
+	address, ok := cpu.EffectiveAddress(disassembly)
+	if !ok {
+		return ""
+	}
+	value := peek(cpu.Bus, address)
+
+	switch disassembly.Instruction.AddressingMode {
+	case ZeroPageX, ZeroPageY:
+		return fmt.Sprintf("@ %02X = %02X", address, value)
+	case AbsoluteX, AbsoluteY:
+		return fmt.Sprintf("@ %04X = %02X", address, value)
+	case IndexedIndirect:
+		return fmt.Sprintf("@ %02X = %04X = %02X", byte(operand)+cpu.X, address, value)
+	case IndirectIndexed:
+		return fmt.Sprintf("= %04X @ %04X = %02X", address-uint16(cpu.Y), address, value)
+	}
+
+	return fmt.Sprintf("= %02X", value)
+}
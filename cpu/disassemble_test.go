@@ -0,0 +1,90 @@
+package cpu
+
+import "testing"
+
+func TestDisassembleAddressingModes(t *testing.T) {
+	for expected, code := range map[string][]byte{
+		"BRK":         {0x00, 0xFF},
+		"LSR A":       {0x4A},
+		"LDA #$10":    {0xA9, 0x10},
+		"STA $10":     {0x85, 0x10},
+		"STY $10,X":   {0x94, 0x10},
+		"LDX $10,Y":   {0xB6, 0x10},
+		"JMP $C5F5":   {0x4C, 0xF5, 0xC5},
+		"LDA $0300,X": {0xBD, 0x00, 0x03},
+		"LDA $0300,Y": {0xB9, 0x00, 0x03},
+		"JMP ($02FF)": {0x6C, 0xFF, 0x02},
+		"LDA ($80,X)": {0xA1, 0x80},
+		"LDA ($80),Y": {0xB1, 0x80},
+		"BNE $0FFE":   {0xD0, 0xFC},
+		"*NOP $A9A9":  {0x0C, 0xA9, 0xA9},
+	} {
+		memory := &Memory{}
+		copy(memory[0x1000:], code)
+
+		disassembly := Disassemble(memory, 0x1000)
+		if disassembly.String() != expected {
+			t.Error("did not disassemble", expected, "got", disassembly.String())
+		}
+		if expected != "BRK" && len(disassembly.Bytes) != len(code) {
+			t.Error("did not decode", len(code), "bytes for", expected)
+		}
+	}
+}
+
+func TestDisassembleListing(t *testing.T) {
+	memory := &Memory{}
+	copy(memory[0xC000:], []byte{0x4C, 0xF5, 0xC5, 0x04, 0xA9})
+
+	if listing := Disassemble(memory, 0xC000).Listing(nil); listing != "C000  4C F5 C5  JMP $C5F5" {
+		t.Error("did not list JMP, got", listing)
+	}
+	if listing := Disassemble(memory, 0xC003).Listing(nil); listing != "C003  04 A9    *NOP $A9" {
+		t.Error("did not list the unofficial NOP, got", listing)
+	}
+
+	labels := map[uint16]string{0xC5F5: "start"}
+	if listing := Disassemble(memory, 0xC000).Listing(labels); listing != "C000  4C F5 C5  JMP start" {
+		t.Error("did not use the label, got", listing)
+	}
+}
+
+func TestAnnotate(t *testing.T) {
+	cpu := NewCPU()
+	memory := &Memory{}
+	cpu.Bus = memory
+	cpu.X, cpu.Y = 0x02, 0x04
+
+	memory[0x0010] = 0x5A
+	memory[0x0012] = 0x34
+	memory[0x0014] = 0x78
+	memory[0x0300] = 0x9A
+	memory[0x0302] = 0xBC
+	memory[0x0304] = 0xDE
+	memory[0x0080], memory[0x0081] = 0x00, 0x03
+	memory[0x0082], memory[0x0083] = 0x02, 0x03
+	memory[0x02FF], memory[0x0200] = 0x00, 0x03
+
+	for _, test := range []struct {
+		code     []byte
+		expected string
+	}{
+		{[]byte{0xA9, 0x10}, ""},
+		{[]byte{0x85, 0x10}, "= 5A"},
+		{[]byte{0xB5, 0x10}, "@ 12 = 34"},
+		{[]byte{0xB6, 0x10}, "@ 14 = 78"},
+		{[]byte{0xAD, 0x00, 0x03}, "= 9A"},
+		{[]byte{0x20, 0x00, 0x03}, ""},
+		{[]byte{0xBD, 0x00, 0x03}, "@ 0302 = BC"},
+		{[]byte{0xB9, 0x00, 0x03}, "@ 0304 = DE"},
+		{[]byte{0x6C, 0xFF, 0x02}, "= 0300"},
+		{[]byte{0xA1, 0x80}, "@ 82 = 0302 = BC"},
+		{[]byte{0xB1, 0x80}, "= 0300 @ 0304 = DE"},
+	} {
+		copy(memory[0x1000:], test.code)
+
+		if annotation := cpu.Annotate(Disassemble(memory, 0x1000)); annotation != test.expected {
+			t.Errorf("did not annotate %02X as %q, got %q", test.code, test.expected, annotation)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package cpu
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNoTrap is returned by Flat.Run when the program is still going after
+// the cycles it was given.
+var ErrNoTrap = errors.New("the program didn't trap")
+
+// Flat is a 6502 on its own with 64KB of RAM and nothing else, the
+// machine generic 6502 test programs like Klaus Dormann's are written
+// for. It has no decimal mode, like the NES's CPU, so those tests have
+// to be assembled with decimal mode testing turned off.
+type Flat struct {
+	CPU    *CPU
+	Memory *Memory
+
+	// InterruptPort, when not zero, is the address of a feedback
+	// register for interrupt tests. Bit 0 of the value written to it
+	// drives IRQ and setting bit 1 triggers an NMI, which is how Klaus
+	// Dormann's interrupt test works when assembled with I_drive = 0.
+	InterruptPort uint16
+}
+
+// NewFlat loads program into RAM at address and starts the CPU at pc.
+// Nothing else in memory is set, the reset vector included.
+func NewFlat(program []byte, address uint16, pc uint16) *Flat {
+	flat := &Flat{CPU: NewCPU(), Memory: &Memory{}}
+	copy(flat.Memory[address:], program)
+
+	flat.CPU.Bus = &flatBus{flat}
+	flat.CPU.PC = pc
+	flat.CPU.SetStatus(0x24)
+
+	return flat
+}
+
+// Run runs the program until it traps, with an instruction that branches
+// or jumps to itself, and returns the trap's address. Test programs trap
+// when they finish and when a test fails, so the address says which
+// happened. ErrNoTrap is returned if that takes more than maxCycles.
+func (flat *Flat) Run(maxCycles uint) (trap uint16, err error) {
+	cpu := flat.CPU
+
+	for cpu.Cycles < maxCycles {
+		pc := cpu.PC
+		if err := cpu.Exec(); err != nil {
+			return pc, err
+		}
+
+		if cpu.Halted {
+			return pc, fmt.Errorf("the CPU halted at $%04X", pc)
+		}
+		if cpu.PC == pc && !cpu.nmiService && !cpu.irqService {
+			return pc, nil
+		}
+	}
+
+	return cpu.PC, fmt.Errorf("%w in %d cycles, PC is $%04X", ErrNoTrap, maxCycles, cpu.PC)
+}
+
+// flatBus is RAM with the interrupt feedback register
+type flatBus struct {
+	flat *Flat
+}
+
+func (bus *flatBus) Read(address uint16) byte {
+	return bus.flat.Memory[address]
+}
+
+func (bus *flatBus) Write(address uint16, value byte) {
+	flat := bus.flat
+	if address == flat.InterruptPort && address != 0 {
+		flat.CPU.SetIRQ(IRQExternal, value&0x01 != 0)
+		if value&0x02 != 0 && flat.Memory[address]&0x02 == 0 {
+			flat.CPU.TriggerNMI()
+		}
+	}
+
+	flat.Memory[address] = value
+}
+
+func (bus *flatBus) Peek(address uint16) byte {
+	return bus.flat.Memory[address]
+}
@@ -0,0 +1,159 @@
+package cpu
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func newTestFlat(t *testing.T, source string) (*Flat, *Program) {
+	program, err := Assemble(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flat := NewFlat(program.Image(0, 0x10000), 0, program.Start())
+	flat.InterruptPort = 0xBFFC
+
+	return flat, program
+}
+
+func TestFlatTrap(t *testing.T) {
+	flat, program := newTestFlat(t, `
+		.org $0400
+		LDX #$05
+loop:	DEX
+		BNE loop
+		CPX #$00
+fail:	BNE fail
+done:	JMP done
+	`)
+
+	trap, err := flat.Run(1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if trap != program.Labels["done"] {
+		t.Errorf("did not trap at the JMP to itself, trapped at $%04X", trap)
+	}
+}
+
+func TestFlatBranchTrap(t *testing.T) {
+	flat, program := newTestFlat(t, `
+		.org $0400
+		LDA #$01
+		CMP #$02
+fail:	BNE fail
+done:	JMP done
+	`)
+
+	if trap, err := flat.Run(1000); err != nil || trap != program.Labels["fail"] {
+		t.Errorf("did not trap at the branch to itself, trapped at $%04X", trap)
+	}
+}
+
+func TestFlatNoTrap(t *testing.T) {
+	flat, _ := newTestFlat(t, `
+		.org $0400
+loop:	NOP
+		JMP loop
+	`)
+
+	if _, err := flat.Run(1000); !errors.Is(err, ErrNoTrap) {
+		t.Error("did not give up on a program that doesn't trap, got", err)
+	}
+	if flat.CPU.Cycles < 1000 {
+		t.Error("did not run for the cycles it was given")
+	}
+}
+
+func TestFlatInterruptPort(t *testing.T) {
+	flat, program := newTestFlat(t, `
+		.org $0400
+		CLI
+		LDA #$01
+		STA $BFFC	; IRQ
+		LDA #$02
+		STA $BFFC	; NMI
+done:	JMP done
+
+irq:	INC $10
+		LDX #$00
+		STX $BFFC
+		RTI
+nmi:	INC $11
+		RTI
+
+		.org $FFFA
+		.word nmi, $0400, irq
+	`)
+
+	if trap, err := flat.Run(1000); err != nil || trap != program.Labels["done"] {
+		t.Fatalf("did not trap after the interrupts, trapped at $%04X: %v", trap, err)
+	}
+	if flat.Memory[0x10] != 1 || flat.Memory[0x11] != 1 {
+		t.Errorf("did not take one IRQ and one NMI, got %d and %d", flat.Memory[0x10], flat.Memory[0x11])
+	}
+}
+
+// klausDormannTests are the binaries from Klaus Dormann's 6502 test
+// suite, the whole 64KB image starting at $0000, and the environment
+// variable holding the address of each one's success trap. The traps move
+// with the build settings, and the builds this CPU can pass aren't the
+// defaults, so the address has to be read from the build's listing.
+var klausDormannTests = []struct {
+	file string
+	env  string
+	port uint16
+}{
+	{"6502_functional_test.bin", "KLAUS_FUNCTIONAL_SUCCESS", 0},
+	{"6502_interrupt_test.bin", "KLAUS_INTERRUPT_SUCCESS", 0xBFFC},
+}
+
+// TestKlausDormann runs the tests in the directory named by
+// KLAUS_DORMANN_TESTS, from $0400. The functional test has to be
+// assembled with disable_decimal = 1 and the interrupt test with
+// I_drive = 0, and their success traps given in hex in
+// KLAUS_FUNCTIONAL_SUCCESS and KLAUS_INTERRUPT_SUCCESS. It is skipped
+// when KLAUS_DORMANN_TESTS isn't set, and each test when its address
+// isn't.
+func TestKlausDormann(t *testing.T) {
+	dir := os.Getenv("KLAUS_DORMANN_TESTS")
+	if dir == "" {
+		t.Skip("KLAUS_DORMANN_TESTS is not set")
+	}
+
+	for _, test := range klausDormannTests {
+		t.Run(test.file, func(t *testing.T) {
+			image, err := os.ReadFile(filepath.Join(dir, test.file))
+			if os.IsNotExist(err) {
+				t.Skip("there is no", test.file)
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			address := os.Getenv(test.env)
+			if address == "" {
+				t.Skip(test.env, "is not set")
+			}
+			value, err := strconv.ParseUint(address, 16, 16)
+			if err != nil {
+				t.Fatalf("%s is %q, not an address in hex", test.env, address)
+			}
+			success := uint16(value)
+
+			flat := NewFlat(image, 0x0000, 0x0400)
+			flat.InterruptPort = test.port
+
+			trap, err := flat.Run(200_000_000)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if trap != success {
+				t.Errorf("trapped at $%04X, the success trap is $%04X", trap, success)
+			}
+		})
+	}
+}
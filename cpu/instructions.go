@@ -0,0 +1,3163 @@
+package cpu
+
+type AddressingMode uint8
+
+const (
+	_               AddressingMode = iota
+	Absolute                       // 1
+	AbsoluteX                      // 2
+	AbsoluteY                      // 3
+	Accumulator                    // 4
+	Immediate                      // 5
+	Implied                        // 6
+	IndexedIndirect                // 7
+	Indirect                       // 8
+	IndirectIndexed                // 9
+	Relative                       // 10
+	ZeroPage                       // 11
+	ZeroPageX                      // 12
+	ZeroPageY                      // 13
+)
+
+type Instruction struct {
+	Bytes               uint16
+	Cycles              uint
+	AddCycleOnPageCross bool
+	AddressingMode      AddressingMode
+	Assembly            string
+	Unofficial          bool // not part of the documented 6502 instruction set
+	Opcode              byte
+	exec                func(*CPU, *instructionContext)
+}
+
+type instructionContext struct {
+	PageCrossed    bool
+	Address        uint16
+	AddressingMode AddressingMode
+}
+
+// Lookup returns the instruction for an opcode. The KIL opcodes are
+// included, ok is only false for opcodes the CPU doesn't implement.
+func Lookup(opcode byte) (instruction Instruction, ok bool) {
+	instruction, ok = instructionMap[opcode]
+	return instruction, ok && instruction.exec != nil
+}
+
+var instructionMap = map[uint8]Instruction{
+	0x00: Instruction{
+		Bytes:               2,
+		Cycles:              7,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Implied,
+		Assembly:            "BRK",
+		Unofficial:          false,
+		Opcode:              0x00,
+		exec:                brk,
+	},
+	0x01: Instruction{
+		Bytes:               2,
+		Cycles:              6,
+		AddCycleOnPageCross: false,
+		AddressingMode:      IndexedIndirect,
+		Assembly:            "ORA",
+		Unofficial:          false,
+		Opcode:              0x01,
+		exec:                ora,
+	},
+	0x02: Instruction{
+		Bytes:               1,
+		Cycles:              2,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Implied,
+		Assembly:            "KIL",
+		Unofficial:          true,
+		Opcode:              0x02,
+		exec:                kil,
+	},
+	0x03: Instruction{
+		Bytes:               2,
+		Cycles:              8,
+		AddCycleOnPageCross: false,
+		AddressingMode:      IndexedIndirect,
+		Assembly:            "SLO",
+		Unofficial:          true,
+		Opcode:              0x03,
+		exec:                slo,
+	},
+	0x04: Instruction{
+		Bytes:               2,
+		Cycles:              3,
+		AddCycleOnPageCross: false,
+		AddressingMode:      ZeroPage,
+		Assembly:            "NOP",
+		Unofficial:          true,
+		Opcode:              0x04,
+		exec:                nop,
+	},
+	0x05: Instruction{
+		Bytes:               2,
+		Cycles:              3,
+		AddCycleOnPageCross: false,
+		AddressingMode:      ZeroPage,
+		Assembly:            "ORA",
+		Unofficial:          false,
+		Opcode:              0x05,
+		exec:                ora,
+	},
+	0x06: Instruction{
+		Bytes:               2,
+		Cycles:              5,
+		AddCycleOnPageCross: false,
+		AddressingMode:      ZeroPage,
+		Assembly:            "ASL",
+		Unofficial:          false,
+		Opcode:              0x06,
+		exec:                asl,
+	},
+	0x07: Instruction{
+		Bytes:               2,
+		Cycles:              5,
+		AddCycleOnPageCross: false,
+		AddressingMode:      ZeroPage,
+		Assembly:            "SLO",
+		Unofficial:          true,
+		Opcode:              0x07,
+		exec:                slo,
+	},
+	0x08: Instruction{
+		Bytes:               1,
+		Cycles:              3,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Implied,
+		Assembly:            "PHP",
+		Unofficial:          false,
+		Opcode:              0x08,
+		exec:                php,
+	},
+	0x09: Instruction{
+		Bytes:               2,
+		Cycles:              2,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Immediate,
+		Assembly:            "ORA",
+		Unofficial:          false,
+		Opcode:              0x09,
+		exec:                ora,
+	},
+	0x0A: Instruction{
+		Bytes:               1,
+		Cycles:              2,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Accumulator,
+		Assembly:            "ASL",
+		Unofficial:          false,
+		Opcode:              0x0A,
+		exec:                asl,
+	},
+	0x0B: Instruction{
+		Bytes:               2,
+		Cycles:              2,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Immediate,
+		Assembly:            "ANC",
+		Unofficial:          true,
+		Opcode:              0x0B,
+		exec:                anc,
+	},
+	0x0C: Instruction{
+		Bytes:               3,
+		Cycles:              4,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Absolute,
+		Assembly:            "NOP",
+		Unofficial:          true,
+		Opcode:              0x0C,
+		exec:                nop,
+	},
+	0x0D: Instruction{
+		Bytes:               3,
+		Cycles:              4,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Absolute,
+		Assembly:            "ORA",
+		Unofficial:          false,
+		Opcode:              0x0D,
+		exec:                ora,
+	},
+	0x0E: Instruction{
+		Bytes:               3,
+		Cycles:              6,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Absolute,
+		Assembly:            "ASL",
+		Unofficial:          false,
+		Opcode:              0x0E,
+		exec:                asl,
+	},
+	0x0F: Instruction{
+		Bytes:               3,
+		Cycles:              6,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Absolute,
+		Assembly:            "SLO",
+		Unofficial:          true,
+		Opcode:              0x0F,
+		exec:                slo,
+	},
+	0x10: Instruction{
+		Bytes:               2,
+		Cycles:              2,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Relative,
+		Assembly:            "BPL",
+		Unofficial:          false,
+		Opcode:              0x10,
+		exec:                bpl,
+	},
+	0x11: Instruction{
+		Bytes:               2,
+		Cycles:              5,
+		AddCycleOnPageCross: true,
+		AddressingMode:      IndirectIndexed,
+		Assembly:            "ORA",
+		Unofficial:          false,
+		Opcode:              0x11,
+		exec:                ora,
+	},
+	0x12: Instruction{
+		Bytes:               1,
+		Cycles:              2,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Implied,
+		Assembly:            "KIL",
+		Unofficial:          true,
+		Opcode:              0x12,
+		exec:                kil,
+	},
+	0x13: Instruction{
+		Bytes:               2,
+		Cycles:              8,
+		AddCycleOnPageCross: false,
+		AddressingMode:      IndirectIndexed,
+		Assembly:            "SLO",
+		Unofficial:          true,
+		Opcode:              0x13,
+		exec:                slo,
+	},
+	0x14: Instruction{
+		Bytes:               2,
+		Cycles:              4,
+		AddCycleOnPageCross: false,
+		AddressingMode:      ZeroPageX,
+		Assembly:            "NOP",
+		Unofficial:          true,
+		Opcode:              0x14,
+		exec:                nop,
+	},
+	0x15: Instruction{
+		Bytes:               2,
+		Cycles:              4,
+		AddCycleOnPageCross: false,
+		AddressingMode:      ZeroPageX,
+		Assembly:            "ORA",
+		Unofficial:          false,
+		Opcode:              0x15,
+		exec:                ora,
+	},
+	0x16: Instruction{
+		Bytes:               2,
+		Cycles:              6,
+		AddCycleOnPageCross: false,
+		AddressingMode:      ZeroPageX,
+		Assembly:            "ASL",
+		Unofficial:          false,
+		Opcode:              0x16,
+		exec:                asl,
+	},
+	0x17: Instruction{
+		Bytes:               2,
+		Cycles:              6,
+		AddCycleOnPageCross: false,
+		AddressingMode:      ZeroPageX,
+		Assembly:            "SLO",
+		Unofficial:          true,
+		Opcode:              0x17,
+		exec:                slo,
+	},
+	0x18: Instruction{
+		Bytes:               1,
+		Cycles:              2,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Implied,
+		Assembly:            "CLC",
+		Unofficial:          false,
+		Opcode:              0x18,
+		exec:                clc,
+	},
+	0x19: Instruction{
+		Bytes:               3,
+		Cycles:              4,
+		AddCycleOnPageCross: true,
+		AddressingMode:      AbsoluteY,
+		Assembly:            "ORA",
+		Unofficial:          false,
+		Opcode:              0x19,
+		exec:                ora,
+	},
+	0x1A: Instruction{
+		Bytes:               1,
+		Cycles:              2,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Implied,
+		Assembly:            "NOP",
+		Unofficial:          true,
+		Opcode:              0x1A,
+		exec:                nop,
+	},
+	0x1B: Instruction{
+		Bytes:               3,
+		Cycles:              7,
+		AddCycleOnPageCross: false,
+		AddressingMode:      AbsoluteY,
+		Assembly:            "SLO",
+		Unofficial:          true,
+		Opcode:              0x1B,
+		exec:                slo,
+	},
+	0x1C: Instruction{
+		Bytes:               3,
+		Cycles:              4,
+		AddCycleOnPageCross: true,
+		AddressingMode:      AbsoluteX,
+		Assembly:            "NOP",
+		Unofficial:          true,
+		Opcode:              0x1C,
+		exec:                nop,
+	},
+	0x1D: Instruction{
+		Bytes:               3,
+		Cycles:              4,
+		AddCycleOnPageCross: true,
+		AddressingMode:      AbsoluteX,
+		Assembly:            "ORA",
+		Unofficial:          false,
+		Opcode:              0x1D,
+		exec:                ora,
+	},
+	0x1E: Instruction{
+		Bytes:               3,
+		Cycles:              7,
+		AddCycleOnPageCross: false,
+		AddressingMode:      AbsoluteX,
+		Assembly:            "ASL",
+		Unofficial:          false,
+		Opcode:              0x1E,
+		exec:                asl,
+	},
+	0x1F: Instruction{
+		Bytes:               3,
+		Cycles:              7,
+		AddCycleOnPageCross: false,
+		AddressingMode:      AbsoluteX,
+		Assembly:            "SLO",
+		Unofficial:          true,
+		Opcode:              0x1F,
+		exec:                slo,
+	},
+	0x20: Instruction{
+		Bytes:               3,
+		Cycles:              6,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Absolute,
+		Assembly:            "JSR",
+		Unofficial:          false,
+		Opcode:              0x20,
+		exec:                jsr,
+	},
+	0x21: Instruction{
+		Bytes:               2,
+		Cycles:              6,
+		AddCycleOnPageCross: false,
+		AddressingMode:      IndexedIndirect,
+		Assembly:            "AND",
+		Unofficial:          false,
+		Opcode:              0x21,
+		exec:                and,
+	},
+	0x22: Instruction{
+		Bytes:               1,
+		Cycles:              2,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Implied,
+		Assembly:            "KIL",
+		Unofficial:          true,
+		Opcode:              0x22,
+		exec:                kil,
+	},
+	0x23: Instruction{
+		Bytes:               2,
+		Cycles:              8,
+		AddCycleOnPageCross: false,
+		AddressingMode:      IndexedIndirect,
+		Assembly:            "RLA",
+		Unofficial:          true,
+		Opcode:              0x23,
+		exec:                rla,
+	},
+	0x24: Instruction{
+		Bytes:               2,
+		Cycles:              3,
+		AddCycleOnPageCross: false,
+		AddressingMode:      ZeroPage,
+		Assembly:            "BIT",
+		Unofficial:          false,
+		Opcode:              0x24,
+		exec:                bit,
+	},
+	0x25: Instruction{
+		Bytes:               2,
+		Cycles:              3,
+		AddCycleOnPageCross: false,
+		AddressingMode:      ZeroPage,
+		Assembly:            "AND",
+		Unofficial:          false,
+		Opcode:              0x25,
+		exec:                and,
+	},
+	0x26: Instruction{
+		Bytes:               2,
+		Cycles:              5,
+		AddCycleOnPageCross: false,
+		AddressingMode:      ZeroPage,
+		Assembly:            "ROL",
+		Unofficial:          false,
+		Opcode:              0x26,
+		exec:                rol,
+	},
+	0x27: Instruction{
+		Bytes:               2,
+		Cycles:              5,
+		AddCycleOnPageCross: false,
+		AddressingMode:      ZeroPage,
+		Assembly:            "RLA",
+		Unofficial:          true,
+		Opcode:              0x27,
+		exec:                rla,
+	},
+	0x28: Instruction{
+		Bytes:               1,
+		Cycles:              4,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Implied,
+		Assembly:            "PLP",
+		Unofficial:          false,
+		Opcode:              0x28,
+		exec:                plp,
+	},
+	0x29: Instruction{
+		Bytes:               2,
+		Cycles:              2,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Immediate,
+		Assembly:            "AND",
+		Unofficial:          false,
+		Opcode:              0x29,
+		exec:                and,
+	},
+	0x2A: Instruction{
+		Bytes:               1,
+		Cycles:              2,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Accumulator,
+		Assembly:            "ROL",
+		Unofficial:          false,
+		Opcode:              0x2A,
+		exec:                rol,
+	},
+	0x2B: Instruction{
+		Bytes:               2,
+		Cycles:              2,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Immediate,
+		Assembly:            "ANC",
+		Unofficial:          true,
+		Opcode:              0x2B,
+		exec:                anc,
+	},
+	0x2C: Instruction{
+		Bytes:               3,
+		Cycles:              4,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Absolute,
+		Assembly:            "BIT",
+		Unofficial:          false,
+		Opcode:              0x2C,
+		exec:                bit,
+	},
+	0x2D: Instruction{
+		Bytes:               3,
+		Cycles:              4,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Absolute,
+		Assembly:            "AND",
+		Unofficial:          false,
+		Opcode:              0x2D,
+		exec:                and,
+	},
+	0x2E: Instruction{
+		Bytes:               3,
+		Cycles:              6,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Absolute,
+		Assembly:            "ROL",
+		Unofficial:          false,
+		Opcode:              0x2E,
+		exec:                rol,
+	},
+	0x2F: Instruction{
+		Bytes:               3,
+		Cycles:              6,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Absolute,
+		Assembly:            "RLA",
+		Unofficial:          true,
+		Opcode:              0x2F,
+		exec:                rla,
+	},
+	0x30: Instruction{
+		Bytes:               2,
+		Cycles:              2,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Relative,
+		Assembly:            "BMI",
+		Unofficial:          false,
+		Opcode:              0x30,
+		exec:                bmi,
+	},
+	0x31: Instruction{
+		Bytes:               2,
+		Cycles:              5,
+		AddCycleOnPageCross: true,
+		AddressingMode:      IndirectIndexed,
+		Assembly:            "AND",
+		Unofficial:          false,
+		Opcode:              0x31,
+		exec:                and,
+	},
+	0x32: Instruction{
+		Bytes:               1,
+		Cycles:              2,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Implied,
+		Assembly:            "KIL",
+		Unofficial:          true,
+		Opcode:              0x32,
+		exec:                kil,
+	},
+	0x33: Instruction{
+		Bytes:               2,
+		Cycles:              8,
+		AddCycleOnPageCross: false,
+		AddressingMode:      IndirectIndexed,
+		Assembly:            "RLA",
+		Unofficial:          true,
+		Opcode:              0x33,
+		exec:                rla,
+	},
+	0x34: Instruction{
+		Bytes:               2,
+		Cycles:              4,
+		AddCycleOnPageCross: false,
+		AddressingMode:      ZeroPageX,
+		Assembly:            "NOP",
+		Unofficial:          true,
+		Opcode:              0x34,
+		exec:                nop,
+	},
+	0x35: Instruction{
+		Bytes:               2,
+		Cycles:              4,
+		AddCycleOnPageCross: false,
+		AddressingMode:      ZeroPageX,
+		Assembly:            "AND",
+		Unofficial:          false,
+		Opcode:              0x35,
+		exec:                and,
+	},
+	0x36: Instruction{
+		Bytes:               2,
+		Cycles:              6,
+		AddCycleOnPageCross: false,
+		AddressingMode:      ZeroPageX,
+		Assembly:            "ROL",
+		Unofficial:          false,
+		Opcode:              0x36,
+		exec:                rol,
+	},
+	0x37: Instruction{
+		Bytes:               2,
+		Cycles:              6,
+		AddCycleOnPageCross: false,
+		AddressingMode:      ZeroPageX,
+		Assembly:            "RLA",
+		Unofficial:          true,
+		Opcode:              0x37,
+		exec:                rla,
+	},
+	0x38: Instruction{
+		Bytes:               1,
+		Cycles:              2,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Implied,
+		Assembly:            "SEC",
+		Unofficial:          false,
+		Opcode:              0x38,
+		exec:                sec,
+	},
+	0x39: Instruction{
+		Bytes:               3,
+		Cycles:              4,
+		AddCycleOnPageCross: true,
+		AddressingMode:      AbsoluteY,
+		Assembly:            "AND",
+		Unofficial:          false,
+		Opcode:              0x39,
+		exec:                and,
+	},
+	0x3A: Instruction{
+		Bytes:               1,
+		Cycles:              2,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Implied,
+		Assembly:            "NOP",
+		Unofficial:          true,
+		Opcode:              0x3A,
+		exec:                nop,
+	},
+	0x3B: Instruction{
+		Bytes:               3,
+		Cycles:              7,
+		AddCycleOnPageCross: false,
+		AddressingMode:      AbsoluteY,
+		Assembly:            "RLA",
+		Unofficial:          true,
+		Opcode:              0x3B,
+		exec:                rla,
+	},
+	0x3C: Instruction{
+		Bytes:               3,
+		Cycles:              4,
+		AddCycleOnPageCross: true,
+		AddressingMode:      AbsoluteX,
+		Assembly:            "NOP",
+		Unofficial:          true,
+		Opcode:              0x3C,
+		exec:                nop,
+	},
+	0x3D: Instruction{
+		Bytes:               3,
+		Cycles:              4,
+		AddCycleOnPageCross: true,
+		AddressingMode:      AbsoluteX,
+		Assembly:            "AND",
+		Unofficial:          false,
+		Opcode:              0x3D,
+		exec:                and,
+	},
+	0x3E: Instruction{
+		Bytes:               3,
+		Cycles:              7,
+		AddCycleOnPageCross: false,
+		AddressingMode:      AbsoluteX,
+		Assembly:            "ROL",
+		Unofficial:          false,
+		Opcode:              0x3E,
+		exec:                rol,
+	},
+	0x3F: Instruction{
+		Bytes:               3,
+		Cycles:              7,
+		AddCycleOnPageCross: false,
+		AddressingMode:      AbsoluteX,
+		Assembly:            "RLA",
+		Unofficial:          true,
+		Opcode:              0x3F,
+		exec:                rla,
+	},
+	0x40: Instruction{
+		Bytes:               1,
+		Cycles:              6,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Implied,
+		Assembly:            "RTI",
+		Unofficial:          false,
+		Opcode:              0x40,
+		exec:                rti,
+	},
+	0x41: Instruction{
+		Bytes:               2,
+		Cycles:              6,
+		AddCycleOnPageCross: false,
+		AddressingMode:      IndexedIndirect,
+		Assembly:            "EOR",
+		Unofficial:          false,
+		Opcode:              0x41,
+		exec:                eor,
+	},
+	0x42: Instruction{
+		Bytes:               1,
+		Cycles:              2,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Implied,
+		Assembly:            "KIL",
+		Unofficial:          true,
+		Opcode:              0x42,
+		exec:                kil,
+	},
+	0x43: Instruction{
+		Bytes:               2,
+		Cycles:              8,
+		AddCycleOnPageCross: false,
+		AddressingMode:      IndexedIndirect,
+		Assembly:            "SRE",
+		Unofficial:          true,
+		Opcode:              0x43,
+		exec:                sre,
+	},
+	0x44: Instruction{
+		Bytes:               2,
+		Cycles:              3,
+		AddCycleOnPageCross: false,
+		AddressingMode:      ZeroPage,
+		Assembly:            "NOP",
+		Unofficial:          true,
+		Opcode:              0x44,
+		exec:                nop,
+	},
+	0x45: Instruction{
+		Bytes:               2,
+		Cycles:              3,
+		AddCycleOnPageCross: false,
+		AddressingMode:      ZeroPage,
+		Assembly:            "EOR",
+		Unofficial:          false,
+		Opcode:              0x45,
+		exec:                eor,
+	},
+	0x46: Instruction{
+		Bytes:               2,
+		Cycles:              5,
+		AddCycleOnPageCross: false,
+		AddressingMode:      ZeroPage,
+		Assembly:            "LSR",
+		Unofficial:          false,
+		Opcode:              0x46,
+		exec:                lsr,
+	},
+	0x47: Instruction{
+		Bytes:               2,
+		Cycles:              5,
+		AddCycleOnPageCross: false,
+		AddressingMode:      ZeroPage,
+		Assembly:            "SRE",
+		Unofficial:          true,
+		Opcode:              0x47,
+		exec:                sre,
+	},
+	0x48: Instruction{
+		Bytes:               1,
+		Cycles:              3,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Implied,
+		Assembly:            "PHA",
+		Unofficial:          false,
+		Opcode:              0x48,
+		exec:                pha,
+	},
+	0x49: Instruction{
+		Bytes:               2,
+		Cycles:              2,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Immediate,
+		Assembly:            "EOR",
+		Unofficial:          false,
+		Opcode:              0x49,
+		exec:                eor,
+	},
+	0x4A: Instruction{
+		Bytes:               1,
+		Cycles:              2,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Accumulator,
+		Assembly:            "LSR",
+		Unofficial:          false,
+		Opcode:              0x4A,
+		exec:                lsr,
+	},
+	0x4B: Instruction{
+		Bytes:               2,
+		Cycles:              2,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Immediate,
+		Assembly:            "ALR",
+		Unofficial:          true,
+		Opcode:              0x4B,
+		exec:                alr,
+	},
+	0x4C: Instruction{
+		Bytes:               3,
+		Cycles:              3,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Absolute,
+		Assembly:            "JMP",
+		Unofficial:          false,
+		Opcode:              0x4C,
+		exec:                jmp,
+	},
+	0x4D: Instruction{
+		Bytes:               3,
+		Cycles:              4,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Absolute,
+		Assembly:            "EOR",
+		Unofficial:          false,
+		Opcode:              0x4D,
+		exec:                eor,
+	},
+	0x4E: Instruction{
+		Bytes:               3,
+		Cycles:              6,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Absolute,
+		Assembly:            "LSR",
+		Unofficial:          false,
+		Opcode:              0x4E,
+		exec:                lsr,
+	},
+	0x4F: Instruction{
+		Bytes:               3,
+		Cycles:              6,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Absolute,
+		Assembly:            "SRE",
+		Unofficial:          true,
+		Opcode:              0x4F,
+		exec:                sre,
+	},
+	0x50: Instruction{
+		Bytes:               2,
+		Cycles:              2,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Relative,
+		Assembly:            "BVC",
+		Unofficial:          false,
+		Opcode:              0x50,
+		exec:                bvc,
+	},
+	0x51: Instruction{
+		Bytes:               2,
+		Cycles:              5,
+		AddCycleOnPageCross: true,
+		AddressingMode:      IndirectIndexed,
+		Assembly:            "EOR",
+		Unofficial:          false,
+		Opcode:              0x51,
+		exec:                eor,
+	},
+	0x52: Instruction{
+		Bytes:               1,
+		Cycles:              2,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Implied,
+		Assembly:            "KIL",
+		Unofficial:          true,
+		Opcode:              0x52,
+		exec:                kil,
+	},
+	0x53: Instruction{
+		Bytes:               2,
+		Cycles:              8,
+		AddCycleOnPageCross: false,
+		AddressingMode:      IndirectIndexed,
+		Assembly:            "SRE",
+		Unofficial:          true,
+		Opcode:              0x53,
+		exec:                sre,
+	},
+	0x54: Instruction{
+		Bytes:               2,
+		Cycles:              4,
+		AddCycleOnPageCross: false,
+		AddressingMode:      ZeroPageX,
+		Assembly:            "NOP",
+		Unofficial:          true,
+		Opcode:              0x54,
+		exec:                nop,
+	},
+	0x55: Instruction{
+		Bytes:               2,
+		Cycles:              4,
+		AddCycleOnPageCross: false,
+		AddressingMode:      ZeroPageX,
+		Assembly:            "EOR",
+		Unofficial:          false,
+		Opcode:              0x55,
+		exec:                eor,
+	},
+	0x56: Instruction{
+		Bytes:               2,
+		Cycles:              6,
+		AddCycleOnPageCross: false,
+		AddressingMode:      ZeroPageX,
+		Assembly:            "LSR",
+		Unofficial:          false,
+		Opcode:              0x56,
+		exec:                lsr,
+	},
+	0x57: Instruction{
+		Bytes:               2,
+		Cycles:              6,
+		AddCycleOnPageCross: false,
+		AddressingMode:      ZeroPageX,
+		Assembly:            "SRE",
+		Unofficial:          true,
+		Opcode:              0x57,
+		exec:                sre,
+	},
+	0x58: Instruction{
+		Bytes:               1,
+		Cycles:              2,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Implied,
+		Assembly:            "CLI",
+		Unofficial:          false,
+		Opcode:              0x58,
+		exec:                cli,
+	},
+	0x59: Instruction{
+		Bytes:               3,
+		Cycles:              4,
+		AddCycleOnPageCross: true,
+		AddressingMode:      AbsoluteY,
+		Assembly:            "EOR",
+		Unofficial:          false,
+		Opcode:              0x59,
+		exec:                eor,
+	},
+	0x5A: Instruction{
+		Bytes:               1,
+		Cycles:              2,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Implied,
+		Assembly:            "NOP",
+		Unofficial:          true,
+		Opcode:              0x5A,
+		exec:                nop,
+	},
+	0x5B: Instruction{
+		Bytes:               3,
+		Cycles:              7,
+		AddCycleOnPageCross: false,
+		AddressingMode:      AbsoluteY,
+		Assembly:            "SRE",
+		Unofficial:          true,
+		Opcode:              0x5B,
+		exec:                sre,
+	},
+	0x5C: Instruction{
+		Bytes:               3,
+		Cycles:              4,
+		AddCycleOnPageCross: true,
+		AddressingMode:      AbsoluteX,
+		Assembly:            "NOP",
+		Unofficial:          true,
+		Opcode:              0x5C,
+		exec:                nop,
+	},
+	0x5D: Instruction{
+		Bytes:               3,
+		Cycles:              4,
+		AddCycleOnPageCross: true,
+		AddressingMode:      AbsoluteX,
+		Assembly:            "EOR",
+		Unofficial:          false,
+		Opcode:              0x5D,
+		exec:                eor,
+	},
+	0x5E: Instruction{
+		Bytes:               3,
+		Cycles:              7,
+		AddCycleOnPageCross: false,
+		AddressingMode:      AbsoluteX,
+		Assembly:            "LSR",
+		Unofficial:          false,
+		Opcode:              0x5E,
+		exec:                lsr,
+	},
+	0x5F: Instruction{
+		Bytes:               3,
+		Cycles:              7,
+		AddCycleOnPageCross: false,
+		AddressingMode:      AbsoluteX,
+		Assembly:            "SRE",
+		Unofficial:          true,
+		Opcode:              0x5F,
+		exec:                sre,
+	},
+	0x60: Instruction{
+		Bytes:               1,
+		Cycles:              6,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Implied,
+		Assembly:            "RTS",
+		Unofficial:          false,
+		Opcode:              0x60,
+		exec:                rts,
+	},
+	0x61: Instruction{
+		Bytes:               2,
+		Cycles:              6,
+		AddCycleOnPageCross: false,
+		AddressingMode:      IndexedIndirect,
+		Assembly:            "ADC",
+		Unofficial:          false,
+		Opcode:              0x61,
+		exec:                adc,
+	},
+	0x62: Instruction{
+		Bytes:               1,
+		Cycles:              2,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Implied,
+		Assembly:            "KIL",
+		Unofficial:          true,
+		Opcode:              0x62,
+		exec:                kil,
+	},
+	0x63: Instruction{
+		Bytes:               2,
+		Cycles:              8,
+		AddCycleOnPageCross: false,
+		AddressingMode:      IndexedIndirect,
+		Assembly:            "RRA",
+		Unofficial:          true,
+		Opcode:              0x63,
+		exec:                rra,
+	},
+	0x64: Instruction{
+		Bytes:               2,
+		Cycles:              3,
+		AddCycleOnPageCross: false,
+		AddressingMode:      ZeroPage,
+		Assembly:            "NOP",
+		Unofficial:          true,
+		Opcode:              0x64,
+		exec:                nop,
+	},
+	0x65: Instruction{
+		Bytes:               2,
+		Cycles:              3,
+		AddCycleOnPageCross: false,
+		AddressingMode:      ZeroPage,
+		Assembly:            "ADC",
+		Unofficial:          false,
+		Opcode:              0x65,
+		exec:                adc,
+	},
+	0x66: Instruction{
+		Bytes:               2,
+		Cycles:              5,
+		AddCycleOnPageCross: false,
+		AddressingMode:      ZeroPage,
+		Assembly:            "ROR",
+		Unofficial:          false,
+		Opcode:              0x66,
+		exec:                ror,
+	},
+	0x67: Instruction{
+		Bytes:               2,
+		Cycles:              5,
+		AddCycleOnPageCross: false,
+		AddressingMode:      ZeroPage,
+		Assembly:            "RRA",
+		Unofficial:          true,
+		Opcode:              0x67,
+		exec:                rra,
+	},
+	0x68: Instruction{
+		Bytes:               1,
+		Cycles:              4,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Implied,
+		Assembly:            "PLA",
+		Unofficial:          false,
+		Opcode:              0x68,
+		exec:                pla,
+	},
+	0x69: Instruction{
+		Bytes:               2,
+		Cycles:              2,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Immediate,
+		Assembly:            "ADC",
+		Unofficial:          false,
+		Opcode:              0x69,
+		exec:                adc,
+	},
+	0x6A: Instruction{
+		Bytes:               1,
+		Cycles:              2,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Accumulator,
+		Assembly:            "ROR",
+		Unofficial:          false,
+		Opcode:              0x6A,
+		exec:                ror,
+	},
+	0x6B: Instruction{
+		Bytes:               2,
+		Cycles:              2,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Immediate,
+		Assembly:            "ARR",
+		Unofficial:          true,
+		Opcode:              0x6B,
+		exec:                arr,
+	},
+	0x6C: Instruction{
+		Bytes:               3,
+		Cycles:              5,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Indirect,
+		Assembly:            "JMP",
+		Unofficial:          false,
+		Opcode:              0x6C,
+		exec:                jmp,
+	},
+	0x6D: Instruction{
+		Bytes:               3,
+		Cycles:              4,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Absolute,
+		Assembly:            "ADC",
+		Unofficial:          false,
+		Opcode:              0x6D,
+		exec:                adc,
+	},
+	0x6E: Instruction{
+		Bytes:               3,
+		Cycles:              6,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Absolute,
+		Assembly:            "ROR",
+		Unofficial:          false,
+		Opcode:              0x6E,
+		exec:                ror,
+	},
+	0x6F: Instruction{
+		Bytes:               3,
+		Cycles:              6,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Absolute,
+		Assembly:            "RRA",
+		Unofficial:          true,
+		Opcode:              0x6F,
+		exec:                rra,
+	},
+	0x70: Instruction{
+		Bytes:               2,
+		Cycles:              2,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Relative,
+		Assembly:            "BVS",
+		Unofficial:          false,
+		Opcode:              0x70,
+		exec:                bvs,
+	},
+	0x71: Instruction{
+		Bytes:               2,
+		Cycles:              5,
+		AddCycleOnPageCross: true,
+		AddressingMode:      IndirectIndexed,
+		Assembly:            "ADC",
+		Unofficial:          false,
+		Opcode:              0x71,
+		exec:                adc,
+	},
+	0x72: Instruction{
+		Bytes:               1,
+		Cycles:              2,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Implied,
+		Assembly:            "KIL",
+		Unofficial:          true,
+		Opcode:              0x72,
+		exec:                kil,
+	},
+	0x73: Instruction{
+		Bytes:               2,
+		Cycles:              8,
+		AddCycleOnPageCross: false,
+		AddressingMode:      IndirectIndexed,
+		Assembly:            "RRA",
+		Unofficial:          true,
+		Opcode:              0x73,
+		exec:                rra,
+	},
+	0x74: Instruction{
+		Bytes:               2,
+		Cycles:              4,
+		AddCycleOnPageCross: false,
+		AddressingMode:      ZeroPageX,
+		Assembly:            "NOP",
+		Unofficial:          true,
+		Opcode:              0x74,
+		exec:                nop,
+	},
+	0x75: Instruction{
+		Bytes:               2,
+		Cycles:              4,
+		AddCycleOnPageCross: false,
+		AddressingMode:      ZeroPageX,
+		Assembly:            "ADC",
+		Unofficial:          false,
+		Opcode:              0x75,
+		exec:                adc,
+	},
+	0x76: Instruction{
+		Bytes:               2,
+		Cycles:              6,
+		AddCycleOnPageCross: false,
+		AddressingMode:      ZeroPageX,
+		Assembly:            "ROR",
+		Unofficial:          false,
+		Opcode:              0x76,
+		exec:                ror,
+	},
+	0x77: Instruction{
+		Bytes:               2,
+		Cycles:              6,
+		AddCycleOnPageCross: false,
+		AddressingMode:      ZeroPageX,
+		Assembly:            "RRA",
+		Unofficial:          true,
+		Opcode:              0x77,
+		exec:                rra,
+	},
+	0x78: Instruction{
+		Bytes:               1,
+		Cycles:              2,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Implied,
+		Assembly:            "SEI",
+		Unofficial:          false,
+		Opcode:              0x78,
+		exec:                sei,
+	},
+	0x79: Instruction{
+		Bytes:               3,
+		Cycles:              4,
+		AddCycleOnPageCross: true,
+		AddressingMode:      AbsoluteY,
+		Assembly:            "ADC",
+		Unofficial:          false,
+		Opcode:              0x79,
+		exec:                adc,
+	},
+	0x7A: Instruction{
+		Bytes:               1,
+		Cycles:              2,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Implied,
+		Assembly:            "NOP",
+		Unofficial:          true,
+		Opcode:              0x7A,
+		exec:                nop,
+	},
+	0x7B: Instruction{
+		Bytes:               3,
+		Cycles:              7,
+		AddCycleOnPageCross: false,
+		AddressingMode:      AbsoluteY,
+		Assembly:            "RRA",
+		Unofficial:          true,
+		Opcode:              0x7B,
+		exec:                rra,
+	},
+	0x7C: Instruction{
+		Bytes:               3,
+		Cycles:              4,
+		AddCycleOnPageCross: true,
+		AddressingMode:      AbsoluteX,
+		Assembly:            "NOP",
+		Unofficial:          true,
+		Opcode:              0x7C,
+		exec:                nop,
+	},
+	0x7D: Instruction{
+		Bytes:               3,
+		Cycles:              4,
+		AddCycleOnPageCross: true,
+		AddressingMode:      AbsoluteX,
+		Assembly:            "ADC",
+		Unofficial:          false,
+		Opcode:              0x7D,
+		exec:                adc,
+	},
+	0x7E: Instruction{
+		Bytes:               3,
+		Cycles:              7,
+		AddCycleOnPageCross: false,
+		AddressingMode:      AbsoluteX,
+		Assembly:            "ROR",
+		Unofficial:          false,
+		Opcode:              0x7E,
+		exec:                ror,
+	},
+	0x7F: Instruction{
+		Bytes:               3,
+		Cycles:              7,
+		AddCycleOnPageCross: false,
+		AddressingMode:      AbsoluteX,
+		Assembly:            "RRA",
+		Unofficial:          true,
+		Opcode:              0x7F,
+		exec:                rra,
+	},
+	0x80: Instruction{
+		Bytes:               2,
+		Cycles:              2,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Immediate,
+		Assembly:            "NOP",
+		Unofficial:          true,
+		Opcode:              0x80,
+		exec:                nop,
+	},
+	0x81: Instruction{
+		Bytes:               2,
+		Cycles:              6,
+		AddCycleOnPageCross: false,
+		AddressingMode:      IndexedIndirect,
+		Assembly:            "STA",
+		Unofficial:          false,
+		Opcode:              0x81,
+		exec:                sta,
+	},
+	0x82: Instruction{
+		Bytes:               2,
+		Cycles:              2,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Immediate,
+		Assembly:            "NOP",
+		Unofficial:          true,
+		Opcode:              0x82,
+		exec:                nop,
+	},
+	0x83: Instruction{
+		Bytes:               2,
+		Cycles:              6,
+		AddCycleOnPageCross: false,
+		AddressingMode:      IndexedIndirect,
+		Assembly:            "SAX",
+		Unofficial:          true,
+		Opcode:              0x83,
+		exec:                sax,
+	},
+	0x84: Instruction{
+		Bytes:               2,
+		Cycles:              3,
+		AddCycleOnPageCross: false,
+		AddressingMode:      ZeroPage,
+		Assembly:            "STY",
+		Unofficial:          false,
+		Opcode:              0x84,
+		exec:                sty,
+	},
+	0x85: Instruction{
+		Bytes:               2,
+		Cycles:              3,
+		AddCycleOnPageCross: false,
+		AddressingMode:      ZeroPage,
+		Assembly:            "STA",
+		Unofficial:          false,
+		Opcode:              0x85,
+		exec:                sta,
+	},
+	0x86: Instruction{
+		Bytes:               2,
+		Cycles:              3,
+		AddCycleOnPageCross: false,
+		AddressingMode:      ZeroPage,
+		Assembly:            "STX",
+		Unofficial:          false,
+		Opcode:              0x86,
+		exec:                stx,
+	},
+	0x87: Instruction{
+		Bytes:               2,
+		Cycles:              3,
+		AddCycleOnPageCross: false,
+		AddressingMode:      ZeroPage,
+		Assembly:            "SAX",
+		Unofficial:          true,
+		Opcode:              0x87,
+		exec:                sax,
+	},
+	0x88: Instruction{
+		Bytes:               1,
+		Cycles:              2,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Implied,
+		Assembly:            "DEY",
+		Unofficial:          false,
+		Opcode:              0x88,
+		exec:                dey,
+	},
+	0x89: Instruction{
+		Bytes:               2,
+		Cycles:              2,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Immediate,
+		Assembly:            "NOP",
+		Unofficial:          true,
+		Opcode:              0x89,
+		exec:                nop,
+	},
+	0x8A: Instruction{
+		Bytes:               1,
+		Cycles:              2,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Implied,
+		Assembly:            "TXA",
+		Unofficial:          false,
+		Opcode:              0x8A,
+		exec:                txa,
+	},
+	0x8B: Instruction{
+		Bytes:               2,
+		Cycles:              2,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Immediate,
+		Assembly:            "XAA",
+		Unofficial:          true,
+		Opcode:              0x8B,
+		exec:                xaa,
+	},
+	0x8C: Instruction{
+		Bytes:               3,
+		Cycles:              4,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Absolute,
+		Assembly:            "STY",
+		Unofficial:          false,
+		Opcode:              0x8C,
+		exec:                sty,
+	},
+	0x8D: Instruction{
+		Bytes:               3,
+		Cycles:              4,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Absolute,
+		Assembly:            "STA",
+		Unofficial:          false,
+		Opcode:              0x8D,
+		exec:                sta,
+	},
+	0x8E: Instruction{
+		Bytes:               3,
+		Cycles:              4,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Absolute,
+		Assembly:            "STX",
+		Unofficial:          false,
+		Opcode:              0x8E,
+		exec:                stx,
+	},
+	0x8F: Instruction{
+		Bytes:               3,
+		Cycles:              4,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Absolute,
+		Assembly:            "SAX",
+		Unofficial:          true,
+		Opcode:              0x8F,
+		exec:                sax,
+	},
+	0x90: Instruction{
+		Bytes:               2,
+		Cycles:              2,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Relative,
+		Assembly:            "BCC",
+		Unofficial:          false,
+		Opcode:              0x90,
+		exec:                bcc,
+	},
+	0x91: Instruction{
+		Bytes:               2,
+		Cycles:              6,
+		AddCycleOnPageCross: false,
+		AddressingMode:      IndirectIndexed,
+		Assembly:            "STA",
+		Unofficial:          false,
+		Opcode:              0x91,
+		exec:                sta,
+	},
+	0x92: Instruction{
+		Bytes:               1,
+		Cycles:              2,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Implied,
+		Assembly:            "KIL",
+		Unofficial:          true,
+		Opcode:              0x92,
+		exec:                kil,
+	},
+	0x93: Instruction{
+		Bytes:               2,
+		Cycles:              6,
+		AddCycleOnPageCross: false,
+		AddressingMode:      IndirectIndexed,
+		Assembly:            "AHX",
+		Unofficial:          true,
+		Opcode:              0x93,
+		exec:                ahx,
+	},
+	0x94: Instruction{
+		Bytes:               2,
+		Cycles:              4,
+		AddCycleOnPageCross: false,
+		AddressingMode:      ZeroPageX,
+		Assembly:            "STY",
+		Unofficial:          false,
+		Opcode:              0x94,
+		exec:                sty,
+	},
+	0x95: Instruction{
+		Bytes:               2,
+		Cycles:              4,
+		AddCycleOnPageCross: false,
+		AddressingMode:      ZeroPageX,
+		Assembly:            "STA",
+		Unofficial:          false,
+		Opcode:              0x95,
+		exec:                sta,
+	},
+	0x96: Instruction{
+		Bytes:               2,
+		Cycles:              4,
+		AddCycleOnPageCross: false,
+		AddressingMode:      ZeroPageY,
+		Assembly:            "STX",
+		Unofficial:          false,
+		Opcode:              0x96,
+		exec:                stx,
+	},
+	0x97: Instruction{
+		Bytes:               2,
+		Cycles:              4,
+		AddCycleOnPageCross: false,
+		AddressingMode:      ZeroPageY,
+		Assembly:            "SAX",
+		Unofficial:          true,
+		Opcode:              0x97,
+		exec:                sax,
+	},
+	0x98: Instruction{
+		Bytes:               1,
+		Cycles:              2,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Implied,
+		Assembly:            "TYA",
+		Unofficial:          false,
+		Opcode:              0x98,
+		exec:                tya,
+	},
+	0x99: Instruction{
+		Bytes:               3,
+		Cycles:              5,
+		AddCycleOnPageCross: false,
+		AddressingMode:      AbsoluteY,
+		Assembly:            "STA",
+		Unofficial:          false,
+		Opcode:              0x99,
+		exec:                sta,
+	},
+	0x9A: Instruction{
+		Bytes:               1,
+		Cycles:              2,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Implied,
+		Assembly:            "TXS",
+		Unofficial:          false,
+		Opcode:              0x9A,
+		exec:                txs,
+	},
+	0x9B: Instruction{
+		Bytes:               3,
+		Cycles:              5,
+		AddCycleOnPageCross: false,
+		AddressingMode:      AbsoluteY,
+		Assembly:            "TAS",
+		Unofficial:          true,
+		Opcode:              0x9B,
+		exec:                tas,
+	},
+	0x9C: Instruction{
+		Bytes:               3,
+		Cycles:              5,
+		AddCycleOnPageCross: false,
+		AddressingMode:      AbsoluteX,
+		Assembly:            "SHY",
+		Unofficial:          true,
+		Opcode:              0x9C,
+		exec:                shy,
+	},
+	0x9D: Instruction{
+		Bytes:               3,
+		Cycles:              5,
+		AddCycleOnPageCross: false,
+		AddressingMode:      AbsoluteX,
+		Assembly:            "STA",
+		Unofficial:          false,
+		Opcode:              0x9D,
+		exec:                sta,
+	},
+	0x9E: Instruction{
+		Bytes:               3,
+		Cycles:              5,
+		AddCycleOnPageCross: false,
+		AddressingMode:      AbsoluteY,
+		Assembly:            "SHX",
+		Unofficial:          true,
+		Opcode:              0x9E,
+		exec:                shx,
+	},
+	0x9F: Instruction{
+		Bytes:               3,
+		Cycles:              5,
+		AddCycleOnPageCross: false,
+		AddressingMode:      AbsoluteY,
+		Assembly:            "AHX",
+		Unofficial:          true,
+		Opcode:              0x9F,
+		exec:                ahx,
+	},
+	0xA0: Instruction{
+		Bytes:               2,
+		Cycles:              2,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Immediate,
+		Assembly:            "LDY",
+		Unofficial:          false,
+		Opcode:              0xA0,
+		exec:                ldy,
+	},
+	0xA1: Instruction{
+		Bytes:               2,
+		Cycles:              6,
+		AddCycleOnPageCross: false,
+		AddressingMode:      IndexedIndirect,
+		Assembly:            "LDA",
+		Unofficial:          false,
+		Opcode:              0xA1,
+		exec:                lda,
+	},
+	0xA2: Instruction{
+		Bytes:               2,
+		Cycles:              2,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Immediate,
+		Assembly:            "LDX",
+		Unofficial:          false,
+		Opcode:              0xA2,
+		exec:                ldx,
+	},
+	0xA3: Instruction{
+		Bytes:               2,
+		Cycles:              6,
+		AddCycleOnPageCross: false,
+		AddressingMode:      IndexedIndirect,
+		Assembly:            "LAX",
+		Unofficial:          true,
+		Opcode:              0xA3,
+		exec:                lax,
+	},
+	0xA4: Instruction{
+		Bytes:               2,
+		Cycles:              3,
+		AddCycleOnPageCross: false,
+		AddressingMode:      ZeroPage,
+		Assembly:            "LDY",
+		Unofficial:          false,
+		Opcode:              0xA4,
+		exec:                ldy,
+	},
+	0xA5: Instruction{
+		Bytes:               2,
+		Cycles:              3,
+		AddCycleOnPageCross: false,
+		AddressingMode:      ZeroPage,
+		Assembly:            "LDA",
+		Unofficial:          false,
+		Opcode:              0xA5,
+		exec:                lda,
+	},
+	0xA6: Instruction{
+		Bytes:               2,
+		Cycles:              3,
+		AddCycleOnPageCross: false,
+		AddressingMode:      ZeroPage,
+		Assembly:            "LDX",
+		Unofficial:          false,
+		Opcode:              0xA6,
+		exec:                ldx,
+	},
+	0xA7: Instruction{
+		Bytes:               2,
+		Cycles:              3,
+		AddCycleOnPageCross: false,
+		AddressingMode:      ZeroPage,
+		Assembly:            "LAX",
+		Unofficial:          true,
+		Opcode:              0xA7,
+		exec:                lax,
+	},
+	0xA8: Instruction{
+		Bytes:               1,
+		Cycles:              2,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Implied,
+		Assembly:            "TAY",
+		Unofficial:          false,
+		Opcode:              0xA8,
+		exec:                tay,
+	},
+	0xA9: Instruction{
+		Bytes:               2,
+		Cycles:              2,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Immediate,
+		Assembly:            "LDA",
+		Unofficial:          false,
+		Opcode:              0xA9,
+		exec:                lda,
+	},
+	0xAA: Instruction{
+		Bytes:               1,
+		Cycles:              2,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Implied,
+		Assembly:            "TAX",
+		Unofficial:          false,
+		Opcode:              0xAA,
+		exec:                tax,
+	},
+	0xAB: Instruction{
+		Bytes:               2,
+		Cycles:              2,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Immediate,
+		Assembly:            "LXA",
+		Unofficial:          true,
+		Opcode:              0xAB,
+		exec:                lxa,
+	},
+	0xAC: Instruction{
+		Bytes:               3,
+		Cycles:              4,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Absolute,
+		Assembly:            "LDY",
+		Unofficial:          false,
+		Opcode:              0xAC,
+		exec:                ldy,
+	},
+	0xAD: Instruction{
+		Bytes:               3,
+		Cycles:              4,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Absolute,
+		Assembly:            "LDA",
+		Unofficial:          false,
+		Opcode:              0xAD,
+		exec:                lda,
+	},
+	0xAE: Instruction{
+		Bytes:               3,
+		Cycles:              4,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Absolute,
+		Assembly:            "LDX",
+		Unofficial:          false,
+		Opcode:              0xAE,
+		exec:                ldx,
+	},
+	0xAF: Instruction{
+		Bytes:               3,
+		Cycles:              4,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Absolute,
+		Assembly:            "LAX",
+		Unofficial:          true,
+		Opcode:              0xAF,
+		exec:                lax,
+	},
+	0xB0: Instruction{
+		Bytes:               2,
+		Cycles:              2,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Relative,
+		Assembly:            "BCS",
+		Unofficial:          false,
+		Opcode:              0xB0,
+		exec:                bcs,
+	},
+	0xB1: Instruction{
+		Bytes:               2,
+		Cycles:              5,
+		AddCycleOnPageCross: true,
+		AddressingMode:      IndirectIndexed,
+		Assembly:            "LDA",
+		Unofficial:          false,
+		Opcode:              0xB1,
+		exec:                lda,
+	},
+	0xB2: Instruction{
+		Bytes:               1,
+		Cycles:              2,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Implied,
+		Assembly:            "KIL",
+		Unofficial:          true,
+		Opcode:              0xB2,
+		exec:                kil,
+	},
+	0xB3: Instruction{
+		Bytes:               2,
+		Cycles:              5,
+		AddCycleOnPageCross: true,
+		AddressingMode:      IndirectIndexed,
+		Assembly:            "LAX",
+		Unofficial:          true,
+		Opcode:              0xB3,
+		exec:                lax,
+	},
+	0xB4: Instruction{
+		Bytes:               2,
+		Cycles:              4,
+		AddCycleOnPageCross: false,
+		AddressingMode:      ZeroPageX,
+		Assembly:            "LDY",
+		Unofficial:          false,
+		Opcode:              0xB4,
+		exec:                ldy,
+	},
+	0xB5: Instruction{
+		Bytes:               2,
+		Cycles:              4,
+		AddCycleOnPageCross: false,
+		AddressingMode:      ZeroPageX,
+		Assembly:            "LDA",
+		Unofficial:          false,
+		Opcode:              0xB5,
+		exec:                lda,
+	},
+	0xB6: Instruction{
+		Bytes:               2,
+		Cycles:              4,
+		AddCycleOnPageCross: false,
+		AddressingMode:      ZeroPageY,
+		Assembly:            "LDX",
+		Unofficial:          false,
+		Opcode:              0xB6,
+		exec:                ldx,
+	},
+	0xB7: Instruction{
+		Bytes:               2,
+		Cycles:              4,
+		AddCycleOnPageCross: false,
+		AddressingMode:      ZeroPageY,
+		Assembly:            "LAX",
+		Unofficial:          true,
+		Opcode:              0xB7,
+		exec:                lax,
+	},
+	0xB8: Instruction{
+		Bytes:               1,
+		Cycles:              2,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Implied,
+		Assembly:            "CLV",
+		Unofficial:          false,
+		Opcode:              0xB8,
+		exec:                clv,
+	},
+	0xB9: Instruction{
+		Bytes:               3,
+		Cycles:              4,
+		AddCycleOnPageCross: true,
+		AddressingMode:      AbsoluteY,
+		Assembly:            "LDA",
+		Unofficial:          false,
+		Opcode:              0xB9,
+		exec:                lda,
+	},
+	0xBA: Instruction{
+		Bytes:               1,
+		Cycles:              2,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Implied,
+		Assembly:            "TSX",
+		Unofficial:          false,
+		Opcode:              0xBA,
+		exec:                tsx,
+	},
+	0xBB: Instruction{
+		Bytes:               3,
+		Cycles:              4,
+		AddCycleOnPageCross: true,
+		AddressingMode:      AbsoluteY,
+		Assembly:            "LAS",
+		Unofficial:          true,
+		Opcode:              0xBB,
+		exec:                las,
+	},
+	0xBC: Instruction{
+		Bytes:               3,
+		Cycles:              4,
+		AddCycleOnPageCross: true,
+		AddressingMode:      AbsoluteX,
+		Assembly:            "LDY",
+		Unofficial:          false,
+		Opcode:              0xBC,
+		exec:                ldy,
+	},
+	0xBD: Instruction{
+		Bytes:               3,
+		Cycles:              4,
+		AddCycleOnPageCross: true,
+		AddressingMode:      AbsoluteX,
+		Assembly:            "LDA",
+		Unofficial:          false,
+		Opcode:              0xBD,
+		exec:                lda,
+	},
+	0xBE: Instruction{
+		Bytes:               3,
+		Cycles:              4,
+		AddCycleOnPageCross: true,
+		AddressingMode:      AbsoluteY,
+		Assembly:            "LDX",
+		Unofficial:          false,
+		Opcode:              0xBE,
+		exec:                ldx,
+	},
+	0xBF: Instruction{
+		Bytes:               3,
+		Cycles:              4,
+		AddCycleOnPageCross: true,
+		AddressingMode:      AbsoluteY,
+		Assembly:            "LAX",
+		Unofficial:          true,
+		Opcode:              0xBF,
+		exec:                lax,
+	},
+	0xC0: Instruction{
+		Bytes:               2,
+		Cycles:              2,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Immediate,
+		Assembly:            "CPY",
+		Unofficial:          false,
+		Opcode:              0xC0,
+		exec:                cpy,
+	},
+	0xC1: Instruction{
+		Bytes:               2,
+		Cycles:              6,
+		AddCycleOnPageCross: false,
+		AddressingMode:      IndexedIndirect,
+		Assembly:            "CMP",
+		Unofficial:          false,
+		Opcode:              0xC1,
+		exec:                cmp,
+	},
+	0xC2: Instruction{
+		Bytes:               2,
+		Cycles:              2,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Immediate,
+		Assembly:            "NOP",
+		Unofficial:          true,
+		Opcode:              0xC2,
+		exec:                nop,
+	},
+	0xC3: Instruction{
+		Bytes:               2,
+		Cycles:              8,
+		AddCycleOnPageCross: false,
+		AddressingMode:      IndexedIndirect,
+		Assembly:            "DCP",
+		Unofficial:          true,
+		Opcode:              0xC3,
+		exec:                dcp,
+	},
+	0xC4: Instruction{
+		Bytes:               2,
+		Cycles:              3,
+		AddCycleOnPageCross: false,
+		AddressingMode:      ZeroPage,
+		Assembly:            "CPY",
+		Unofficial:          false,
+		Opcode:              0xC4,
+		exec:                cpy,
+	},
+	0xC5: Instruction{
+		Bytes:               2,
+		Cycles:              3,
+		AddCycleOnPageCross: false,
+		AddressingMode:      ZeroPage,
+		Assembly:            "CMP",
+		Unofficial:          false,
+		Opcode:              0xC5,
+		exec:                cmp,
+	},
+	0xC6: Instruction{
+		Bytes:               2,
+		Cycles:              5,
+		AddCycleOnPageCross: false,
+		AddressingMode:      ZeroPage,
+		Assembly:            "DEC",
+		Unofficial:          false,
+		Opcode:              0xC6,
+		exec:                dec,
+	},
+	0xC7: Instruction{
+		Bytes:               2,
+		Cycles:              5,
+		AddCycleOnPageCross: false,
+		AddressingMode:      ZeroPage,
+		Assembly:            "DCP",
+		Unofficial:          true,
+		Opcode:              0xC7,
+		exec:                dcp,
+	},
+	0xC8: Instruction{
+		Bytes:               1,
+		Cycles:              2,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Implied,
+		Assembly:            "INY",
+		Unofficial:          false,
+		Opcode:              0xC8,
+		exec:                iny,
+	},
+	0xC9: Instruction{
+		Bytes:               2,
+		Cycles:              2,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Immediate,
+		Assembly:            "CMP",
+		Unofficial:          false,
+		Opcode:              0xC9,
+		exec:                cmp,
+	},
+	0xCA: Instruction{
+		Bytes:               1,
+		Cycles:              2,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Implied,
+		Assembly:            "DEX",
+		Unofficial:          false,
+		Opcode:              0xCA,
+		exec:                dex,
+	},
+	0xCB: Instruction{
+		Bytes:               2,
+		Cycles:              2,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Immediate,
+		Assembly:            "AXS",
+		Unofficial:          true,
+		Opcode:              0xCB,
+		exec:                axs,
+	},
+	0xCC: Instruction{
+		Bytes:               3,
+		Cycles:              4,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Absolute,
+		Assembly:            "CPY",
+		Unofficial:          false,
+		Opcode:              0xCC,
+		exec:                cpy,
+	},
+	0xCD: Instruction{
+		Bytes:               3,
+		Cycles:              4,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Absolute,
+		Assembly:            "CMP",
+		Unofficial:          false,
+		Opcode:              0xCD,
+		exec:                cmp,
+	},
+	0xCE: Instruction{
+		Bytes:               3,
+		Cycles:              6,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Absolute,
+		Assembly:            "DEC",
+		Unofficial:          false,
+		Opcode:              0xCE,
+		exec:                dec,
+	},
+	0xCF: Instruction{
+		Bytes:               3,
+		Cycles:              6,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Absolute,
+		Assembly:            "DCP",
+		Unofficial:          true,
+		Opcode:              0xCF,
+		exec:                dcp,
+	},
+	0xD0: Instruction{
+		Bytes:               2,
+		Cycles:              2,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Relative,
+		Assembly:            "BNE",
+		Unofficial:          false,
+		Opcode:              0xD0,
+		exec:                bne,
+	},
+	0xD1: Instruction{
+		Bytes:               2,
+		Cycles:              5,
+		AddCycleOnPageCross: true,
+		AddressingMode:      IndirectIndexed,
+		Assembly:            "CMP",
+		Unofficial:          false,
+		Opcode:              0xD1,
+		exec:                cmp,
+	},
+	0xD2: Instruction{
+		Bytes:               1,
+		Cycles:              2,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Implied,
+		Assembly:            "KIL",
+		Unofficial:          true,
+		Opcode:              0xD2,
+		exec:                kil,
+	},
+	0xD3: Instruction{
+		Bytes:               2,
+		Cycles:              8,
+		AddCycleOnPageCross: false,
+		AddressingMode:      IndirectIndexed,
+		Assembly:            "DCP",
+		Unofficial:          true,
+		Opcode:              0xD3,
+		exec:                dcp,
+	},
+	0xD4: Instruction{
+		Bytes:               2,
+		Cycles:              4,
+		AddCycleOnPageCross: false,
+		AddressingMode:      ZeroPageX,
+		Assembly:            "NOP",
+		Unofficial:          true,
+		Opcode:              0xD4,
+		exec:                nop,
+	},
+	0xD5: Instruction{
+		Bytes:               2,
+		Cycles:              4,
+		AddCycleOnPageCross: false,
+		AddressingMode:      ZeroPageX,
+		Assembly:            "CMP",
+		Unofficial:          false,
+		Opcode:              0xD5,
+		exec:                cmp,
+	},
+	0xD6: Instruction{
+		Bytes:               2,
+		Cycles:              6,
+		AddCycleOnPageCross: false,
+		AddressingMode:      ZeroPageX,
+		Assembly:            "DEC",
+		Unofficial:          false,
+		Opcode:              0xD6,
+		exec:                dec,
+	},
+	0xD7: Instruction{
+		Bytes:               2,
+		Cycles:              6,
+		AddCycleOnPageCross: false,
+		AddressingMode:      ZeroPageX,
+		Assembly:            "DCP",
+		Unofficial:          true,
+		Opcode:              0xD7,
+		exec:                dcp,
+	},
+	0xD8: Instruction{
+		Bytes:               1,
+		Cycles:              2,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Implied,
+		Assembly:            "CLD",
+		Unofficial:          false,
+		Opcode:              0xD8,
+		exec:                cld,
+	},
+	0xD9: Instruction{
+		Bytes:               3,
+		Cycles:              4,
+		AddCycleOnPageCross: true,
+		AddressingMode:      AbsoluteY,
+		Assembly:            "CMP",
+		Unofficial:          false,
+		Opcode:              0xD9,
+		exec:                cmp,
+	},
+	0xDA: Instruction{
+		Bytes:               1,
+		Cycles:              2,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Implied,
+		Assembly:            "NOP",
+		Unofficial:          true,
+		Opcode:              0xDA,
+		exec:                nop,
+	},
+	0xDB: Instruction{
+		Bytes:               3,
+		Cycles:              7,
+		AddCycleOnPageCross: false,
+		AddressingMode:      AbsoluteY,
+		Assembly:            "DCP",
+		Unofficial:          true,
+		Opcode:              0xDB,
+		exec:                dcp,
+	},
+	0xDC: Instruction{
+		Bytes:               3,
+		Cycles:              4,
+		AddCycleOnPageCross: true,
+		AddressingMode:      AbsoluteX,
+		Assembly:            "NOP",
+		Unofficial:          true,
+		Opcode:              0xDC,
+		exec:                nop,
+	},
+	0xDD: Instruction{
+		Bytes:               3,
+		Cycles:              4,
+		AddCycleOnPageCross: true,
+		AddressingMode:      AbsoluteX,
+		Assembly:            "CMP",
+		Unofficial:          false,
+		Opcode:              0xDD,
+		exec:                cmp,
+	},
+	0xDE: Instruction{
+		Bytes:               3,
+		Cycles:              7,
+		AddCycleOnPageCross: false,
+		AddressingMode:      AbsoluteX,
+		Assembly:            "DEC",
+		Unofficial:          false,
+		Opcode:              0xDE,
+		exec:                dec,
+	},
+	0xDF: Instruction{
+		Bytes:               3,
+		Cycles:              7,
+		AddCycleOnPageCross: false,
+		AddressingMode:      AbsoluteX,
+		Assembly:            "DCP",
+		Unofficial:          true,
+		Opcode:              0xDF,
+		exec:                dcp,
+	},
+	0xE0: Instruction{
+		Bytes:               2,
+		Cycles:              2,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Immediate,
+		Assembly:            "CPX",
+		Unofficial:          false,
+		Opcode:              0xE0,
+		exec:                cpx,
+	},
+	0xE1: Instruction{
+		Bytes:               2,
+		Cycles:              6,
+		AddCycleOnPageCross: false,
+		AddressingMode:      IndexedIndirect,
+		Assembly:            "SBC",
+		Unofficial:          false,
+		Opcode:              0xE1,
+		exec:                sbc,
+	},
+	0xE2: Instruction{
+		Bytes:               2,
+		Cycles:              2,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Immediate,
+		Assembly:            "NOP",
+		Unofficial:          true,
+		Opcode:              0xE2,
+		exec:                nop,
+	},
+	0xE3: Instruction{
+		Bytes:               2,
+		Cycles:              8,
+		AddCycleOnPageCross: false,
+		AddressingMode:      IndexedIndirect,
+		Assembly:            "ISB",
+		Unofficial:          true,
+		Opcode:              0xE3,
+		exec:                isb,
+	},
+	0xE4: Instruction{
+		Bytes:               2,
+		Cycles:              3,
+		AddCycleOnPageCross: false,
+		AddressingMode:      ZeroPage,
+		Assembly:            "CPX",
+		Unofficial:          false,
+		Opcode:              0xE4,
+		exec:                cpx,
+	},
+	0xE5: Instruction{
+		Bytes:               2,
+		Cycles:              3,
+		AddCycleOnPageCross: false,
+		AddressingMode:      ZeroPage,
+		Assembly:            "SBC",
+		Unofficial:          false,
+		Opcode:              0xE5,
+		exec:                sbc,
+	},
+	0xE6: Instruction{
+		Bytes:               2,
+		Cycles:              5,
+		AddCycleOnPageCross: false,
+		AddressingMode:      ZeroPage,
+		Assembly:            "INC",
+		Unofficial:          false,
+		Opcode:              0xE6,
+		exec:                inc,
+	},
+	0xE7: Instruction{
+		Bytes:               2,
+		Cycles:              5,
+		AddCycleOnPageCross: false,
+		AddressingMode:      ZeroPage,
+		Assembly:            "ISB",
+		Unofficial:          true,
+		Opcode:              0xE7,
+		exec:                isb,
+	},
+	0xE8: Instruction{
+		Bytes:               1,
+		Cycles:              2,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Implied,
+		Assembly:            "INX",
+		Unofficial:          false,
+		Opcode:              0xE8,
+		exec:                inx,
+	},
+	0xE9: Instruction{
+		Bytes:               2,
+		Cycles:              2,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Immediate,
+		Assembly:            "SBC",
+		Unofficial:          false,
+		Opcode:              0xE9,
+		exec:                sbc,
+	},
+	0xEA: Instruction{
+		Bytes:               1,
+		Cycles:              2,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Implied,
+		Assembly:            "NOP",
+		Unofficial:          false,
+		Opcode:              0xEA,
+		exec:                nop,
+	},
+	0xEB: Instruction{
+		Bytes:               2,
+		Cycles:              2,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Immediate,
+		Assembly:            "SBC",
+		Unofficial:          true,
+		Opcode:              0xEB,
+		exec:                sbc,
+	},
+	0xEC: Instruction{
+		Bytes:               3,
+		Cycles:              4,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Absolute,
+		Assembly:            "CPX",
+		Unofficial:          false,
+		Opcode:              0xEC,
+		exec:                cpx,
+	},
+	0xED: Instruction{
+		Bytes:               3,
+		Cycles:              4,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Absolute,
+		Assembly:            "SBC",
+		Unofficial:          false,
+		Opcode:              0xED,
+		exec:                sbc,
+	},
+	0xEE: Instruction{
+		Bytes:               3,
+		Cycles:              6,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Absolute,
+		Assembly:            "INC",
+		Unofficial:          false,
+		Opcode:              0xEE,
+		exec:                inc,
+	},
+	0xEF: Instruction{
+		Bytes:               3,
+		Cycles:              6,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Absolute,
+		Assembly:            "ISB",
+		Unofficial:          true,
+		Opcode:              0xEF,
+		exec:                isb,
+	},
+	0xF0: Instruction{
+		Bytes:               2,
+		Cycles:              2,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Relative,
+		Assembly:            "BEQ",
+		Unofficial:          false,
+		Opcode:              0xF0,
+		exec:                beq,
+	},
+	0xF1: Instruction{
+		Bytes:               2,
+		Cycles:              5,
+		AddCycleOnPageCross: true,
+		AddressingMode:      IndirectIndexed,
+		Assembly:            "SBC",
+		Unofficial:          false,
+		Opcode:              0xF1,
+		exec:                sbc,
+	},
+	0xF2: Instruction{
+		Bytes:               1,
+		Cycles:              2,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Implied,
+		Assembly:            "KIL",
+		Unofficial:          true,
+		Opcode:              0xF2,
+		exec:                kil,
+	},
+	0xF3: Instruction{
+		Bytes:               2,
+		Cycles:              8,
+		AddCycleOnPageCross: false,
+		AddressingMode:      IndirectIndexed,
+		Assembly:            "ISB",
+		Unofficial:          true,
+		Opcode:              0xF3,
+		exec:                isb,
+	},
+	0xF4: Instruction{
+		Bytes:               2,
+		Cycles:              4,
+		AddCycleOnPageCross: false,
+		AddressingMode:      ZeroPageX,
+		Assembly:            "NOP",
+		Unofficial:          true,
+		Opcode:              0xF4,
+		exec:                nop,
+	},
+	0xF5: Instruction{
+		Bytes:               2,
+		Cycles:              4,
+		AddCycleOnPageCross: false,
+		AddressingMode:      ZeroPageX,
+		Assembly:            "SBC",
+		Unofficial:          false,
+		Opcode:              0xF5,
+		exec:                sbc,
+	},
+	0xF6: Instruction{
+		Bytes:               2,
+		Cycles:              6,
+		AddCycleOnPageCross: false,
+		AddressingMode:      ZeroPageX,
+		Assembly:            "INC",
+		Unofficial:          false,
+		Opcode:              0xF6,
+		exec:                inc,
+	},
+	0xF7: Instruction{
+		Bytes:               2,
+		Cycles:              6,
+		AddCycleOnPageCross: false,
+		AddressingMode:      ZeroPageX,
+		Assembly:            "ISB",
+		Unofficial:          true,
+		Opcode:              0xF7,
+		exec:                isb,
+	},
+	0xF8: Instruction{
+		Bytes:               1,
+		Cycles:              2,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Implied,
+		Assembly:            "SED",
+		Unofficial:          false,
+		Opcode:              0xF8,
+		exec:                sed,
+	},
+	0xF9: Instruction{
+		Bytes:               3,
+		Cycles:              4,
+		AddCycleOnPageCross: true,
+		AddressingMode:      AbsoluteY,
+		Assembly:            "SBC",
+		Unofficial:          false,
+		Opcode:              0xF9,
+		exec:                sbc,
+	},
+	0xFA: Instruction{
+		Bytes:               1,
+		Cycles:              2,
+		AddCycleOnPageCross: false,
+		AddressingMode:      Implied,
+		Assembly:            "NOP",
+		Unofficial:          true,
+		Opcode:              0xFA,
+		exec:                nop,
+	},
+	0xFB: Instruction{
+		Bytes:               3,
+		Cycles:              7,
+		AddCycleOnPageCross: false,
+		AddressingMode:      AbsoluteY,
+		Assembly:            "ISB",
+		Unofficial:          true,
+		Opcode:              0xFB,
+		exec:                isb,
+	},
+	0xFC: Instruction{
+		Bytes:               3,
+		Cycles:              4,
+		AddCycleOnPageCross: true,
+		AddressingMode:      AbsoluteX,
+		Assembly:            "NOP",
+		Unofficial:          true,
+		Opcode:              0xFC,
+		exec:                nop,
+	},
+	0xFD: Instruction{
+		Bytes:               3,
+		Cycles:              4,
+		AddCycleOnPageCross: true,
+		AddressingMode:      AbsoluteX,
+		Assembly:            "SBC",
+		Unofficial:          false,
+		Opcode:              0xFD,
+		exec:                sbc,
+	},
+	0xFE: Instruction{
+		Bytes:               3,
+		Cycles:              7,
+		AddCycleOnPageCross: false,
+		AddressingMode:      AbsoluteX,
+		Assembly:            "INC",
+		Unofficial:          false,
+		Opcode:              0xFE,
+		exec:                inc,
+	},
+	0xFF: Instruction{
+		Bytes:               3,
+		Cycles:              7,
+		AddCycleOnPageCross: false,
+		AddressingMode:      AbsoluteX,
+		Assembly:            "ISB",
+		Unofficial:          true,
+		Opcode:              0xFF,
+		exec:                isb,
+	},
+}
+
+// This function is used interally by the ADC and SBC instructions
+var add = func(cpu *CPU, operand byte) {
+	accumulator := cpu.A
+	carry := cpu.flagToInt(cpu.CFlag)
+
+	sum := uint16(accumulator) + uint16(operand) + uint16(carry)
+	cpu.A = byte(sum)
+
+	cpu.CFlag = sum > 0xFF
+
+	// Formula for setting the overflow flag taken from:
+	// http://www.righto.com/2012/12/the-6502-overflow-flag-explained.html
+	cpu.VFlag = ((accumulator ^ cpu.A) & (operand ^ cpu.A) & 0x80) != 0
+
+	cpu.setZeroAndNegativeFlags(cpu.A)
+}
+
+var and = func(cpu *CPU, context *instructionContext) {
+	cpu.A = (cpu.A & cpu.read(context.Address))
+	cpu.setZeroAndNegativeFlags(cpu.A)
+}
+
+var adc = func(cpu *CPU, context *instructionContext) {
+	add(cpu, cpu.read(context.Address))
+}
+
+var asl = func(cpu *CPU, context *instructionContext) {
+	modify(cpu, context, func(operand byte) byte {
+		return shiftLeft(cpu, operand)
+	})
+}
+
+var bcc = func(cpu *CPU, context *instructionContext) {
+	if !cpu.CFlag {
+		branchRelative(cpu, context)
+	}
+}
+
+var bcs = func(cpu *CPU, context *instructionContext) {
+	if cpu.CFlag {
+		branchRelative(cpu, context)
+	}
+}
+
+var beq = func(cpu *CPU, context *instructionContext) {
+	if cpu.ZFlag {
+		branchRelative(cpu, context)
+	}
+}
+
+var bit = func(cpu *CPU, context *instructionContext) {
+	operand := cpu.read(context.Address)
+	if (cpu.A & operand) == 0 {
+		cpu.ZFlag = true
+	} else {
+		cpu.ZFlag = false
+	}
+
+	cpu.NFlag = cpu.intToFlag(operand & 0x80)
+	cpu.VFlag = cpu.intToFlag(operand & 0x40)
+}
+
+var bmi = func(cpu *CPU, context *instructionContext) {
+	if cpu.NFlag {
+		branchRelative(cpu, context)
+	}
+}
+
+var bne = func(cpu *CPU, context *instructionContext) {
+	if !cpu.ZFlag {
+		branchRelative(cpu, context)
+	}
+}
+
+var bpl = func(cpu *CPU, context *instructionContext) {
+	if !cpu.NFlag {
+		branchRelative(cpu, context)
+	}
+}
+
+var brk = func(cpu *CPU, context *instructionContext) {
+	// push the PC and the status flags onto the stack, set the
+	// disable interrupt flag and load the interrupt address from
+	// $FFFE and $FFFF.
+	// Bits 5 and 4 of the pushed flags are always set
+	// See: https://wiki.nesdev.com/w/index.php/Status_flags#The_B_flag
+	cpu.interruptSequence(cpu.Status() | 0x30)
+}
+
+var bvc = func(cpu *CPU, context *instructionContext) {
+	if !cpu.VFlag {
+		branchRelative(cpu, context)
+	}
+}
+
+var bvs = func(cpu *CPU, context *instructionContext) {
+	if cpu.VFlag {
+		branchRelative(cpu, context)
+	}
+}
+
+var clc = func(cpu *CPU, context *instructionContext) {
+	cpu.CFlag = false
+}
+
+var cld = func(cpu *CPU, context *instructionContext) {
+	cpu.DFlag = false
+}
+
+var cli = func(cpu *CPU, context *instructionContext) {
+	cpu.IFlag = false
+}
+
+var clv = func(cpu *CPU, context *instructionContext) {
+	cpu.VFlag = false
+}
+
+var cmp = func(cpu *CPU, context *instructionContext) {
+	compare(cpu, cpu.A, cpu.read(context.Address))
+}
+
+var cpx = func(cpu *CPU, context *instructionContext) {
+	compare(cpu, cpu.X, cpu.read(context.Address))
+}
+
+var cpy = func(cpu *CPU, context *instructionContext) {
+	compare(cpu, cpu.Y, cpu.read(context.Address))
+}
+
+var dec = func(cpu *CPU, context *instructionContext) {
+	modify(cpu, context, func(operand byte) byte {
+		return decrement(cpu, operand)
+	})
+}
+
+var dex = func(cpu *CPU, context *instructionContext) {
+	cpu.X = decrement(cpu, cpu.X)
+}
+
+var dey = func(cpu *CPU, context *instructionContext) {
+	cpu.Y = decrement(cpu, cpu.Y)
+}
+
+var eor = func(cpu *CPU, context *instructionContext) {
+	operand := cpu.read(context.Address)
+	cpu.A = cpu.A ^ operand
+	cpu.setZeroAndNegativeFlags(cpu.A)
+}
+
+var inc = func(cpu *CPU, context *instructionContext) {
+	modify(cpu, context, func(operand byte) byte {
+		return increment(cpu, operand)
+	})
+}
+
+var inx = func(cpu *CPU, context *instructionContext) {
+	cpu.X = increment(cpu, cpu.X)
+}
+
+var iny = func(cpu *CPU, context *instructionContext) {
+	cpu.Y = increment(cpu, cpu.Y)
+}
+
+var jmp = func(cpu *CPU, context *instructionContext) {
+	cpu.PC = context.Address
+}
+
+var jsr = func(cpu *CPU, context *instructionContext) {
+	// Only the low byte of the target has been fetched at this point
+	// and PC is pointing at the high byte, which is the return address
+	// minus one that RTS expects
+	cpu.read(0x100 | uint16(cpu.SP))
+	cpu.stackPush16(cpu.PC)
+	hi := cpu.read(cpu.PC)
+	cpu.PC = uint16(hi)<<8 | context.Address
+}
+
+var lda = func(cpu *CPU, context *instructionContext) {
+	cpu.A = cpu.read(context.Address)
+	cpu.setZeroAndNegativeFlags(cpu.A)
+}
+
+var ldx = func(cpu *CPU, context *instructionContext) {
+	cpu.X = cpu.read(context.Address)
+	cpu.setZeroAndNegativeFlags(cpu.X)
+}
+
+var ldy = func(cpu *CPU, context *instructionContext) {
+	cpu.Y = cpu.read(context.Address)
+	cpu.setZeroAndNegativeFlags(cpu.Y)
+}
+
+var lsr = func(cpu *CPU, context *instructionContext) {
+	modify(cpu, context, func(operand byte) byte {
+		return shiftRight(cpu, operand)
+	})
+}
+
+var nop = func(cpu *CPU, context *instructionContext) {
+	// The unofficial NOPs that take an operand still read it
+	if context.AddressingMode != Implied {
+		cpu.read(context.Address)
+	}
+}
+
+var ora = func(cpu *CPU, context *instructionContext) {
+	cpu.A = cpu.A | cpu.read(context.Address)
+	cpu.setZeroAndNegativeFlags(cpu.A)
+}
+
+var pha = func(cpu *CPU, context *instructionContext) {
+	cpu.stackPush(cpu.A)
+}
+
+var php = func(cpu *CPU, context *instructionContext) {
+	// Bits 5 and 4 are always set according to
+	// https://wiki.nesdev.com/w/index.php/Status_flags
+	// though the flag status isn't changed
+	cpu.stackPush(cpu.Status() | 0x30)
+}
+
+var pla = func(cpu *CPU, context *instructionContext) {
+	cpu.read(0x100 | uint16(cpu.SP))
+	cpu.A = cpu.stackPop()
+	cpu.setZeroAndNegativeFlags(cpu.A)
+}
+
+var plp = func(cpu *CPU, context *instructionContext) {
+	cpu.read(0x100 | uint16(cpu.SP))
+	cpu.SetStatus(cpu.stackPop()&0xEF | 0x20)
+}
+
+var rol = func(cpu *CPU, context *instructionContext) {
+	modify(cpu, context, func(operand byte) byte {
+		return rotateLeft(cpu, operand)
+	})
+}
+
+var ror = func(cpu *CPU, context *instructionContext) {
+	modify(cpu, context, func(operand byte) byte {
+		return rotateRight(cpu, operand)
+	})
+}
+
+var rti = func(cpu *CPU, context *instructionContext) {
+	cpu.read(0x100 | uint16(cpu.SP))
+	cpu.SetStatus(cpu.stackPop()&0xEF | 0x20)
+	cpu.PC = cpu.stackPop16()
+}
+
+var rts = func(cpu *CPU, context *instructionContext) {
+	cpu.read(0x100 | uint16(cpu.SP))
+	cpu.PC = cpu.stackPop16()
+	cpu.fetch()
+}
+
+var sbc = func(cpu *CPU, context *instructionContext) {
+	// Subtraction is the same as addition of the one's
+	// complement. Here we flip the bits of the operand
+	// (i.e. take the one's complement) and then run the
+	// same logic as the ADC instruction
+	operand := cpu.read(context.Address)
+	add(cpu, ^operand)
+}
+
+var sec = func(cpu *CPU, context *instructionContext) {
+	cpu.CFlag = true
+}
+
+var sed = func(cpu *CPU, context *instructionContext) {
+	cpu.DFlag = true
+}
+
+var sei = func(cpu *CPU, context *instructionContext) {
+	cpu.IFlag = true
+}
+
+var sta = func(cpu *CPU, context *instructionContext) {
+	cpu.write(context.Address, cpu.A)
+}
+
+var stx = func(cpu *CPU, context *instructionContext) {
+	cpu.write(context.Address, cpu.X)
+}
+
+var sty = func(cpu *CPU, context *instructionContext) {
+	cpu.write(context.Address, cpu.Y)
+}
+
+var tax = func(cpu *CPU, context *instructionContext) {
+	cpu.X = cpu.A
+	cpu.setZeroAndNegativeFlags(cpu.X)
+}
+
+var tay = func(cpu *CPU, context *instructionContext) {
+	cpu.Y = cpu.A
+	cpu.setZeroAndNegativeFlags(cpu.Y)
+}
+
+var tsx = func(cpu *CPU, context *instructionContext) {
+	cpu.X = cpu.SP
+	cpu.setZeroAndNegativeFlags(cpu.X)
+}
+
+var txa = func(cpu *CPU, context *instructionContext) {
+	cpu.A = cpu.X
+	cpu.setZeroAndNegativeFlags(cpu.A)
+}
+
+var txs = func(cpu *CPU, context *instructionContext) {
+	cpu.SP = cpu.X
+}
+
+var tya = func(cpu *CPU, context *instructionContext) {
+	cpu.A = cpu.Y
+	cpu.setZeroAndNegativeFlags(cpu.A)
+}
+
+// Unofficial opcodes
+//
+// These are the undocumented instructions that fall out of the NMOS 6502
+// instruction decoder. Most combine two official instructions, a few are
+// unstable and depend on analog effects that are modelled here the way
+// the common test suites expect.
+// See: https://wiki.nesdev.com/w/index.php/CPU_unofficial_opcodes
+
+var alr = func(cpu *CPU, context *instructionContext) {
+	cpu.A = shiftRight(cpu, cpu.A&cpu.read(context.Address))
+}
+
+var anc = func(cpu *CPU, context *instructionContext) {
+	cpu.A = cpu.A & cpu.read(context.Address)
+	cpu.setZeroAndNegativeFlags(cpu.A)
+	cpu.CFlag = cpu.NFlag
+}
+
+var ahx = func(cpu *CPU, context *instructionContext) {
+	storeHighByteAnd(cpu, context, cpu.Y, cpu.A&cpu.X)
+}
+
+var arr = func(cpu *CPU, context *instructionContext) {
+	// AND followed by ROR, except that C and V are taken from bits 6
+	// and 5 of the result rather than from the rotation
+	cpu.A = cpu.A & cpu.read(context.Address)
+	cpu.A = cpu.A>>1 | cpu.flagToInt(cpu.CFlag)<<7
+	cpu.setZeroAndNegativeFlags(cpu.A)
+	cpu.CFlag = cpu.intToFlag(cpu.A & 0x40)
+	cpu.VFlag = cpu.intToFlag((cpu.A>>6 ^ cpu.A>>5) & 0x01)
+}
+
+var axs = func(cpu *CPU, context *instructionContext) {
+	// X = (A & X) - operand, setting flags like CMP does
+	operand := cpu.read(context.Address)
+	value := cpu.A & cpu.X
+	compare(cpu, value, operand)
+	cpu.X = value - operand
+}
+
+var dcp = func(cpu *CPU, context *instructionContext) {
+	modify(cpu, context, func(operand byte) byte {
+		result := operand - 1
+		compare(cpu, cpu.A, result)
+		return result
+	})
+}
+
+var isb = func(cpu *CPU, context *instructionContext) {
+	modify(cpu, context, func(operand byte) byte {
+		result := operand + 1
+		add(cpu, ^result)
+		return result
+	})
+}
+
+var kil = func(cpu *CPU, context *instructionContext) {
+	// The processor locks up with the opcode on the bus, only a reset
+	// brings it back
+	cpu.PC -= 1
+	cpu.Halted = true
+}
+
+var las = func(cpu *CPU, context *instructionContext) {
+	value := cpu.read(context.Address) & cpu.SP
+	cpu.A = value
+	cpu.X = value
+	cpu.SP = value
+	cpu.setZeroAndNegativeFlags(value)
+}
+
+var lax = func(cpu *CPU, context *instructionContext) {
+	cpu.A = cpu.read(context.Address)
+	cpu.X = cpu.A
+	cpu.setZeroAndNegativeFlags(cpu.A)
+}
+
+var lxa = func(cpu *CPU, context *instructionContext) {
+	// Unstable: the accumulator is ORed with a chip dependent constant
+	// before the AND. $EE matches the behaviour most test suites expect.
+	cpu.A = (cpu.A | 0xEE) & cpu.read(context.Address)
+	cpu.X = cpu.A
+	cpu.setZeroAndNegativeFlags(cpu.A)
+}
+
+var rla = func(cpu *CPU, context *instructionContext) {
+	modify(cpu, context, func(operand byte) byte {
+		result := rotateLeft(cpu, operand)
+		cpu.A = cpu.A & result
+		cpu.setZeroAndNegativeFlags(cpu.A)
+		return result
+	})
+}
+
+var rra = func(cpu *CPU, context *instructionContext) {
+	modify(cpu, context, func(operand byte) byte {
+		result := rotateRight(cpu, operand)
+		add(cpu, result)
+		return result
+	})
+}
+
+var sax = func(cpu *CPU, context *instructionContext) {
+	cpu.write(context.Address, cpu.A&cpu.X)
+}
+
+var shx = func(cpu *CPU, context *instructionContext) {
+	storeHighByteAnd(cpu, context, cpu.Y, cpu.X)
+}
+
+var shy = func(cpu *CPU, context *instructionContext) {
+	storeHighByteAnd(cpu, context, cpu.X, cpu.Y)
+}
+
+var slo = func(cpu *CPU, context *instructionContext) {
+	modify(cpu, context, func(operand byte) byte {
+		result := shiftLeft(cpu, operand)
+		cpu.A = cpu.A | result
+		cpu.setZeroAndNegativeFlags(cpu.A)
+		return result
+	})
+}
+
+var sre = func(cpu *CPU, context *instructionContext) {
+	modify(cpu, context, func(operand byte) byte {
+		result := shiftRight(cpu, operand)
+		cpu.A = cpu.A ^ result
+		cpu.setZeroAndNegativeFlags(cpu.A)
+		return result
+	})
+}
+
+var tas = func(cpu *CPU, context *instructionContext) {
+	cpu.SP = cpu.A & cpu.X
+	storeHighByteAnd(cpu, context, cpu.Y, cpu.SP)
+}
+
+var xaa = func(cpu *CPU, context *instructionContext) {
+	// Unstable, see LXA
+	cpu.A = (cpu.A | 0xEE) & cpu.X & cpu.read(context.Address)
+	cpu.setZeroAndNegativeFlags(cpu.A)
+}
+
+func branchRelative(cpu *CPU, context *instructionContext) {
+	// Taking a branch costs an extra cycle, and another one when the
+	// target is on a different page. The CPU reads from the next
+	// instruction and then from the target with the high byte not yet
+	// fixed up during those cycles.
+	cpu.read(cpu.PC)
+
+	if context.PageCrossed {
+		cpu.read(cpu.PC&0xFF00 | context.Address&0x00FF)
+	}
+
+	cpu.PC = context.Address
+}
+
+// modify is used internally by the read-modify-write instructions, which
+// operate either on the accumulator or on a byte in memory. The CPU
+// writes the unmodified value back while it is working out the result,
+// so memory is written twice.
+func modify(cpu *CPU, context *instructionContext, operation func(byte) byte) {
+	if context.AddressingMode == Accumulator {
+		cpu.A = operation(cpu.A)
+	} else {
+		operand := cpu.read(context.Address)
+		cpu.write(context.Address, operand)
+		cpu.write(context.Address, operation(operand))
+	}
+}
+
+// storeHighByteAnd is used internally by the unstable SHX, SHY, AHX and TAS
+// instructions. They store value ANDed with the high byte of the base
+// address plus one. When indexing crosses a page the high byte of the
+// target address is replaced by the value being stored.
+func storeHighByteAnd(cpu *CPU, context *instructionContext, index byte, value byte) {
+	base := context.Address - uint16(index)
+	value = value & (byte(base>>8) + 1)
+	address := context.Address
+
+	if context.PageCrossed {
+		address = uint16(value)<<8 | address&0x00FF
+	}
+
+	cpu.write(address, value)
+}
+
+func shiftLeft(cpu *CPU, operand byte) byte {
+	cpu.CFlag = cpu.intToFlag(operand & 0x80)
+	result := operand << 1
+	cpu.setZeroAndNegativeFlags(result)
+
+	return result
+}
+
+func shiftRight(cpu *CPU, operand byte) byte {
+	cpu.CFlag = cpu.intToFlag(operand & 0x01)
+	result := operand >> 1
+	cpu.setZeroAndNegativeFlags(result)
+
+	return result
+}
+
+func rotateLeft(cpu *CPU, operand byte) byte {
+	result := operand<<1 | cpu.flagToInt(cpu.CFlag)
+	cpu.CFlag = cpu.intToFlag(operand & 0x80)
+	cpu.setZeroAndNegativeFlags(result)
+
+	return result
+}
+
+func rotateRight(cpu *CPU, operand byte) byte {
+	result := operand>>1 | cpu.flagToInt(cpu.CFlag)<<7
+	cpu.CFlag = cpu.intToFlag(operand & 0x01)
+	cpu.setZeroAndNegativeFlags(result)
+
+	return result
+}
+
+func compare(cpu *CPU, register byte, operand byte) {
+	cpu.CFlag = register >= operand
+	cpu.setZeroAndNegativeFlags(register - operand)
+}
+
+func decrement(cpu *CPU, target byte) byte {
+	result := target - 1
+	cpu.setZeroAndNegativeFlags(result)
+
+	return result
+}
+
+func increment(cpu *CPU, target byte) byte {
+	result := target + 1
+	cpu.setZeroAndNegativeFlags(result)
+
+	return result
+}
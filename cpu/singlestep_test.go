@@ -0,0 +1,194 @@
+package cpu
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// singleStepTest is a test in the per-opcode JSON format of the
+// SingleStepTests suites (once called ProcessorTests): the registers and
+// the memory that matters before and after one instruction, and the bus
+// access made in every cycle of it.
+type singleStepTest struct {
+	Name    string           `json:"name"`
+	Initial singleStepState  `json:"initial"`
+	Final   singleStepState  `json:"final"`
+	Cycles  [][3]interface{} `json:"cycles"` // address, value, "read" or "write"
+}
+
+type singleStepState struct {
+	PC  uint16      `json:"pc"`
+	S   byte        `json:"s"`
+	A   byte        `json:"a"`
+	X   byte        `json:"x"`
+	Y   byte        `json:"y"`
+	P   byte        `json:"p"`
+	RAM [][2]uint16 `json:"ram"` // address, value
+}
+
+// maxSingleStepFailures stops an opcode that is wrong everywhere from
+// burying the rest of the results
+const maxSingleStepFailures = 5
+
+// runSingleStep runs a test on a CPU and bus that are reused between
+// tests, because clearing 64KB for each of thousands of tests is slow. It
+// returns what differed from the test's final state.
+func runSingleStep(cpu *CPU, bus *recordingBus, test singleStepTest) []string {
+	initial, final := test.Initial, test.Final
+
+	bus.accesses = bus.accesses[:0]
+	for _, entry := range initial.RAM {
+		bus.Memory[entry[0]] = byte(entry[1])
+	}
+	cpu.PC, cpu.SP, cpu.A, cpu.X, cpu.Y = initial.PC, initial.S, initial.A, initial.X, initial.Y
+	cpu.SetStatus(initial.P)
+	cpu.Cycles = 0
+
+	var diffs []string
+	if err := cpu.Exec(); err != nil {
+		diffs = append(diffs, err.Error())
+	}
+
+	registers := []struct {
+		name      string
+		got, want uint16
+	}{
+		{"PC", cpu.PC, final.PC},
+		{"S", uint16(cpu.SP), uint16(final.S)},
+		{"A", uint16(cpu.A), uint16(final.A)},
+		{"X", uint16(cpu.X), uint16(final.X)},
+		{"Y", uint16(cpu.Y), uint16(final.Y)},
+		// B and the unused bit aren't flags the CPU keeps, only pushes
+		{"P", uint16(cpu.Status() &^ 0x30), uint16(final.P &^ 0x30)},
+	}
+	for _, register := range registers {
+		if register.got != register.want {
+			diffs = append(diffs, fmt.Sprintf("%s is %02X, want %02X", register.name, register.got, register.want))
+		}
+	}
+
+	for _, entry := range final.RAM {
+		if value := bus.Memory[entry[0]]; value != byte(entry[1]) {
+			diffs = append(diffs, fmt.Sprintf("$%04X is %02X, want %02X", entry[0], value, entry[1]))
+		}
+	}
+
+	var want []busAccess
+	for _, cycle := range test.Cycles {
+		address, _ := cycle[0].(float64)
+		value, _ := cycle[1].(float64)
+		want = append(want, busAccess{uint16(address), byte(value), cycle[2] == "write"})
+	}
+	if !equalAccesses(bus.accesses, want) {
+		diffs = append(diffs, fmt.Sprintf("bus accesses are %v, want %v", bus.accesses, want))
+	}
+
+	// put back the zeroes the next test expects
+	for _, entry := range initial.RAM {
+		bus.Memory[entry[0]] = 0
+	}
+	for _, access := range bus.accesses {
+		bus.Memory[access.Address] = 0
+	}
+
+	return diffs
+}
+
+func singleStepFailures(t *testing.T, tests []singleStepTest) {
+	cpu, bus := NewCPU(), &recordingBus{}
+	cpu.Bus = bus
+
+	failures := 0
+	for _, test := range tests {
+		if diffs := runSingleStep(cpu, bus, test); len(diffs) > 0 {
+			t.Errorf("%s: %s", test.Name, strings.Join(diffs, ", "))
+			if failures++; failures == maxSingleStepFailures {
+				t.Log("skipping the rest of the tests")
+				return
+			}
+		}
+	}
+}
+
+// TestSingleStep runs every opcode in instructionMap against the JSON
+// tests in the directory named by SINGLE_STEP_TESTS, one file per opcode
+// named like a9.json. The nes6502 suite is the one to use, since the NES
+// has no decimal mode. It is skipped when SINGLE_STEP_TESTS isn't set.
+func TestSingleStep(t *testing.T) {
+	dir := os.Getenv("SINGLE_STEP_TESTS")
+	if dir == "" {
+		t.Skip("SINGLE_STEP_TESTS is not set")
+	}
+
+	for opcode := 0; opcode <= 0xFF; opcode++ {
+		instruction, ok := instructionMap[byte(opcode)]
+		if !ok {
+			continue
+		}
+
+		t.Run(fmt.Sprintf("%02X %s", opcode, instruction.Assembly), func(t *testing.T) {
+			if instruction.Assembly == "KIL" {
+				t.Skip("KIL never finishes")
+			}
+
+			data, err := os.ReadFile(filepath.Join(dir, fmt.Sprintf("%02x.json", opcode)))
+			if os.IsNotExist(err) {
+				t.Skip("no tests for this opcode")
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			var tests []singleStepTest
+			if err := json.Unmarshal(data, &tests); err != nil {
+				t.Fatal(err)
+			}
+			singleStepFailures(t, tests)
+		})
+	}
+}
+
+// singleStepSample is hand-written in the suite's format, so the runner
+// is tested without the suite: ROR of zero with carry in, which must
+// clear Z, then LDA #$00 reading its operand
+const singleStepSample = `[
+	{
+		"name": "66 80",
+		"initial": {"pc": 512, "s": 253, "a": 0, "x": 0, "y": 0, "p": 39, "ram": [[512, 102], [513, 128], [128, 0]]},
+		"final": {"pc": 514, "s": 253, "a": 0, "x": 0, "y": 0, "p": 164, "ram": [[512, 102], [513, 128], [128, 128]]},
+		"cycles": [[512, 102, "read"], [513, 128, "read"], [128, 0, "read"], [128, 0, "write"], [128, 128, "write"]]
+	},
+	{
+		"name": "a9 00",
+		"initial": {"pc": 49152, "s": 253, "a": 255, "x": 0, "y": 0, "p": 164, "ram": [[49152, 169], [49153, 0]]},
+		"final": {"pc": 49154, "s": 253, "a": 0, "x": 0, "y": 0, "p": 38, "ram": [[49152, 169], [49153, 0]]},
+		"cycles": [[49152, 169, "read"], [49153, 0, "read"]]
+	}
+]`
+
+func TestSingleStepRunner(t *testing.T) {
+	var tests []singleStepTest
+	if err := json.Unmarshal([]byte(singleStepSample), &tests); err != nil {
+		t.Fatal(err)
+	}
+
+	cpu, bus := NewCPU(), &recordingBus{}
+	cpu.Bus = bus
+	for _, test := range tests {
+		if diffs := runSingleStep(cpu, bus, test); len(diffs) > 0 {
+			t.Errorf("%s did not pass: %s", test.Name, strings.Join(diffs, ", "))
+		}
+	}
+
+	tests[1].Final.A = 0x01
+	tests[1].Cycles = tests[1].Cycles[:1]
+	if diffs := runSingleStep(cpu, bus, tests[1]); len(diffs) != 2 {
+		t.Error("did not report the wrong A and bus accesses, got", diffs)
+	}
+	if bus.Memory[0x0080] != 0 || bus.Memory[0xC000] != 0 {
+		t.Error("did not clear the memory the tests used")
+	}
+}
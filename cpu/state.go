@@ -0,0 +1,88 @@
+package cpu
+
+import (
+	"encoding/gob"
+	"errors"
+	"io"
+)
+
+// ErrMidInstruction is returned by SaveState when Tick has left an
+// instruction part way through.
+var ErrMidInstruction = errors.New("the CPU is part way through an instruction")
+
+type cpuState struct {
+	PC         uint16
+	SP         byte
+	A          byte
+	X          byte
+	Y          byte
+	Status     byte
+	Cycles     uint
+	Halted     bool
+	Stall      uint
+	NMIPending bool
+	IRQLine    IRQSource
+	NMIPoll    bool
+	IRQPoll    bool
+	NMIService bool
+	IRQService bool
+}
+
+// SaveState writes the registers and interrupt state. The bus is not
+// included. States are only saved on instruction boundaries, since the
+// position inside an instruction that Tick has suspended can't be
+// written out, so after Tick an Exec is needed to finish the
+// instruction first. Saving never changes the CPU.
+func (cpu *CPU) SaveState(w io.Writer) error {
+	if cpu.MidInstruction() {
+		return ErrMidInstruction
+	}
+
+	state := cpuState{
+		PC:         cpu.PC,
+		SP:         cpu.SP,
+		A:          cpu.A,
+		X:          cpu.X,
+		Y:          cpu.Y,
+		Status:     cpu.Status(),
+		Cycles:     cpu.Cycles,
+		Halted:     cpu.Halted,
+		Stall:      cpu.stall,
+		NMIPending: cpu.nmiPending,
+		IRQLine:    cpu.irqLine,
+		NMIPoll:    cpu.nmiPoll,
+		IRQPoll:    cpu.irqPoll,
+		NMIService: cpu.nmiService,
+		IRQService: cpu.irqService,
+	}
+
+	return gob.NewEncoder(w).Encode(&state)
+}
+
+// LoadState restores the registers and interrupt state written by
+// SaveState.
+func (cpu *CPU) LoadState(r io.Reader) error {
+	var state cpuState
+	if err := gob.NewDecoder(r).Decode(&state); err != nil {
+		return err
+	}
+
+	cpu.stopTicking()
+	cpu.PC = state.PC
+	cpu.SP = state.SP
+	cpu.A = state.A
+	cpu.X = state.X
+	cpu.Y = state.Y
+	cpu.SetStatus(state.Status)
+	cpu.Cycles = state.Cycles
+	cpu.Halted = state.Halted
+	cpu.stall = state.Stall
+	cpu.nmiPending = state.NMIPending
+	cpu.irqLine = state.IRQLine
+	cpu.nmiPoll = state.NMIPoll
+	cpu.irqPoll = state.IRQPoll
+	cpu.nmiService = state.NMIService
+	cpu.irqService = state.IRQService
+
+	return nil
+}
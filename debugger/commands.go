@@ -0,0 +1,396 @@
+package debugger
+
+import (
+	"fmt"
+	"strings"
+)
+
+type command struct {
+	names []string
+	usage string
+	help  string
+	run   func(debugger *Debugger, args []string) error
+}
+
+var commands []command
+
+// commands is filled in by init because help refers back to it
+func init() {
+	commands = []command{
+		{[]string{"step", "s"}, "step [N]", "run N instructions, 1 if N is left out", step},
+		{[]string{"next", "n"}, "next", "run an instruction, running a whole subroutine for JSR", next},
+		{[]string{"finish"}, "finish", "run until the current subroutine returns", finish},
+		{[]string{"continue", "c"}, "continue", "run until a breakpoint or watchpoint", continueRunning},
+		{[]string{"break", "b"}, "break ADDR|op OPCODE [if COND]", "stop at an address, or before an opcode", addBreakpoint},
+		{[]string{"watch", "w"}, "watch [r|w|rw] ADDR[-END] [if COND]", "stop after memory is read or written, writes if left out", addWatchpoint},
+		{[]string{"delete", "del"}, "delete [ID]", "remove a breakpoint or watchpoint, or all of them", deleteBreakpoint},
+		{[]string{"breakpoints", "info"}, "breakpoints", "list breakpoints and watchpoints", listBreakpoints},
+		{[]string{"regs", "r"}, "regs", "show the registers and flags", showRegisters},
+		{[]string{"set"}, "set REG VALUE", "change a register (A X Y SP PC P) or flag (N V D I Z C)", setRegister},
+		{[]string{"mem", "x"}, "mem ADDR [LEN]", "hexdump LEN bytes of memory, 64 if left out", hexdump},
+		{[]string{"poke"}, "poke ADDR VALUE...", "write bytes to memory", poke},
+		{[]string{"disasm", "d"}, "disasm [ADDR] [N]", "disassemble N instructions, around PC if ADDR is left out", disassemble},
+		{[]string{"stack", "bt"}, "stack", "show the subroutine calls and interrupts that haven't returned", showStack},
+		{[]string{"reset"}, "reset", "press the reset button", reset},
+		{[]string{"help", "h", "?"}, "help", "list the commands", help},
+		{[]string{"quit", "q"}, "quit", "leave the debugger", quit},
+	}
+}
+
+func step(debugger *Debugger, args []string) error {
+	count := 1
+	if len(args) > 0 {
+		var err error
+		if count, err = parseNumber(args[0]); err != nil {
+			return err
+		}
+	}
+
+	return debugger.run(func() bool {
+		count--
+		return count <= 0
+	})
+}
+
+func next(debugger *Debugger, args []string) error {
+	depth := len(debugger.frames)
+	if debugger.opcodeAt(debugger.Console.CPU.PC) != opJSR {
+		return step(debugger, nil)
+	}
+
+	return debugger.run(func() bool { return len(debugger.frames) <= depth })
+}
+
+func finish(debugger *Debugger, args []string) error {
+	depth := len(debugger.frames)
+	if depth == 0 {
+		return fmt.Errorf("not in a subroutine")
+	}
+
+	return debugger.run(func() bool { return len(debugger.frames) < depth })
+}
+
+func continueRunning(debugger *Debugger, args []string) error {
+	return debugger.run(nil)
+}
+
+// parseIf splits the condition off the end of a command's arguments
+func parseIf(args []string) ([]string, *condition, error) {
+	for i, arg := range args {
+		if strings.ToLower(arg) == "if" {
+			condition, err := parseCondition(strings.Join(args[i+1:], " "))
+			return args[:i], condition, err
+		}
+	}
+
+	return args, nil, nil
+}
+
+func addBreakpoint(debugger *Debugger, args []string) error {
+	args, condition, err := parseIf(args)
+	if err != nil {
+		return err
+	}
+
+	point := &breakpoint{kind: breakAddress, condition: condition}
+	if len(args) == 2 && strings.ToLower(args[0]) == "op" {
+		point.kind = breakOpcode
+		args = args[1:]
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("usage: break ADDR|op OPCODE [if COND]")
+	}
+
+	address, err := parseNumber(args[0])
+	if err != nil {
+		return err
+	}
+	if point.kind == breakOpcode && address > 0xFF {
+		return fmt.Errorf("opcode %s is more than a byte", args[0])
+	}
+	point.address = uint16(address)
+
+	debugger.add(point)
+	return nil
+}
+
+func addWatchpoint(debugger *Debugger, args []string) error {
+	args, condition, err := parseIf(args)
+	if err != nil {
+		return err
+	}
+
+	point := &breakpoint{kind: watchMemory, write: true, condition: condition}
+	if len(args) == 2 {
+		switch strings.ToLower(args[0]) {
+		case "r":
+			point.read, point.write = true, false
+		case "w":
+		case "rw":
+			point.read = true
+		default:
+			return fmt.Errorf("watch %q, not r, w or rw", args[0])
+		}
+		args = args[1:]
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("usage: watch [r|w|rw] ADDR[-END] [if COND]")
+	}
+
+	start, end, found := strings.Cut(args[0], "-")
+	address, err := parseNumber(start)
+	if err != nil {
+		return err
+	}
+	point.address, point.end = uint16(address), uint16(address)
+
+	if found {
+		if address, err = parseNumber(end); err != nil {
+			return err
+		}
+		if uint16(address) < point.address {
+			return fmt.Errorf("range %s ends before it starts", args[0])
+		}
+		point.end = uint16(address)
+	}
+
+	debugger.add(point)
+	return nil
+}
+
+func (debugger *Debugger) add(point *breakpoint) {
+	point.id = debugger.nextID
+	debugger.nextID++
+	debugger.breakpoints = append(debugger.breakpoints, point)
+
+	fmt.Fprintln(debugger.Out, point)
+}
+
+func (point *breakpoint) String() string {
+	var text string
+	switch point.kind {
+	case breakAddress:
+		text = fmt.Sprintf("breakpoint %d at $%04X", point.id, point.address)
+	case breakOpcode:
+		text = fmt.Sprintf("breakpoint %d on opcode $%02X", point.id, point.address)
+	case watchMemory:
+		access := map[[2]bool]string{{true, false}: "reads", {false, true}: "writes", {true, true}: "reads and writes"}
+		text = fmt.Sprintf("watchpoint %d on %s of $%04X", point.id, access[[2]bool{point.read, point.write}], point.address)
+		if point.end != point.address {
+			text += fmt.Sprintf("-$%04X", point.end)
+		}
+	}
+
+	if point.condition != nil {
+		text += " if " + point.condition.text
+	}
+
+	return text
+}
+
+func deleteBreakpoint(debugger *Debugger, args []string) error {
+	if len(args) == 0 {
+		debugger.breakpoints = nil
+		return nil
+	}
+
+	id, err := parseNumber(args[0])
+	if err != nil {
+		return err
+	}
+
+	for i, point := range debugger.breakpoints {
+		if point.id == id {
+			debugger.breakpoints = append(debugger.breakpoints[:i], debugger.breakpoints[i+1:]...)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("there is no breakpoint %d", id)
+}
+
+func listBreakpoints(debugger *Debugger, args []string) error {
+	if len(debugger.breakpoints) == 0 {
+		fmt.Fprintln(debugger.Out, "no breakpoints or watchpoints")
+	}
+
+	for _, point := range debugger.breakpoints {
+		fmt.Fprintln(debugger.Out, point)
+	}
+
+	return nil
+}
+
+func showRegisters(debugger *Debugger, args []string) error {
+	processor := debugger.Console.CPU
+
+	flags := []byte("nv-bdizc")
+	for i := range flags {
+		if processor.Status()&(0x80>>i) != 0 {
+			flags[i] = strings.ToUpper(string(flags[i]))[0]
+		}
+	}
+
+	fmt.Fprintf(debugger.Out, "PC:%04X A:%02X X:%02X Y:%02X SP:%02X P:%02X %s CYC:%d\n",
+		processor.PC, processor.A, processor.X, processor.Y, processor.SP, processor.Status(), flags, processor.Cycles)
+
+	return nil
+}
+
+func setRegister(debugger *Debugger, args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("usage: set REG VALUE")
+	}
+
+	register, ok := registers[strings.ToUpper(args[0])]
+	if !ok {
+		return fmt.Errorf("there is no register %q", args[0])
+	}
+
+	value, err := parseNumber(args[1])
+	if err != nil {
+		return err
+	}
+	register.set(debugger, value)
+
+	return showRegisters(debugger, nil)
+}
+
+func hexdump(debugger *Debugger, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: mem ADDR [LEN]")
+	}
+
+	start, err := parseNumber(args[0])
+	if err != nil {
+		return err
+	}
+
+	length := 64
+	if len(args) > 1 {
+		if length, err = parseNumber(args[1]); err != nil {
+			return err
+		}
+	}
+
+	for row := start &^ 0xF; row < start+length && row <= 0xFFFF; row += 16 {
+		line := fmt.Sprintf("%04X ", row)
+		for address := row; address < row+16; address++ {
+			if address < start || address >= start+length || address > 0xFFFF {
+				line += "   "
+			} else if value, ok := debugger.peek(uint16(address)); ok {
+				line += fmt.Sprintf(" %02X", value)
+			} else {
+				line += " --"
+			}
+		}
+		fmt.Fprintln(debugger.Out, strings.TrimRight(line, " "))
+	}
+
+	return nil
+}
+
+func poke(debugger *Debugger, args []string) error {
+	if len(args) < 2 {
+		return fmt.Errorf("usage: poke ADDR VALUE...")
+	}
+
+	address, err := parseNumber(args[0])
+	if err != nil {
+		return err
+	}
+
+	for i, arg := range args[1:] {
+		value, err := parseNumber(arg)
+		if err != nil {
+			return err
+		}
+		if value > 0xFF {
+			return fmt.Errorf("%s is more than a byte", arg)
+		}
+		debugger.Console.Bus.Write(uint16(address+i), byte(value))
+	}
+
+	return nil
+}
+
+// disassemble lists the last few instructions the CPU ran before going
+// on from PC, because 6502 code can't be disassembled backwards.
+func disassemble(debugger *Debugger, args []string) error {
+	processor := debugger.Console.CPU
+	address, count := int(processor.PC), 8
+
+	var err error
+	if len(args) > 0 {
+		if address, err = parseNumber(args[0]); err != nil {
+			return err
+		}
+	} else {
+		history := processor.History()
+		for _, entry := range history[max(0, len(history)-3):] {
+			text, _ := debugger.disassemble(entry.PC)
+			fmt.Fprintln(debugger.Out, "  ", text)
+		}
+	}
+	if len(args) > 1 {
+		if count, err = parseNumber(args[1]); err != nil {
+			return err
+		}
+	}
+
+	for ; count > 0; count-- {
+		marker := "  "
+		if uint16(address) == processor.PC {
+			marker = "=>"
+		} else if point := debugger.breakpointAt(uint16(address)); point != nil && point.kind == breakAddress {
+			marker = " *"
+		}
+
+		text, size := debugger.disassemble(uint16(address))
+		fmt.Fprintln(debugger.Out, marker, text)
+		address = int(uint16(address) + size)
+	}
+
+	return nil
+}
+
+func showStack(debugger *Debugger, args []string) error {
+	if len(debugger.frames) == 0 {
+		fmt.Fprintln(debugger.Out, "not in a subroutine")
+	}
+
+	for i := len(debugger.frames) - 1; i >= 0; i-- {
+		frame := debugger.frames[i]
+		kind := "called"
+		if frame.interrupt {
+			kind = "interrupt"
+		}
+		fmt.Fprintf(debugger.Out, "#%d  $%04X  %s from $%04X\n", len(debugger.frames)-1-i, frame.to, kind, frame.from)
+	}
+
+	return nil
+}
+
+func reset(debugger *Debugger, args []string) error {
+	if err := debugger.Console.Reset(); err != nil {
+		return err
+	}
+	debugger.frames = nil
+	debugger.where()
+
+	return nil
+}
+
+func help(debugger *Debugger, args []string) error {
+	for _, command := range commands {
+		fmt.Fprintf(debugger.Out, "%-40s %s\n", command.usage, command.help)
+	}
+	fmt.Fprintln(debugger.Out, "Numbers are $hex, 0xhex, binary with a % prefix, or decimal. An empty line repeats the last command.")
+	fmt.Fprintln(debugger.Out, "COND compares registers, flags, [ADDR], numbers or VALUE, the byte a watchpoint saw: A == $FF")
+
+	return nil
+}
+
+func quit(debugger *Debugger, args []string) error {
+	return errQuit
+}
@@ -0,0 +1,162 @@
+package debugger
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// condition compares two operands, for example A == $FF or [$10] > 3.
+// An operand is a number, a register (A X Y SP PC P), a flag (N V D I Z
+// C), a byte of memory in square brackets, or VALUE, the byte a
+// watchpoint saw being read or written.
+type condition struct {
+	text        string
+	left, right operand
+	compare     func(left, right int) bool
+}
+
+type operand func(debugger *Debugger, value byte) int
+
+// two character operators come first so <= isn't taken for <
+var comparisons = []struct {
+	operator string
+	compare  func(left, right int) bool
+}{
+	{"==", func(left, right int) bool { return left == right }},
+	{"!=", func(left, right int) bool { return left != right }},
+	{"<=", func(left, right int) bool { return left <= right }},
+	{">=", func(left, right int) bool { return left >= right }},
+	{"<", func(left, right int) bool { return left < right }},
+	{">", func(left, right int) bool { return left > right }},
+}
+
+func parseCondition(text string) (*condition, error) {
+	for _, comparison := range comparisons {
+		i := strings.Index(text, comparison.operator)
+		if i < 0 {
+			continue
+		}
+
+		left, err := parseOperand(text[:i])
+		if err != nil {
+			return nil, err
+		}
+		right, err := parseOperand(text[i+len(comparison.operator):])
+		if err != nil {
+			return nil, err
+		}
+
+		return &condition{text: strings.TrimSpace(text), left: left, right: right, compare: comparison.compare}, nil
+	}
+
+	return nil, fmt.Errorf("condition %q has no comparison", text)
+}
+
+func (condition *condition) eval(debugger *Debugger, value byte) bool {
+	return condition.compare(condition.left(debugger, value), condition.right(debugger, value))
+}
+
+func parseOperand(text string) (operand, error) {
+	text = strings.TrimSpace(text)
+
+	if strings.HasPrefix(text, "[") && strings.HasSuffix(text, "]") {
+		address, err := parseNumber(text[1 : len(text)-1])
+		if err != nil {
+			return nil, err
+		}
+
+		return func(debugger *Debugger, value byte) int {
+			memory, _ := debugger.peek(uint16(address))
+			return int(memory)
+		}, nil
+	}
+
+	if strings.ToUpper(text) == "VALUE" {
+		return func(debugger *Debugger, value byte) int { return int(value) }, nil
+	}
+
+	if register, ok := registers[strings.ToUpper(text)]; ok {
+		return func(debugger *Debugger, value byte) int { return register.get(debugger) }, nil
+	}
+
+	number, err := parseNumber(text)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(debugger *Debugger, value byte) int { return number }, nil
+}
+
+// parseNumber reads $FF or 0xFF as hex, %1010 as binary and anything
+// else as decimal.
+func parseNumber(text string) (int, error) {
+	text = strings.TrimSpace(text)
+	base, digits := 10, text
+
+	switch {
+	case strings.HasPrefix(text, "$"):
+		base, digits = 16, text[1:]
+	case strings.HasPrefix(strings.ToLower(text), "0x"):
+		base, digits = 16, text[2:]
+	case strings.HasPrefix(text, "%"):
+		base, digits = 2, text[1:]
+	}
+
+	number, err := strconv.ParseUint(digits, base, 16)
+	if err != nil {
+		return 0, fmt.Errorf("bad number %q", text)
+	}
+
+	return int(number), nil
+}
+
+type register struct {
+	get func(debugger *Debugger) int
+	set func(debugger *Debugger, value int)
+}
+
+func flag(field func(debugger *Debugger) *bool) register {
+	return register{
+		get: func(debugger *Debugger) int {
+			if *field(debugger) {
+				return 1
+			}
+			return 0
+		},
+		set: func(debugger *Debugger, value int) { *field(debugger) = value != 0 },
+	}
+}
+
+var registers = map[string]register{
+	"A": {
+		func(debugger *Debugger) int { return int(debugger.Console.CPU.A) },
+		func(debugger *Debugger, value int) { debugger.Console.CPU.A = byte(value) },
+	},
+	"X": {
+		func(debugger *Debugger) int { return int(debugger.Console.CPU.X) },
+		func(debugger *Debugger, value int) { debugger.Console.CPU.X = byte(value) },
+	},
+	"Y": {
+		func(debugger *Debugger) int { return int(debugger.Console.CPU.Y) },
+		func(debugger *Debugger, value int) { debugger.Console.CPU.Y = byte(value) },
+	},
+	"SP": {
+		func(debugger *Debugger) int { return int(debugger.Console.CPU.SP) },
+		func(debugger *Debugger, value int) { debugger.Console.CPU.SP = byte(value) },
+	},
+	"PC": {
+		func(debugger *Debugger) int { return int(debugger.Console.CPU.PC) },
+		func(debugger *Debugger, value int) { debugger.Console.CPU.PC = uint16(value) },
+	},
+	"P": {
+		func(debugger *Debugger) int { return int(debugger.Console.CPU.Status()) },
+		func(debugger *Debugger, value int) { debugger.Console.CPU.SetStatus(byte(value)) },
+	},
+	"N": flag(func(debugger *Debugger) *bool { return &debugger.Console.CPU.NFlag }),
+	"V": flag(func(debugger *Debugger) *bool { return &debugger.Console.CPU.VFlag }),
+	"D": flag(func(debugger *Debugger) *bool { return &debugger.Console.CPU.DFlag }),
+	"I": flag(func(debugger *Debugger) *bool { return &debugger.Console.CPU.IFlag }),
+	"Z": flag(func(debugger *Debugger) *bool { return &debugger.Console.CPU.ZFlag }),
+	"C": flag(func(debugger *Debugger) *bool { return &debugger.Console.CPU.CFlag }),
+}
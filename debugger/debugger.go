@@ -0,0 +1,295 @@
+// Package debugger is an interactive debugger for a Console. It is driven
+// by lines of text, so it works over a plain terminal or a pipe.
+package debugger
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/DevinRiley/nes"
+	"github.com/DevinRiley/nes/cpu"
+)
+
+// ErrHalted is returned when asked to run a CPU that a KIL opcode has
+// stopped. Only a reset starts it again.
+var ErrHalted = errors.New("the CPU is halted")
+
+var errQuit = errors.New("quit")
+
+// maxStallCycles is longer than any DMA, so an instruction that still
+// hasn't started after this many cycles never will
+const maxStallCycles = 1024
+
+const opJSR = 0x20
+
+// Debugger controls a console one instruction at a time. It stops before
+// an instruction at a breakpoint, and after an instruction that touches
+// memory under a watchpoint.
+type Debugger struct {
+	Console *nes.Console
+	Out     io.Writer
+
+	breakpoints []*breakpoint
+	nextID      int
+	frames      []frame // innermost last
+	hit         string  // the watchpoint hit by the current instruction
+	last        string  // command repeated by an empty line
+}
+
+type breakpointKind int
+
+const (
+	breakAddress breakpointKind = iota
+	breakOpcode
+	watchMemory
+)
+
+type breakpoint struct {
+	id        int
+	kind      breakpointKind
+	address   uint16 // start of the watched range, or the opcode
+	end       uint16
+	read      bool
+	write     bool
+	condition *condition
+}
+
+// frame is a subroutine call or interrupt that hasn't returned yet
+type frame struct {
+	from      uint16
+	to        uint16
+	sp        byte // the stack pointer before the call
+	interrupt bool
+}
+
+// New debugs a console that has a cartridge loaded. The CPU's bus is
+// wrapped to watch memory, so New has to be called again after the
+// console is power cycled or reloaded, which replaces the CPU.
+func New(console *nes.Console, out io.Writer) *Debugger {
+	debugger := &Debugger{Console: console, Out: out, nextID: 1}
+	console.CPU.Bus = &watchBus{Bus: console.CPU.Bus, debugger: debugger}
+
+	return debugger
+}
+
+// Run reads commands from in until it ends or a quit command, writing a
+// prompt before each one. Errors from commands are printed, not returned.
+func (debugger *Debugger) Run(in io.Reader) error {
+	scanner := bufio.NewScanner(in)
+
+	debugger.where()
+	fmt.Fprint(debugger.Out, "(nes) ")
+	for scanner.Scan() {
+		if err := debugger.Command(scanner.Text()); errors.Is(err, errQuit) {
+			return nil
+		} else if err != nil {
+			fmt.Fprintln(debugger.Out, "error:", err)
+		}
+		fmt.Fprint(debugger.Out, "(nes) ")
+	}
+
+	return scanner.Err()
+}
+
+// Command runs a single command. An empty line repeats the last one.
+func (debugger *Debugger) Command(line string) error {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		line = debugger.last
+	}
+	debugger.last = line
+
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return nil
+	}
+
+	for _, command := range commands {
+		for _, name := range command.names {
+			if name == strings.ToLower(fields[0]) {
+				return command.run(debugger, fields[1:])
+			}
+		}
+	}
+
+	return fmt.Errorf("unknown command %q, try help", fields[0])
+}
+
+// run executes instructions until done returns true, a breakpoint or
+// watchpoint is hit, or the CPU stops. The first instruction always
+// runs, so that continuing from a breakpoint doesn't stop there again.
+func (debugger *Debugger) run(done func() bool) error {
+	defer debugger.where()
+
+	for first := true; ; first = false {
+		if !first {
+			if point := debugger.breakpointAt(debugger.Console.CPU.PC); point != nil {
+				fmt.Fprintf(debugger.Out, "breakpoint %d\n", point.id)
+				return nil
+			}
+		}
+
+		if err := debugger.execute(); err != nil {
+			return err
+		}
+
+		if debugger.hit != "" {
+			fmt.Fprintln(debugger.Out, debugger.hit)
+			return nil
+		}
+		if done != nil && done() {
+			return nil
+		}
+	}
+}
+
+// execute runs one instruction, or services one interrupt, and keeps the
+// call stack up to date. Cycles the CPU spends stalled by DMA are run
+// through as part of the instruction that follows them.
+func (debugger *Debugger) execute() error {
+	processor := debugger.Console.CPU
+	debugger.hit = ""
+
+	for stalled := 0; stalled < maxStallCycles; stalled++ {
+		if processor.Halted {
+			return ErrHalted
+		}
+
+		pc, sp, cycles := processor.PC, processor.SP, processor.Cycles
+		opcode, _ := debugger.peek(pc)
+
+		if err := debugger.Console.StepInstruction(); err != nil {
+			return err
+		}
+
+		if history := processor.History(); len(history) > 0 && history[len(history)-1].Cycles == cycles {
+			debugger.track(pc, opcode, sp)
+			return nil
+		}
+		if processor.PC != pc {
+			debugger.frames = append(debugger.frames, frame{from: pc, to: processor.PC, sp: sp, interrupt: true})
+			return nil
+		}
+	}
+
+	return fmt.Errorf("the CPU is stuck at $%04X", processor.PC)
+}
+
+// track follows the stack pointer to see which calls have returned. RTS
+// and RTI are the usual way out, but code that adjusts the stack itself
+// to return early is followed too.
+func (debugger *Debugger) track(pc uint16, opcode byte, sp byte) {
+	processor := debugger.Console.CPU
+
+	if opcode == opJSR {
+		debugger.frames = append(debugger.frames, frame{from: pc, to: processor.PC, sp: sp})
+		return
+	}
+
+	for n := len(debugger.frames); n > 0 && processor.SP >= debugger.frames[n-1].sp; n-- {
+		debugger.frames = debugger.frames[:n-1]
+	}
+}
+
+func (debugger *Debugger) breakpointAt(pc uint16) *breakpoint {
+	for _, point := range debugger.breakpoints {
+		switch {
+		case point.kind == breakAddress && point.address == pc:
+		case point.kind == breakOpcode && debugger.opcodeAt(pc) == point.address:
+		default:
+			continue
+		}
+
+		if point.condition == nil || point.condition.eval(debugger, 0) {
+			return point
+		}
+	}
+
+	return nil
+}
+
+func (debugger *Debugger) opcodeAt(pc uint16) uint16 {
+	opcode, _ := debugger.peek(pc)
+	return uint16(opcode)
+}
+
+// access checks a CPU bus access against the watchpoints. Only the first
+// hit of an instruction is reported.
+func (debugger *Debugger) access(address uint16, value byte, write bool) {
+	if debugger.hit != "" {
+		return
+	}
+
+	for _, point := range debugger.breakpoints {
+		if point.kind != watchMemory || address < point.address || address > point.end {
+			continue
+		}
+		if write && !point.write || !write && !point.read {
+			continue
+		}
+		if point.condition != nil && !point.condition.eval(debugger, value) {
+			continue
+		}
+
+		access := "read"
+		if write {
+			access = "write"
+		}
+		debugger.hit = fmt.Sprintf("watchpoint %d: %s $%04X = $%02X", point.id, access, address, value)
+		return
+	}
+}
+
+// peek reads memory for the debugger itself. The bus doesn't peek the
+// PPU and APU registers, so ok is false for them.
+func (debugger *Debugger) peek(address uint16) (value byte, ok bool) {
+	if address >= 0x2000 && address < 0x4020 {
+		return 0, false
+	}
+
+	return debugger.Console.Bus.Peek(address), true
+}
+
+// where prints the instruction about to run and the registers
+func (debugger *Debugger) where() {
+	processor := debugger.Console.CPU
+	text, _ := debugger.disassemble(processor.PC)
+
+	fmt.Fprintf(debugger.Out, "%-28s A:%02X X:%02X Y:%02X P:%02X SP:%02X CYC:%d\n",
+		text, processor.A, processor.X, processor.Y, processor.Status(), processor.SP, processor.Cycles)
+}
+
+// disassemble lists the instruction at address, returning its length
+func (debugger *Debugger) disassemble(address uint16) (string, uint16) {
+	disassembly := cpu.Disassemble(debugger.Console.Bus, address)
+	return disassembly.Listing(nil), uint16(len(disassembly.Bytes))
+}
+
+// watchBus reports every access the CPU makes to the debugger
+type watchBus struct {
+	cpu.Bus
+	debugger *Debugger
+}
+
+func (bus *watchBus) Read(address uint16) byte {
+	value := bus.Bus.Read(address)
+	bus.debugger.access(address, value, false)
+	return value
+}
+
+func (bus *watchBus) Write(address uint16, value byte) {
+	bus.Bus.Write(address, value)
+	bus.debugger.access(address, value, true)
+}
+
+// Peek lets tracers see through the watch without setting it off
+func (bus *watchBus) Peek(address uint16) byte {
+	if peeker, ok := bus.Bus.(cpu.Peeker); ok {
+		return peeker.Peek(address)
+	}
+
+	return bus.Bus.Read(address)
+}
@@ -0,0 +1,189 @@
+package debugger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/DevinRiley/nes"
+	"github.com/DevinRiley/nes/cartridge"
+	"github.com/DevinRiley/nes/cpu"
+)
+
+// testProgram calls a subroutine that sets A and counts X, stores A and
+// then loops forever
+const testProgram = `
+		.org $8000
+		LDX #$00
+		JSR sub
+		STA $10
+@loop:	JMP @loop
+		.org $8010
+sub:	LDA #$FF
+		INX
+		RTS
+		.org $FFFC
+		.word $8000
+`
+
+func newTestDebugger(t *testing.T) (*Debugger, *bytes.Buffer) {
+	program, err := cpu.Assemble(testProgram)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rom := &cartridge.ROM{PRGSize: 0x8000, PRGData: program.Image(0x8000, 0x8000)}
+
+	console := nes.NewConsole()
+	if err := console.Load(rom); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	return New(console, &out), &out
+}
+
+func run(t *testing.T, debugger *Debugger, commands ...string) {
+	for _, command := range commands {
+		if err := debugger.Command(command); err != nil {
+			t.Fatal(command, err)
+		}
+	}
+}
+
+func TestDebuggerStep(t *testing.T) {
+	debugger, _ := newTestDebugger(t)
+	run(t, debugger, "step 2", "")
+
+	if debugger.Console.CPU.PC != 0x8013 {
+		t.Errorf("did not step 4 instructions, PC is %04X", debugger.Console.CPU.PC)
+	}
+}
+
+func TestDebuggerNext(t *testing.T) {
+	debugger, _ := newTestDebugger(t)
+	run(t, debugger, "next", "next")
+
+	if debugger.Console.CPU.PC != 0x8005 || debugger.Console.CPU.X != 1 {
+		t.Errorf("did not step over the subroutine, PC is %04X", debugger.Console.CPU.PC)
+	}
+}
+
+func TestDebuggerBreakpoint(t *testing.T) {
+	debugger, out := newTestDebugger(t)
+	run(t, debugger, "break $8012", "continue")
+
+	if debugger.Console.CPU.PC != 0x8012 || !strings.Contains(out.String(), "breakpoint 1\n") {
+		t.Errorf("did not stop at the breakpoint, PC is %04X", debugger.Console.CPU.PC)
+	}
+}
+
+func TestDebuggerOpcodeBreakpoint(t *testing.T) {
+	debugger, _ := newTestDebugger(t)
+	run(t, debugger, "break op $60 if X == 1", "continue")
+
+	if debugger.Console.CPU.PC != 0x8013 {
+		t.Errorf("did not stop before RTS, PC is %04X", debugger.Console.CPU.PC)
+	}
+}
+
+func TestDebuggerWatchpoint(t *testing.T) {
+	debugger, out := newTestDebugger(t)
+	run(t, debugger, "watch w $10 if A == $FF", "continue")
+
+	if debugger.Console.CPU.PC != 0x8007 || !strings.Contains(out.String(), "watchpoint 1: write $0010 = $FF") {
+		t.Errorf("did not stop after the write, PC is %04X", debugger.Console.CPU.PC)
+	}
+}
+
+func TestDebuggerWatchpointCondition(t *testing.T) {
+	debugger, _ := newTestDebugger(t)
+	run(t, debugger, "watch w $10 if VALUE == 0", "break $8007", "continue")
+
+	if debugger.Console.CPU.PC != 0x8007 || debugger.hit != "" {
+		t.Error("stopped for a watchpoint whose condition was false")
+	}
+}
+
+func TestDebuggerStack(t *testing.T) {
+	debugger, out := newTestDebugger(t)
+	run(t, debugger, "break $8012", "continue", "stack")
+
+	if !strings.Contains(out.String(), "#0  $8010  called from $8002") {
+		t.Error("did not show the call, got", out.String())
+	}
+
+	run(t, debugger, "finish")
+	if len(debugger.frames) != 0 || debugger.Console.CPU.PC != 0x8005 {
+		t.Error("did not return from the subroutine")
+	}
+}
+
+func TestDebuggerSetRegisters(t *testing.T) {
+	debugger, _ := newTestDebugger(t)
+	run(t, debugger, "set A $42", "set c 1", "set PC 0x8010")
+
+	processor := debugger.Console.CPU
+	if processor.A != 0x42 || !processor.CFlag || processor.PC != 0x8010 {
+		t.Error("did not set the registers")
+	}
+}
+
+func TestDebuggerMemory(t *testing.T) {
+	debugger, out := newTestDebugger(t)
+	run(t, debugger, "poke $0203 1 %10 $FF", "mem $0203 3")
+
+	if !strings.Contains(out.String(), "0200           01 02 FF\n") {
+		t.Error("did not dump the poked bytes, got", out.String())
+	}
+}
+
+func TestDebuggerDisassemble(t *testing.T) {
+	debugger, out := newTestDebugger(t)
+	run(t, debugger, "disasm $8000 3")
+
+	expected := "=> 8000  A2 00     LDX #$00\n" +
+		"   8002  20 10 80  JSR $8010\n" +
+		"   8005  85 10     STA $10\n"
+	if out.String() != expected {
+		t.Error("did not disassemble the program, got", out.String())
+	}
+}
+
+func TestDebuggerRun(t *testing.T) {
+	debugger, out := newTestDebugger(t)
+
+	if err := debugger.Run(strings.NewReader("bogus\nquit\nstep\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(out.String(), `error: unknown command "bogus"`) || debugger.Console.CPU.PC != 0x8000 {
+		t.Error("did not stop at quit, got", out.String())
+	}
+}
+
+func TestParseCondition(t *testing.T) {
+	debugger, _ := newTestDebugger(t)
+	debugger.Console.CPU.A = 0xFF
+
+	for text, expected := range map[string]bool{
+		"A == $FF":       true,
+		"A != 255":       false,
+		"X < %1":         true,
+		"PC >= $8000":    true,
+		"[$8000] == $A2": true,
+		"VALUE > 3":      true,
+		"Z <= 0":         true,
+	} {
+		condition, err := parseCondition(text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if condition.eval(debugger, 4) != expected {
+			t.Error("did not evaluate", text, "to", expected)
+		}
+	}
+
+	if _, err := parseCondition("A = 1"); err == nil {
+		t.Error("did not reject a condition without a comparison")
+	}
+}
@@ -1,99 +1,40 @@
-package main
+package nes
 
 import (
-	"bufio"
-	"fmt"
 	"os"
-	"strconv"
 	"testing"
+
+	"github.com/DevinRiley/nes/cartridge"
 )
 
-func setupCpu() *CPU {
-	cpu := NewCPU()
-	cpu.Debug = true
-	loadTestRom(cpu)
+// nestestConsole runs nestest's automated test, which is what nestest.log
+// was recorded from
+func nestestConsole(t *testing.T) *Console {
+	rom, err := cartridge.LoadFile("nestest.nes")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// nestest's reset vector starts the interactive menu, the automated
+	// test starts at $C000 instead
+	console := newTestConsole(t, rom)
+	console.CPU.PC = 0xC000
 
-	return cpu
+	return console
 }
 
 func TestFixtureRom(t *testing.T) {
-	var pc, a, x, y, sp, flags uint64
-	var fail = false
-
-	// Read File
-	logFile, err := os.Open("nestest.log")
+	log, err := os.Open("nestest.log")
 	if err != nil {
-		panic(err)
+		t.Fatal(err)
 	}
-	defer logFile.Close()
-
-	scanner := bufio.NewScanner(logFile)
-
-	cpu := setupCpu()
-
-	for scanner.Scan() {
-
-		line := scanner.Text()
-
-		if pc, err = strconv.ParseUint(line[0:4], 16, 64); err != nil {
-			fmt.Println("Error parsing pc")
-		}
-
-		if a, err = strconv.ParseUint(line[50:52], 16, 64); err != nil {
-			fmt.Println("Error parsing accumulator")
-		}
-
-		if x, err = strconv.ParseUint(line[55:57], 16, 64); err != nil {
-			fmt.Println("Error parsing x register")
-		}
-
-		if y, err = strconv.ParseUint(line[60:62], 16, 64); err != nil {
-			fmt.Println("Error parsing y register")
-		}
-
-		if flags, err = strconv.ParseUint(line[65:67], 16, 64); err != nil {
-			fmt.Println("Error parsing flags values")
-		}
-
-		if sp, err = strconv.ParseUint(line[71:73], 16, 64); err != nil {
-			fmt.Println("Error parsing stack pointer values")
-		}
-
-		if a != uint64(cpu.A) {
-			fmt.Printf("Accumulator value wrong, expected %X but got %X\n", a, cpu.A)
-			fail = true
-		}
-
-		if pc != uint64(cpu.PC) {
-			fmt.Printf("PC value wrong, expected %X but got %X\n", pc, cpu.PC)
-			fail = true
-		}
-
-		if x != uint64(cpu.X) {
-			fmt.Printf("X value wrong, expected %X but got %X\n", x, cpu.X)
-			fail = true
-		}
-
-		if y != uint64(cpu.Y) {
-			fmt.Printf("Y value wrong, expected %X but got %X\n", y, cpu.Y)
-			fail = true
-		}
-
-		if flags != uint64(cpu.flagsToByte()) {
-			fmt.Printf("Flags value wrong, expected %X but got %X\n", flags, cpu.flagsToByte())
-			fail = true
-		}
-
-		if sp != uint64(cpu.SP) {
-			fmt.Printf("SP value wrong, expected %X but got %X\n", sp, cpu.SP)
-			fail = true
-		}
-
-		cpu.Exec()
-
-		if fail {
-			os.Exit(1)
-		}
+	defer log.Close()
 
+	divergence, err := DiffTrace(nestestConsole(t), log, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if divergence != nil {
+		t.Error("did not follow nestest.log,", divergence)
 	}
 }
@@ -0,0 +1,3 @@
+module github.com/DevinRiley/nes
+
+go 1.23
@@ -0,0 +1,746 @@
+// Package ppu emulates the NES's 2C02 picture processing unit.
+package ppu
+
+import "github.com/DevinRiley/nes/cartridge"
+
+// Dimensions of the picture the PPU produces
+const (
+	ScreenWidth  = 256
+	ScreenHeight = 240
+)
+
+// PPU timing, in dots (PPU cycles) and scanlines
+const (
+	DotsPerScanline    = 341
+	ScanlinesPerFrame  = 262
+	VBlankScanline     = 241
+	PreRenderScanline  = 261
+	visibleScanlines   = 240
+	visibleDots        = 256
+	spriteFetchDot     = 257
+	backgroundFetchDot = 321
+)
+
+// PPUCTRL ($2000) bits
+const (
+	ctrlIncrement32     = 0x04
+	ctrlSpriteTable     = 0x08
+	ctrlBackgroundTable = 0x10
+	ctrlSpriteSize16    = 0x20
+	ctrlNMI             = 0x80
+)
+
+// PPUMASK ($2001) bits
+const (
+	maskGrayscale      = 0x01
+	maskBackgroundLeft = 0x02
+	maskSpritesLeft    = 0x04
+	maskBackground     = 0x08
+	maskSprites        = 0x10
+)
+
+// PPUSTATUS ($2002) bits
+const (
+	statusSpriteOverflow = 0x20
+	statusSpriteZeroHit  = 0x40
+	statusVBlank         = 0x80
+)
+
+// sprite attribute bits
+const (
+	spritePalette        = 0x03
+	spriteBehind         = 0x20
+	spriteFlipHorizontal = 0x40
+	spriteFlipVertical   = 0x80
+)
+
+// PPU is the 2C02 picture processing unit. It sits on the CPU bus at
+// $2000-$2007 and has its own 14 bit address space:
+//
+// $0000-$1FFF  Pattern tables, provided by the cartridge (CHR)
+// $2000-$2FFF  Nametables, internal VRAM mirrored by the cartridge
+// $3000-$3EFF  Mirrors of $2000-$2EFF
+// $3F00-$3F1F  Palette RAM
+// $3F20-$3FFF  Mirrors of $3F00-$3F1F
+//
+// The nametables are 2KB of VRAM mirrored as the cartridge's mapper
+// selects, or 4KB with four-screen VRAM. Step advances the PPU by one
+// dot, and every visible dot writes a palette index (0-63) into
+// FrameBuffer.
+type PPU struct {
+	Cartridge cartridge.Mapper // provides the pattern tables and nametable mirroring
+
+	// NMI is called when the PPU asserts the CPU's NMI line, that is
+	// when vblank starts with NMI enabled, or NMI is enabled during vblank
+	NMI func()
+
+	FrameBuffer [ScreenWidth * ScreenHeight]byte
+	Frames      uint64 // number of frames completed
+	Scanline    int    // 0-239 visible, 240 post-render, 241-260 vblank, 261 pre-render
+	Dot         int    // 0-340
+
+	ctrl       byte
+	mask       byte
+	status     byte
+	oamAddress byte
+	oam        [256]byte
+	nametables [0x1000]byte
+	palette    [32]byte
+
+	// loopy scroll registers: v is the current VRAM address, t the
+	// temporary address, x the fine X scroll and w the write toggle
+	// shared by $2005 and $2006
+	v uint16
+	t uint16
+	x byte
+	w bool
+
+	readBuffer     byte // $2007 reads are delayed by one read
+	openBus        byte // the last value written to any PPU register
+	nmiOutput      bool
+	suppressVBlank bool
+	oddFrame       bool
+
+	// background fetches for the next tile, and the shift registers
+	// holding two tiles worth of 4 bit (attribute, pattern) pixels
+	nametableByte byte
+	attributeByte byte
+	lowTileByte   byte
+	highTileByte  byte
+	tileData      uint64
+
+	// sprites found by evaluation for the next scanline
+	sprites          [8]sprite
+	spriteCount      int
+	nextSpriteZero   bool
+	spriteZeroOnLine bool
+	spritePatternLow byte
+}
+
+type sprite struct {
+	y          byte
+	tile       byte
+	attributes byte
+	x          byte
+	low        byte
+	high       byte
+}
+
+func NewPPU(cartridge cartridge.Mapper) *PPU {
+	ppu := &PPU{Cartridge: cartridge}
+	ppu.PowerOn()
+
+	return ppu
+}
+
+// PowerOn puts the PPU into its power up state. VRAM, OAM and palette
+// contents are left alone as they are unspecified on hardware.
+func (ppu *PPU) PowerOn() {
+	ppu.status = 0
+	ppu.oamAddress = 0
+	ppu.v = 0
+	ppu.Reset()
+}
+
+// Reset is the PPU's response to the reset button. Scrolling, PPUCTRL and
+// PPUMASK are cleared and the frame starts over, while VRAM, OAM, the
+// VRAM address and PPUSTATUS are unaffected.
+func (ppu *PPU) Reset() {
+	ppu.ctrl = 0
+	ppu.mask = 0
+	ppu.t = 0
+	ppu.x = 0
+	ppu.w = false
+	ppu.readBuffer = 0
+	ppu.oddFrame = false
+	ppu.nmiOutput = false
+	ppu.Scanline = 0
+	ppu.Dot = 0
+}
+
+// Read handles CPU reads of the PPU registers at $2000-$2007. Write only
+// registers read back whatever was last written to the PPU.
+func (ppu *PPU) Read(address uint16) byte {
+	switch address & 0x0007 {
+	case 2:
+		ppu.openBus = ppu.status&0xE0 | ppu.openBus&0x1F
+		ppu.status &^= statusVBlank
+		ppu.nmiChange()
+		ppu.w = false
+
+		// reading one dot before vblank starts means it never does
+		if ppu.Scanline == VBlankScanline && ppu.Dot == 0 {
+			ppu.suppressVBlank = true
+		}
+	case 4:
+		ppu.openBus = ppu.readOAM()
+	case 7:
+		ppu.openBus = ppu.readData()
+	}
+
+	return ppu.openBus
+}
+
+// Write handles CPU writes to the PPU registers at $2000-$2007.
+func (ppu *PPU) Write(address uint16, value byte) {
+	ppu.openBus = value
+
+	switch address & 0x0007 {
+	case 0:
+		ppu.ctrl = value
+		ppu.t = ppu.t&0xF3FF | uint16(value&0x03)<<10
+		ppu.nmiChange()
+	case 1:
+		ppu.mask = value
+	case 3:
+		ppu.oamAddress = value
+	case 4:
+		ppu.writeOAM(value)
+	case 5:
+		if !ppu.w {
+			ppu.t = ppu.t&0xFFE0 | uint16(value)>>3
+			ppu.x = value & 0x07
+		} else {
+			ppu.t = ppu.t&0x8C1F | uint16(value&0x07)<<12 | uint16(value&0xF8)<<2
+		}
+		ppu.w = !ppu.w
+	case 6:
+		if !ppu.w {
+			ppu.t = ppu.t&0x80FF | uint16(value&0x3F)<<8
+		} else {
+			ppu.t = ppu.t&0xFF00 | uint16(value)
+			ppu.v = ppu.t
+		}
+		ppu.w = !ppu.w
+	case 7:
+		ppu.write(ppu.v, value)
+		ppu.incrementAddress()
+	}
+}
+
+// WriteOAM writes the next byte of sprite memory, the same as a write to
+// OAMDATA. OAM DMA ($4014) is a series of these.
+func (ppu *PPU) WriteOAM(value byte) {
+	ppu.writeOAM(value)
+}
+
+func (ppu *PPU) readOAM() byte {
+	// secondary OAM is being cleared, which reads as $FF
+	if ppu.renderingEnabled() && ppu.Scanline < visibleScanlines && ppu.Dot >= 1 && ppu.Dot <= 64 {
+		return 0xFF
+	}
+
+	value := ppu.oam[ppu.oamAddress]
+	if ppu.oamAddress&0x03 == 2 {
+		// the unimplemented attribute bits read back as 0
+		value &= 0xE3
+	}
+
+	return value
+}
+
+func (ppu *PPU) writeOAM(value byte) {
+	if ppu.renderingEnabled() && ppu.renderLine() {
+		// writes during rendering don't reach OAM, but do bump the
+		// high 6 bits of the address
+		ppu.oamAddress += 4
+		return
+	}
+
+	ppu.oam[ppu.oamAddress] = value
+	ppu.oamAddress++
+}
+
+func (ppu *PPU) readData() byte {
+	address := ppu.v & 0x3FFF
+	var value byte
+
+	if address < 0x3F00 {
+		value = ppu.readBuffer
+		ppu.readBuffer = ppu.read(address)
+	} else {
+		// palette reads are immediate, but the nametable byte
+		// "underneath" the palette still ends up in the buffer
+		value = ppu.read(address) | ppu.openBus&0xC0
+		ppu.readBuffer = ppu.read(address - 0x1000)
+	}
+
+	ppu.incrementAddress()
+	return value
+}
+
+func (ppu *PPU) incrementAddress() {
+	if ppu.renderingEnabled() && ppu.renderLine() {
+		// during rendering $2007 accesses clock both scroll counters
+		ppu.incrementX()
+		ppu.incrementY()
+		return
+	}
+
+	if ppu.ctrl&ctrlIncrement32 != 0 {
+		ppu.v += 32
+	} else {
+		ppu.v++
+	}
+	ppu.v &= 0x7FFF
+}
+
+// nmiChange updates the NMI output, which is the vblank flag ANDed with
+// the NMI enable bit, and signals the CPU on a rising edge.
+func (ppu *PPU) nmiChange() {
+	nmi := ppu.ctrl&ctrlNMI != 0 && ppu.status&statusVBlank != 0
+	if nmi && !ppu.nmiOutput && ppu.NMI != nil {
+		ppu.NMI()
+	}
+	ppu.nmiOutput = nmi
+}
+
+// read reads the PPU's own address space
+func (ppu *PPU) read(address uint16) byte {
+	address &= 0x3FFF
+
+	switch {
+	case address < 0x2000:
+		if ppu.Cartridge != nil {
+			return ppu.Cartridge.ReadPPU(address)
+		}
+		return 0
+	case address < 0x3F00:
+		return ppu.nametables[ppu.nametableAddress(address)]
+	default:
+		return ppu.readPalette(address)
+	}
+}
+
+func (ppu *PPU) write(address uint16, value byte) {
+	address &= 0x3FFF
+
+	switch {
+	case address < 0x2000:
+		if ppu.Cartridge != nil {
+			ppu.Cartridge.WritePPU(address, value)
+		}
+	case address < 0x3F00:
+		ppu.nametables[ppu.nametableAddress(address)] = value
+	default:
+		ppu.palette[paletteAddress(address)] = value & 0x3F
+	}
+}
+
+// nametableAddress maps $2000-$3EFF onto the internal VRAM. Mirroring
+// follows the header's naming (see cartridge.Mirroring), which describes
+// how the nametables are arranged: Vertical stacks $2000 above $2800 and
+// mirrors $2400 onto $2000 (CIRAM A10 = PPU A11), Horizontal puts $2000
+// beside $2400 and mirrors $2800 onto $2000 (CIRAM A10 = PPU A10).
+func (ppu *PPU) nametableAddress(address uint16) uint16 {
+	address &= 0x0FFF
+
+	mirroring := cartridge.Vertical
+	if ppu.Cartridge != nil {
+		mirroring = ppu.Cartridge.Mirroring()
+	}
+
+	switch mirroring {
+	case cartridge.FourScreen:
+		return address
+	case cartridge.SingleScreenLower:
+		return address & 0x03FF
+	case cartridge.SingleScreenUpper:
+		return 0x0400 | address&0x03FF
+	case cartridge.Vertical:
+		return address&0x0800>>1 | address&0x03FF
+	default:
+		return address & 0x07FF
+	}
+}
+
+func (ppu *PPU) readPalette(address uint16) byte {
+	value := ppu.palette[paletteAddress(address)]
+	if ppu.mask&maskGrayscale != 0 {
+		value &= 0x30
+	}
+
+	return value
+}
+
+// paletteAddress maps $3F00-$3FFF onto the 32 bytes of palette RAM. The
+// backdrop entries of the sprite palettes ($3F10/$3F14/$3F18/$3F1C) are
+// mirrors of the background ones.
+func paletteAddress(address uint16) uint16 {
+	address &= 0x1F
+	if address&0x13 == 0x10 {
+		address &^= 0x10
+	}
+
+	return address
+}
+
+func (ppu *PPU) renderingEnabled() bool {
+	return ppu.mask&(maskBackground|maskSprites) != 0
+}
+
+func (ppu *PPU) renderLine() bool {
+	return ppu.Scanline < visibleScanlines || ppu.Scanline == PreRenderScanline
+}
+
+// Step advances the PPU by a single dot.
+func (ppu *PPU) Step() {
+	ppu.tick()
+
+	rendering := ppu.renderingEnabled()
+	visibleLine := ppu.Scanline < visibleScanlines
+	preLine := ppu.Scanline == PreRenderScanline
+	renderLine := visibleLine || preLine
+	visibleDot := ppu.Dot >= 1 && ppu.Dot <= visibleDots
+	fetchDot := visibleDot || (ppu.Dot >= backgroundFetchDot && ppu.Dot <= 336)
+
+	if visibleLine && visibleDot {
+		if rendering {
+			ppu.renderPixel()
+		} else {
+			ppu.renderBackdrop()
+		}
+	}
+
+	if rendering && renderLine {
+		if fetchDot {
+			ppu.tileData <<= 4
+			switch ppu.Dot % 8 {
+			case 1:
+				ppu.fetchNametableByte()
+			case 3:
+				ppu.fetchAttributeByte()
+			case 5:
+				ppu.lowTileByte = ppu.read(ppu.backgroundPatternAddress())
+			case 7:
+				ppu.highTileByte = ppu.read(ppu.backgroundPatternAddress() + 8)
+			case 0:
+				ppu.storeTileData()
+				ppu.incrementX()
+			}
+		}
+
+		if ppu.Dot == visibleDots {
+			ppu.incrementY()
+		}
+
+		if ppu.Dot == spriteFetchDot {
+			ppu.copyX()
+			if visibleLine {
+				ppu.evaluateSprites()
+			} else {
+				ppu.spriteCount = 0
+				ppu.nextSpriteZero = false
+			}
+		}
+
+		if ppu.Dot >= spriteFetchDot && ppu.Dot < backgroundFetchDot {
+			ppu.oamAddress = 0
+			ppu.fetchSprite()
+		}
+
+		if preLine && ppu.Dot >= 280 && ppu.Dot <= 304 {
+			ppu.copyY()
+		}
+
+		if ppu.Dot == 260 && ppu.Cartridge != nil {
+			ppu.Cartridge.Scanline()
+		}
+	}
+
+	if ppu.Scanline == VBlankScanline && ppu.Dot == 1 {
+		if !ppu.suppressVBlank {
+			ppu.status |= statusVBlank
+			ppu.nmiChange()
+		}
+		ppu.suppressVBlank = false
+	}
+
+	if preLine && ppu.Dot == 1 {
+		ppu.status &^= statusVBlank | statusSpriteZeroHit | statusSpriteOverflow
+		ppu.nmiChange()
+	}
+}
+
+// tick moves to the next dot. On odd frames the pre-render scanline is
+// one dot shorter when rendering is enabled.
+func (ppu *PPU) tick() {
+	if ppu.Scanline == PreRenderScanline && ppu.Dot == 339 && ppu.oddFrame && ppu.renderingEnabled() {
+		ppu.Dot = 340
+	}
+
+	ppu.Dot++
+	if ppu.Dot == DotsPerScanline {
+		ppu.Dot = 0
+		ppu.Scanline++
+
+		if ppu.Scanline == ScanlinesPerFrame {
+			ppu.Scanline = 0
+			ppu.Frames++
+			ppu.oddFrame = !ppu.oddFrame
+		}
+	}
+}
+
+func (ppu *PPU) renderPixel() {
+	x := ppu.Dot - 1
+	background := ppu.backgroundPixel()
+	spriteIndex, spriteColor := ppu.spritePixel()
+
+	if x < 8 && ppu.mask&maskBackgroundLeft == 0 {
+		background = 0
+	}
+	if x < 8 && ppu.mask&maskSpritesLeft == 0 {
+		spriteColor = 0
+	}
+
+	opaqueBackground := background&0x03 != 0
+	opaqueSprite := spriteColor&0x03 != 0
+
+	var color byte
+	switch {
+	case !opaqueBackground && !opaqueSprite:
+		color = 0
+	case !opaqueBackground:
+		color = 0x10 | spriteColor
+	case !opaqueSprite:
+		color = background
+	default:
+		if spriteIndex == 0 && ppu.spriteZeroOnLine && x != 255 {
+			ppu.status |= statusSpriteZeroHit
+		}
+
+		if ppu.sprites[spriteIndex].attributes&spriteBehind == 0 {
+			color = 0x10 | spriteColor
+		} else {
+			color = background
+		}
+	}
+
+	ppu.FrameBuffer[ppu.Scanline*ScreenWidth+x] = ppu.readPalette(uint16(color))
+}
+
+// renderBackdrop outputs the backdrop colour while rendering is off, or
+// the colour v points at if it has been left inside palette RAM.
+func (ppu *PPU) renderBackdrop() {
+	address := uint16(0)
+	if ppu.v&0x3F00 == 0x3F00 {
+		address = ppu.v
+	}
+
+	ppu.FrameBuffer[ppu.Scanline*ScreenWidth+ppu.Dot-1] = ppu.readPalette(address)
+}
+
+// backgroundPixel returns the attribute and pattern bits of the background
+// at the current dot, selected from the shift registers by fine X.
+func (ppu *PPU) backgroundPixel() byte {
+	if ppu.mask&maskBackground == 0 {
+		return 0
+	}
+
+	data := uint32(ppu.tileData>>32) >> ((7 - ppu.x) * 4)
+	return byte(data & 0x0F)
+}
+
+// spritePixel returns which of the scanline's sprites is in front at the
+// current dot, and its palette and pattern bits.
+func (ppu *PPU) spritePixel() (int, byte) {
+	if ppu.mask&maskSprites == 0 {
+		return 0, 0
+	}
+
+	x := ppu.Dot - 1
+	for i := 0; i < ppu.spriteCount; i++ {
+		sprite := &ppu.sprites[i]
+		offset := x - int(sprite.x)
+		if offset < 0 || offset > 7 {
+			continue
+		}
+
+		bit := 7 - offset
+		if sprite.attributes&spriteFlipHorizontal != 0 {
+			bit = offset
+		}
+
+		color := (sprite.high>>bit&1)<<1 | sprite.low>>bit&1
+		if color != 0 {
+			return i, (sprite.attributes&spritePalette)<<2 | color
+		}
+	}
+
+	return 0, 0
+}
+
+func (ppu *PPU) fetchNametableByte() {
+	ppu.nametableByte = ppu.read(0x2000 | ppu.v&0x0FFF)
+}
+
+func (ppu *PPU) fetchAttributeByte() {
+	v := ppu.v
+	address := 0x23C0 | v&0x0C00 | v>>4&0x38 | v>>2&0x07
+	shift := v>>4&0x04 | v&0x02
+	ppu.attributeByte = (ppu.read(address) >> shift & 0x03) << 2
+}
+
+func (ppu *PPU) backgroundPatternAddress() uint16 {
+	fineY := ppu.v >> 12 & 0x07
+	table := uint16(ppu.ctrl&ctrlBackgroundTable) << 8
+	return table | uint16(ppu.nametableByte)<<4 | fineY
+}
+
+// storeTileData loads the fetched tile into the low half of the shift
+// registers, to be shifted out once the current tile is done.
+func (ppu *PPU) storeTileData() {
+	var data uint32
+	for i := 0; i < 8; i++ {
+		data <<= 4
+		data |= uint32(ppu.attributeByte | ppu.highTileByte>>6&0x02 | ppu.lowTileByte>>7)
+		ppu.lowTileByte <<= 1
+		ppu.highTileByte <<= 1
+	}
+
+	ppu.tileData |= uint64(data)
+}
+
+// evaluateSprites finds the first 8 sprites on the next scanline,
+// including the hardware's buggy overflow check, which after finding 8
+// sprites goes on to look at the wrong byte of each remaining sprite.
+func (ppu *PPU) evaluateSprites() {
+	height := ppu.spriteHeight()
+	inRange := func(y byte) bool {
+		row := ppu.Scanline - int(y)
+		return row >= 0 && row < height
+	}
+
+	count := 0
+	n := 0
+	ppu.nextSpriteZero = false
+	for ; n < 64 && count < 8; n++ {
+		y := ppu.oam[n*4]
+		if !inRange(y) {
+			continue
+		}
+
+		if n == 0 {
+			ppu.nextSpriteZero = true
+		}
+		ppu.sprites[count] = sprite{
+			y:          y,
+			tile:       ppu.oam[n*4+1],
+			attributes: ppu.oam[n*4+2],
+			x:          ppu.oam[n*4+3],
+		}
+		count++
+	}
+
+	for m := 0; n < 64; n++ {
+		if inRange(ppu.oam[n*4+m]) {
+			ppu.status |= statusSpriteOverflow
+			break
+		}
+		m = (m + 1) & 0x03
+	}
+
+	ppu.spriteCount = count
+}
+
+func (ppu *PPU) spriteHeight() int {
+	if ppu.ctrl&ctrlSpriteSize16 != 0 {
+		return 16
+	}
+
+	return 8
+}
+
+// fetchSprite does the pattern fetches for the sprite slots during dots
+// 257-320, so mappers watching the PPU address bus see them at the same
+// time as on hardware. Empty slots fetch tile $FF.
+func (ppu *PPU) fetchSprite() {
+	slot := (ppu.Dot - spriteFetchDot) / 8
+
+	switch (ppu.Dot - spriteFetchDot) % 8 {
+	case 4:
+		ppu.spritePatternLow = ppu.read(ppu.spritePatternAddress(slot))
+	case 6:
+		high := ppu.read(ppu.spritePatternAddress(slot) + 8)
+
+		if slot < ppu.spriteCount {
+			ppu.sprites[slot].low = ppu.spritePatternLow
+			ppu.sprites[slot].high = high
+		}
+
+		if slot == 7 {
+			ppu.spriteZeroOnLine = ppu.nextSpriteZero
+		}
+	}
+}
+
+func (ppu *PPU) spritePatternAddress(slot int) uint16 {
+	tile, attributes, row := byte(0xFF), byte(0), 0
+	if slot < ppu.spriteCount {
+		sprite := ppu.sprites[slot]
+		tile, attributes = sprite.tile, sprite.attributes
+		row = ppu.Scanline - int(sprite.y)
+	}
+
+	height := ppu.spriteHeight()
+	if attributes&spriteFlipVertical != 0 {
+		row = height - 1 - row
+	}
+
+	if height == 8 {
+		table := uint16(ppu.ctrl&ctrlSpriteTable) << 9
+		return table | uint16(tile)<<4 | uint16(row)
+	}
+
+	table := uint16(tile&0x01) << 12
+	tile &= 0xFE
+	if row > 7 {
+		tile++
+		row -= 8
+	}
+
+	return table | uint16(tile)<<4 | uint16(row)
+}
+
+// incrementX moves v to the next tile, wrapping into the horizontally
+// adjacent nametable.
+func (ppu *PPU) incrementX() {
+	if ppu.v&0x001F == 31 {
+		ppu.v &^= 0x001F
+		ppu.v ^= 0x0400
+	} else {
+		ppu.v++
+	}
+}
+
+// incrementY moves v to the next pixel row, wrapping into the vertically
+// adjacent nametable after row 29. Rows 30 and 31 hold attribute data and
+// wrap without switching nametables.
+func (ppu *PPU) incrementY() {
+	if ppu.v&0x7000 != 0x7000 {
+		ppu.v += 0x1000
+		return
+	}
+
+	ppu.v &^= 0x7000
+	y := ppu.v & 0x03E0 >> 5
+	switch y {
+	case 29:
+		y = 0
+		ppu.v ^= 0x0800
+	case 31:
+		y = 0
+	default:
+		y++
+	}
+	ppu.v = ppu.v&^0x03E0 | y<<5
+}
+
+func (ppu *PPU) copyX() {
+	ppu.v = ppu.v&0xFBE0 | ppu.t&0x041F
+}
+
+func (ppu *PPU) copyY() {
+	ppu.v = ppu.v&0x841F | ppu.t&0x7BE0
+}
@@ -0,0 +1,547 @@
+package ppu
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/DevinRiley/nes/cartridge"
+)
+
+func newTestPPU() *PPU {
+	return NewPPU(newTestBoard(cartridge.Vertical))
+}
+
+// testBoard is a board with 8KB of CHR RAM and fixed mirroring, so tests
+// can draw tiles
+type testBoard struct {
+	chr       [0x2000]byte
+	mirroring cartridge.Mirroring
+}
+
+func newTestBoard(mirroring cartridge.Mirroring) *testBoard {
+	return &testBoard{mirroring: mirroring}
+}
+
+func (board *testBoard) Read(address uint16) byte            { return 0 }
+func (board *testBoard) Write(address uint16, value byte)    {}
+func (board *testBoard) ReadPPU(address uint16) byte         { return board.chr[address&0x1FFF] }
+func (board *testBoard) WritePPU(address uint16, value byte) { board.chr[address&0x1FFF] = value }
+func (board *testBoard) Mirroring() cartridge.Mirroring      { return board.mirroring }
+func (board *testBoard) IRQ() bool                           { return false }
+func (board *testBoard) Step()                               {}
+func (board *testBoard) Scanline()                           {}
+func (board *testBoard) SaveState(w io.Writer) error         { return nil }
+func (board *testBoard) SaveRegisters(w io.Writer) error     { return nil }
+func (board *testBoard) LoadState(r io.Reader) error         { return nil }
+func (board *testBoard) Memory() [][]byte                    { return [][]byte{board.chr[:]} }
+
+func stepPPUTo(ppu *PPU, scanline int, dot int) {
+	for ppu.Scanline != scanline || ppu.Dot != dot {
+		ppu.Step()
+	}
+}
+
+func stepPPUFrame(ppu *PPU) {
+	frames := ppu.Frames
+	for ppu.Frames == frames {
+		ppu.Step()
+	}
+}
+
+func setPPUAddress(ppu *PPU, address uint16) {
+	ppu.Write(0x2006, byte(address>>8))
+	ppu.Write(0x2006, byte(address))
+}
+
+func TestPPUScrollRegisters(t *testing.T) {
+	ppu := newTestPPU()
+
+	ppu.Write(0x2000, 0x00)
+	ppu.Write(0x2005, 0x7D)
+	ppu.Write(0x2005, 0x5E)
+
+	if ppu.t != 0x616F {
+		t.Errorf("did not set t from $2005, got %04X", ppu.t)
+	}
+
+	if ppu.x != 0x05 {
+		t.Error("did not set fine X from $2005")
+	}
+
+	if ppu.v != 0x0000 {
+		t.Error("$2005 should not change v")
+	}
+
+	ppu.Write(0x2006, 0x3D)
+	ppu.Write(0x2006, 0xF0)
+
+	if ppu.t != 0x3DF0 || ppu.v != 0x3DF0 {
+		t.Errorf("did not set t and v from $2006, got %04X %04X", ppu.t, ppu.v)
+	}
+
+	ppu.Write(0x2000, 0x00)
+
+	if ppu.t != 0x31F0 {
+		t.Error("did not set the nametable bits of t from $2000")
+	}
+}
+
+func TestPPUStatusReadResetsWriteToggle(t *testing.T) {
+	ppu := newTestPPU()
+
+	ppu.Write(0x2006, 0x21)
+	ppu.Read(0x2002)
+	ppu.Write(0x2006, 0x23)
+	ppu.Write(0x2006, 0x45)
+
+	if ppu.v != 0x2345 {
+		t.Errorf("did not reset the write toggle, v is %04X", ppu.v)
+	}
+}
+
+func TestPPUNametableMirroringVertical(t *testing.T) {
+	ppu := NewPPU(newTestBoard(cartridge.Vertical))
+
+	ppu.write(0x2005, 0x42)
+
+	if ppu.read(0x2405) != 0x42 || ppu.read(0x2805) == 0x42 {
+		t.Error("vertical arrangement should mirror $2400 onto $2000")
+	}
+
+	if ppu.read(0x3405) != 0x42 {
+		t.Error("did not mirror $3000-$3EFF onto $2000-$2EFF")
+	}
+}
+
+func TestPPUNametableMirroringHorizontal(t *testing.T) {
+	ppu := NewPPU(newTestBoard(cartridge.Horizontal))
+
+	ppu.write(0x2005, 0x42)
+
+	if ppu.read(0x2805) != 0x42 || ppu.read(0x2405) == 0x42 {
+		t.Error("horizontal arrangement should mirror $2800 onto $2000")
+	}
+}
+
+func TestPPUNametableFourScreen(t *testing.T) {
+	ppu := NewPPU(newTestBoard(cartridge.FourScreen))
+
+	ppu.write(0x2005, 0x42)
+
+	for _, address := range []uint16{0x2405, 0x2805, 0x2C05} {
+		if ppu.read(address) == 0x42 {
+			t.Errorf("four screen VRAM should not mirror $%04X", address)
+		}
+	}
+}
+
+func TestPPUNametableSingleScreen(t *testing.T) {
+	ppu := NewPPU(newTestBoard(cartridge.SingleScreenUpper))
+
+	ppu.write(0x2005, 0x42)
+
+	for _, address := range []uint16{0x2405, 0x2805, 0x2C05} {
+		if ppu.read(address) != 0x42 {
+			t.Errorf("single screen should mirror $%04X", address)
+		}
+	}
+
+	if ppu.nametables[0x0405] != 0x42 {
+		t.Error("did not use the upper 1KB of VRAM")
+	}
+}
+
+func TestPPUPaletteMirroring(t *testing.T) {
+	ppu := newTestPPU()
+
+	ppu.write(0x3F10, 0x0F)
+	ppu.write(0x3F25, 0x16)
+
+	if ppu.read(0x3F00) != 0x0F {
+		t.Error("did not mirror $3F10 onto $3F00")
+	}
+
+	if ppu.read(0x3F05) != 0x16 {
+		t.Error("did not mirror $3F20-$3FFF onto $3F00-$3F1F")
+	}
+}
+
+func TestPPUDataReadIsBuffered(t *testing.T) {
+	ppu := newTestPPU()
+	ppu.write(0x2100, 0x11)
+	ppu.write(0x2120, 0x22)
+	ppu.Write(0x2000, ctrlIncrement32)
+	setPPUAddress(ppu, 0x2100)
+
+	ppu.Read(0x2007)
+
+	if ppu.Read(0x2007) != 0x11 {
+		t.Error("did not return the buffered value")
+	}
+
+	if ppu.Read(0x2007) != 0x22 {
+		t.Error("did not increment the address by 32")
+	}
+}
+
+func TestPPUDataReadPaletteIsImmediate(t *testing.T) {
+	ppu := newTestPPU()
+	ppu.write(0x2F00, 0x33)
+	ppu.write(0x3F00, 0x21)
+	setPPUAddress(ppu, 0x3F00)
+
+	if ppu.Read(0x2007) != 0x21 {
+		t.Error("palette reads should not be buffered")
+	}
+
+	if ppu.readBuffer != 0x33 {
+		t.Error("did not buffer the nametable byte underneath the palette")
+	}
+}
+
+func TestPPUWriteOnlyRegistersReadOpenBus(t *testing.T) {
+	ppu := newTestPPU()
+
+	ppu.Write(0x2001, 0x5A)
+
+	if ppu.Read(0x2000) != 0x5A {
+		t.Error("did not return open bus for a write only register")
+	}
+
+	if ppu.Read(0x2002)&0x1F != 0x1A {
+		t.Error("did not fill the low bits of PPUSTATUS from open bus")
+	}
+}
+
+func TestPPUOAMData(t *testing.T) {
+	ppu := newTestPPU()
+
+	ppu.Write(0x2003, 0x10)
+	ppu.Write(0x2004, 0x20)
+	ppu.Write(0x2004, 0xFF)
+
+	if ppu.oam[0x10] != 0x20 || ppu.oamAddress != 0x12 {
+		t.Error("did not write OAM and increment OAMADDR")
+	}
+
+	ppu.Write(0x2003, 0x11)
+	if ppu.Read(0x2004) != 0xFF {
+		t.Error("did not read OAM")
+	}
+
+	ppu.Write(0x2003, 0x12)
+	ppu.Write(0x2004, 0xFF)
+	ppu.Write(0x2003, 0x12)
+	if ppu.Read(0x2004) != 0xE3 {
+		t.Error("did not clear the unimplemented attribute bits")
+	}
+}
+
+func TestPPUVBlankNMI(t *testing.T) {
+	ppu := newTestPPU()
+	nmis := 0
+	ppu.NMI = func() { nmis++ }
+	ppu.Write(0x2000, ctrlNMI)
+
+	stepPPUTo(ppu, VBlankScanline, 1)
+
+	if nmis != 1 {
+		t.Error("did not signal NMI at the start of vblank")
+	}
+
+	if ppu.Read(0x2002)&statusVBlank == 0 {
+		t.Error("did not set the vblank flag")
+	}
+
+	if ppu.Read(0x2002)&statusVBlank != 0 {
+		t.Error("reading PPUSTATUS did not clear the vblank flag")
+	}
+
+	stepPPUFrame(ppu)
+
+	if nmis != 1 {
+		t.Error("signalled NMI again without a new vblank")
+	}
+}
+
+func TestPPUEnablingNMIDuringVBlank(t *testing.T) {
+	ppu := newTestPPU()
+	nmis := 0
+	ppu.NMI = func() { nmis++ }
+
+	stepPPUTo(ppu, VBlankScanline+1, 0)
+	ppu.Write(0x2000, ctrlNMI)
+
+	if nmis != 1 {
+		t.Error("did not signal NMI when enabled during vblank")
+	}
+
+	ppu.Write(0x2000, 0x00)
+	ppu.Write(0x2000, ctrlNMI)
+
+	if nmis != 2 {
+		t.Error("did not signal NMI when toggled during vblank")
+	}
+}
+
+func TestPPUVBlankClearedOnPreRenderLine(t *testing.T) {
+	ppu := newTestPPU()
+	ppu.status = statusVBlank | statusSpriteZeroHit | statusSpriteOverflow
+
+	stepPPUTo(ppu, PreRenderScanline, 1)
+
+	if ppu.status != 0 {
+		t.Error("did not clear PPUSTATUS on the pre-render scanline")
+	}
+}
+
+func TestPPUStatusReadSuppressesVBlank(t *testing.T) {
+	ppu := newTestPPU()
+	nmis := 0
+	ppu.NMI = func() { nmis++ }
+	ppu.Write(0x2000, ctrlNMI)
+
+	stepPPUTo(ppu, VBlankScanline, 0)
+	ppu.Read(0x2002)
+	ppu.Step()
+
+	if ppu.status&statusVBlank != 0 || nmis != 0 {
+		t.Error("reading PPUSTATUS just before vblank did not suppress it")
+	}
+}
+
+func TestPPUOddFramesSkipADot(t *testing.T) {
+	ppu := newTestPPU()
+	ppu.Write(0x2001, maskBackground)
+
+	length := func() int {
+		dots := 0
+		frames := ppu.Frames
+		for ppu.Frames == frames {
+			ppu.Step()
+			dots++
+		}
+		return dots
+	}
+
+	first, second := length(), length()
+
+	if first != 89342 || second != 89341 {
+		t.Errorf("frames were %d and %d dots long", first, second)
+	}
+
+	ppu.Write(0x2001, 0x00)
+	if length() != 89342 || length() != 89342 {
+		t.Error("skipped a dot with rendering disabled")
+	}
+}
+
+// setupTestScene puts an opaque tile 1 in the pattern table and palette
+// colours for background palette 0 and sprite palette 0.
+func setupTestScene(ppu *PPU) {
+	for row := uint16(0); row < 8; row++ {
+		ppu.Cartridge.WritePPU(0x0010+row, 0xFF)
+	}
+
+	ppu.write(0x3F00, 0x0F)
+	ppu.write(0x3F01, 0x16)
+	ppu.write(0x3F11, 0x2A)
+}
+
+func TestPPURendersBackground(t *testing.T) {
+	ppu := newTestPPU()
+	setupTestScene(ppu)
+	ppu.write(0x2000, 0x01)
+	ppu.Write(0x2001, maskBackground|maskBackgroundLeft)
+
+	stepPPUFrame(ppu)
+	stepPPUFrame(ppu)
+
+	for x := 0; x < 8; x++ {
+		if ppu.FrameBuffer[x] != 0x16 {
+			t.Errorf("did not render tile 1 at pixel %d, got %02X", x, ppu.FrameBuffer[x])
+		}
+	}
+
+	if ppu.FrameBuffer[8] != 0x0F || ppu.FrameBuffer[8*ScreenWidth] != 0x0F {
+		t.Error("did not render the backdrop around the tile")
+	}
+}
+
+func TestPPURendersFineXScroll(t *testing.T) {
+	ppu := newTestPPU()
+	setupTestScene(ppu)
+	ppu.write(0x2001, 0x01)
+	ppu.Write(0x2001, maskBackground|maskBackgroundLeft)
+	ppu.Write(0x2005, 0x03)
+	ppu.Write(0x2005, 0x00)
+
+	stepPPUFrame(ppu)
+	stepPPUFrame(ppu)
+
+	if ppu.FrameBuffer[4] != 0x0F || ppu.FrameBuffer[5] != 0x16 || ppu.FrameBuffer[12] != 0x16 || ppu.FrameBuffer[13] != 0x0F {
+		t.Error("did not scroll tile 1 to pixels 5-12")
+	}
+}
+
+func TestPPUHidesLeftColumn(t *testing.T) {
+	ppu := newTestPPU()
+	setupTestScene(ppu)
+	ppu.write(0x2000, 0x01)
+	ppu.Write(0x2001, maskBackground)
+
+	stepPPUFrame(ppu)
+	stepPPUFrame(ppu)
+
+	if ppu.FrameBuffer[0] != 0x0F {
+		t.Error("did not hide the background in the leftmost 8 pixels")
+	}
+}
+
+func TestPPURendersSprites(t *testing.T) {
+	ppu := newTestPPU()
+	setupTestScene(ppu)
+	ppu.oam = [256]byte{}
+	for i := 0; i < 64; i++ {
+		ppu.oam[i*4] = 0xFF
+	}
+	// sprites are drawn one line below their Y coordinate
+	ppu.oam[0], ppu.oam[1], ppu.oam[2], ppu.oam[3] = 9, 0x01, spriteFlipHorizontal, 20
+	ppu.Write(0x2001, maskSprites|maskSpritesLeft)
+
+	stepPPUFrame(ppu)
+	stepPPUFrame(ppu)
+
+	if ppu.FrameBuffer[10*ScreenWidth+20] != 0x2A || ppu.FrameBuffer[17*ScreenWidth+27] != 0x2A {
+		t.Error("did not render the sprite")
+	}
+
+	if ppu.FrameBuffer[9*ScreenWidth+20] != 0x0F || ppu.FrameBuffer[18*ScreenWidth+20] != 0x0F || ppu.FrameBuffer[10*ScreenWidth+28] != 0x0F {
+		t.Error("rendered the sprite outside of its 8x8 box")
+	}
+
+	if ppu.status&statusSpriteZeroHit != 0 {
+		t.Error("set sprite 0 hit without any background")
+	}
+}
+
+func TestPPUSpriteZeroHit(t *testing.T) {
+	ppu := newTestPPU()
+	setupTestScene(ppu)
+	for i := 0; i < 64; i++ {
+		ppu.oam[i*4] = 0xFF
+	}
+	ppu.oam[0], ppu.oam[1], ppu.oam[2], ppu.oam[3] = 30, 0x01, spriteBehind, 40
+	ppu.write(0x2000+4*32+5, 0x01)
+	ppu.Write(0x2001, maskBackground|maskSprites)
+
+	stepPPUFrame(ppu)
+	stepPPUTo(ppu, 32, 0)
+
+	if ppu.status&statusSpriteZeroHit != 0 {
+		t.Error("set sprite 0 hit before the sprite overlapped the background")
+	}
+
+	stepPPUTo(ppu, 33, 0)
+
+	if ppu.status&statusSpriteZeroHit == 0 {
+		t.Error("did not set sprite 0 hit")
+	}
+
+	if ppu.FrameBuffer[31*ScreenWidth+40] != 0x2A || ppu.FrameBuffer[32*ScreenWidth+40] != 0x16 {
+		t.Error("did not draw the background over a sprite with priority")
+	}
+}
+
+func TestPPUSpriteOverflow(t *testing.T) {
+	ppu := newTestPPU()
+	for i := 0; i < 64; i++ {
+		ppu.oam[i*4] = 0xFF
+	}
+	for i := 0; i < 9; i++ {
+		ppu.oam[i*4] = 50
+	}
+	ppu.Write(0x2001, maskSprites)
+
+	stepPPUFrame(ppu)
+	stepPPUTo(ppu, 49, 300)
+
+	if ppu.status&statusSpriteOverflow != 0 {
+		t.Error("set sprite overflow too early")
+	}
+
+	stepPPUTo(ppu, 50, 300)
+
+	if ppu.status&statusSpriteOverflow == 0 {
+		t.Error("did not set sprite overflow with 9 sprites on a line")
+	}
+
+	if ppu.spriteCount != 8 {
+		t.Error("did not limit the scanline to 8 sprites")
+	}
+}
+
+func TestPPURenderingDisabledShowsBackdrop(t *testing.T) {
+	ppu := newTestPPU()
+	ppu.write(0x3F00, 0x21)
+
+	stepPPUFrame(ppu)
+
+	if ppu.FrameBuffer[100*ScreenWidth+100] != 0x21 {
+		t.Error("did not output the backdrop colour with rendering disabled")
+	}
+}
+
+func TestPPUClocksMMC3Scanlines(t *testing.T) {
+	rom := &cartridge.ROM{Mapper: 4, PRGSize: 0x8000, PRGData: make([]byte, 0x8000)}
+	mmc3, err := cartridge.NewMapper(rom)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mmc3.Write(0xC000, 9)
+	mmc3.Write(0xC001, 0)
+	mmc3.Write(0xE001, 0)
+
+	ppu := NewPPU(mmc3)
+	ppu.Write(0x2000, ctrlSpriteTable)
+	ppu.Write(0x2001, maskBackground|maskSprites)
+
+	stepPPUFrame(ppu)
+	for !mmc3.IRQ() {
+		mmc3.Step()
+		for i := 0; i < 3; i++ {
+			ppu.Step()
+		}
+	}
+
+	// the counter is clocked at the sprite fetches of every scanline, the
+	// first clock on scanline 0 loads it and then it counts down to 0
+	if ppu.Scanline != 9 {
+		t.Error("asserted IRQ on scanline", ppu.Scanline)
+	}
+}
+
+func TestPPUSaveState(t *testing.T) {
+	ppu := newTestPPU()
+	setupTestScene(ppu)
+	stepPPUFrame(ppu)
+	stepPPUTo(ppu, 100, 200)
+
+	var state bytes.Buffer
+	if err := ppu.SaveState(&state); err != nil {
+		t.Fatal(err)
+	}
+
+	restored := NewPPU(ppu.Cartridge)
+	if err := restored.LoadState(&state); err != nil {
+		t.Fatal(err)
+	}
+
+	stepPPUFrame(ppu)
+	stepPPUFrame(restored)
+
+	if restored.FrameBuffer != ppu.FrameBuffer || restored.Scanline != ppu.Scanline || restored.Dot != ppu.Dot {
+		t.Error("did not render the same frame after restoring the state")
+	}
+}
@@ -0,0 +1,175 @@
+package ppu
+
+import (
+	"encoding/gob"
+	"io"
+)
+
+type ppuState struct {
+	FrameBuffer []byte
+	Frames      uint64
+	Scanline    int
+	Dot         int
+
+	Ctrl       byte
+	Mask       byte
+	Status     byte
+	OAMAddress byte
+	OAM        []byte
+	Nametables []byte
+	Palette    []byte
+
+	V uint16
+	T uint16
+	X byte
+	W bool
+
+	ReadBuffer     byte
+	OpenBus        byte
+	NMIOutput      bool
+	SuppressVBlank bool
+	OddFrame       bool
+
+	NametableByte byte
+	AttributeByte byte
+	LowTileByte   byte
+	HighTileByte  byte
+	TileData      uint64
+
+	Sprites          []spriteState
+	SpriteCount      int
+	NextSpriteZero   bool
+	SpriteZeroOnLine bool
+	SpritePatternLow byte
+}
+
+type spriteState struct {
+	Y          byte
+	Tile       byte
+	Attributes byte
+	X          byte
+	Low        byte
+	High       byte
+}
+
+// SaveState writes everything inside the PPU, including the picture in
+// FrameBuffer. The cartridge saves its own state.
+func (ppu *PPU) SaveState(w io.Writer) error {
+	state := ppu.registerState()
+	state.FrameBuffer = ppu.FrameBuffer[:]
+	state.OAM = ppu.oam[:]
+	state.Nametables = ppu.nametables[:]
+	state.Palette = ppu.palette[:]
+
+	return gob.NewEncoder(w).Encode(&state)
+}
+
+// SaveRegisters writes what SaveState does except for FrameBuffer and the
+// memory that Memory returns. LoadState leaves those alone when it reads
+// the result.
+func (ppu *PPU) SaveRegisters(w io.Writer) error {
+	state := ppu.registerState()
+	return gob.NewEncoder(w).Encode(&state)
+}
+
+// Memory returns the PPU's own RAM: OAM, the nametables and the palette.
+// The slices are the PPU's memory, not copies.
+func (ppu *PPU) Memory() [][]byte {
+	return [][]byte{ppu.oam[:], ppu.nametables[:], ppu.palette[:]}
+}
+
+func (ppu *PPU) registerState() ppuState {
+	state := ppuState{
+		Frames:           ppu.Frames,
+		Scanline:         ppu.Scanline,
+		Dot:              ppu.Dot,
+		Ctrl:             ppu.ctrl,
+		Mask:             ppu.mask,
+		Status:           ppu.status,
+		OAMAddress:       ppu.oamAddress,
+		V:                ppu.v,
+		T:                ppu.t,
+		X:                ppu.x,
+		W:                ppu.w,
+		ReadBuffer:       ppu.readBuffer,
+		OpenBus:          ppu.openBus,
+		NMIOutput:        ppu.nmiOutput,
+		SuppressVBlank:   ppu.suppressVBlank,
+		OddFrame:         ppu.oddFrame,
+		NametableByte:    ppu.nametableByte,
+		AttributeByte:    ppu.attributeByte,
+		LowTileByte:      ppu.lowTileByte,
+		HighTileByte:     ppu.highTileByte,
+		TileData:         ppu.tileData,
+		SpriteCount:      ppu.spriteCount,
+		NextSpriteZero:   ppu.nextSpriteZero,
+		SpriteZeroOnLine: ppu.spriteZeroOnLine,
+		SpritePatternLow: ppu.spritePatternLow,
+	}
+
+	for _, sprite := range ppu.sprites {
+		state.Sprites = append(state.Sprites, spriteState{
+			Y:          sprite.y,
+			Tile:       sprite.tile,
+			Attributes: sprite.attributes,
+			X:          sprite.x,
+			Low:        sprite.low,
+			High:       sprite.high,
+		})
+	}
+
+	return state
+}
+
+// LoadState restores the state written by SaveState.
+func (ppu *PPU) LoadState(r io.Reader) error {
+	var state ppuState
+	if err := gob.NewDecoder(r).Decode(&state); err != nil {
+		return err
+	}
+
+	copy(ppu.FrameBuffer[:], state.FrameBuffer)
+	ppu.Frames = state.Frames
+	ppu.Scanline = state.Scanline
+	ppu.Dot = state.Dot
+	ppu.ctrl = state.Ctrl
+	ppu.mask = state.Mask
+	ppu.status = state.Status
+	ppu.oamAddress = state.OAMAddress
+	copy(ppu.oam[:], state.OAM)
+	copy(ppu.nametables[:], state.Nametables)
+	copy(ppu.palette[:], state.Palette)
+	ppu.v = state.V
+	ppu.t = state.T
+	ppu.x = state.X
+	ppu.w = state.W
+	ppu.readBuffer = state.ReadBuffer
+	ppu.openBus = state.OpenBus
+	ppu.nmiOutput = state.NMIOutput
+	ppu.suppressVBlank = state.SuppressVBlank
+	ppu.oddFrame = state.OddFrame
+	ppu.nametableByte = state.NametableByte
+	ppu.attributeByte = state.AttributeByte
+	ppu.lowTileByte = state.LowTileByte
+	ppu.highTileByte = state.HighTileByte
+	ppu.tileData = state.TileData
+	ppu.spriteCount = state.SpriteCount
+	ppu.nextSpriteZero = state.NextSpriteZero
+	ppu.spriteZeroOnLine = state.SpriteZeroOnLine
+	ppu.spritePatternLow = state.SpritePatternLow
+
+	ppu.sprites = [8]sprite{}
+	for i, sprite := range state.Sprites {
+		if i == len(ppu.sprites) {
+			break
+		}
+		ppu.sprites[i].y = sprite.Y
+		ppu.sprites[i].tile = sprite.Tile
+		ppu.sprites[i].attributes = sprite.Attributes
+		ppu.sprites[i].x = sprite.X
+		ppu.sprites[i].low = sprite.Low
+		ppu.sprites[i].high = sprite.High
+	}
+
+	return nil
+}
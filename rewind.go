@@ -0,0 +1,294 @@
+package nes
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+// ErrRewindEmpty is returned by Rewind.Back when there is no recorded
+// history left to go back to.
+var ErrRewindEmpty = errors.New("no more frames to rewind")
+
+var errCorruptSnapshot = errors.New("corrupt rewind snapshot")
+
+// Rewind runs a console a frame at a time and records where it has been,
+// so that it can be stepped backwards a frame at a time.
+//
+// A snapshot is taken every Interval frames, along with the buttons held
+// during every frame. Going back a frame loads the newest snapshot before
+// it and replays the recorded input up to the frame. Only the newest
+// snapshot is kept in full, each older one is stored as the difference
+// from the snapshot after it, which is small because most of the machine
+// doesn't change from one frame to the next. The oldest snapshots are
+// dropped to keep the history within Budget bytes.
+//
+// The picture in the PPU's FrameBuffer is redrawn every frame, so it
+// isn't recorded. After Back it holds the newer frame until the next
+// StepFrame draws over it.
+type Rewind struct {
+	Console  *Console
+	Interval int // frames between snapshots
+	Budget   int // bytes of history to keep
+
+	frame     int         // frames run through StepFrame
+	snapshots []snapshot  // oldest first
+	latest    []byte      // the newest snapshot in full
+	inputs    [][2]Button // buttons for every frame since the oldest snapshot
+	size      int         // bytes used by snapshots and inputs
+}
+
+type snapshot struct {
+	frame int
+	delta []byte // from the next newer snapshot, nil for the newest
+}
+
+// NewRewind records the console's history, taking a snapshot every
+// interval frames and keeping at most budget bytes of it.
+func NewRewind(console *Console, interval int, budget int) *Rewind {
+	if interval < 1 {
+		interval = 1
+	}
+
+	return &Rewind{Console: console, Interval: interval, Budget: budget}
+}
+
+// Frames is how many frames Back can go back.
+func (rewind *Rewind) Frames() int {
+	if len(rewind.snapshots) == 0 {
+		return 0
+	}
+
+	return rewind.frame - rewind.snapshots[0].frame
+}
+
+// StepFrame runs the console for a frame and records it.
+func (rewind *Rewind) StepFrame() error {
+	if rewind.frame%rewind.Interval == 0 && rewind.newestFrame() != rewind.frame {
+		if err := rewind.snapshot(); err != nil {
+			return err
+		}
+	}
+
+	controllers := &rewind.Console.Controllers
+	rewind.inputs = append(rewind.inputs, [2]Button{controllers[0].Buttons, controllers[1].Buttons})
+	rewind.size += 2
+	rewind.trim()
+
+	if err := rewind.Console.StepFrame(); err != nil {
+		return err
+	}
+	rewind.frame++
+
+	return nil
+}
+
+// Back puts the console back to where it was one frame ago. History
+// after that frame is forgotten, the next StepFrame records over it.
+func (rewind *Rewind) Back() error {
+	if rewind.Frames() == 0 {
+		return ErrRewindEmpty
+	}
+	target := rewind.frame - 1
+
+	for rewind.newestFrame() > target {
+		if err := rewind.dropNewest(); err != nil {
+			return err
+		}
+	}
+
+	if err := rewind.restore(rewind.latest); err != nil {
+		return err
+	}
+
+	controllers := &rewind.Console.Controllers
+	held := [2]Button{controllers[0].Buttons, controllers[1].Buttons}
+	defer func() {
+		controllers[0].Buttons, controllers[1].Buttons = held[0], held[1]
+	}()
+
+	first := rewind.snapshots[0].frame
+	for rewind.frame = rewind.newestFrame(); rewind.frame < target; rewind.frame++ {
+		input := rewind.inputs[rewind.frame-first]
+		controllers[0].Buttons, controllers[1].Buttons = input[0], input[1]
+		if err := rewind.Console.StepFrame(); err != nil {
+			return err
+		}
+	}
+
+	rewind.size -= 2 * (len(rewind.inputs) - (target - first))
+	rewind.inputs = rewind.inputs[:target-first]
+
+	return nil
+}
+
+func (rewind *Rewind) newestFrame() int {
+	if len(rewind.snapshots) == 0 {
+		return -1
+	}
+
+	return rewind.snapshots[len(rewind.snapshots)-1].frame
+}
+
+func (rewind *Rewind) snapshot() error {
+	state, err := rewind.capture()
+	if err != nil {
+		return err
+	}
+
+	if n := len(rewind.snapshots); n > 0 {
+		delta := diffState(state, rewind.latest)
+		rewind.snapshots[n-1].delta = delta
+		rewind.size += len(delta) - len(rewind.latest)
+	}
+
+	rewind.snapshots = append(rewind.snapshots, snapshot{frame: rewind.frame})
+	rewind.latest = state
+	rewind.size += len(rewind.latest)
+
+	return nil
+}
+
+// capture takes a snapshot: all of the machine's RAM, which is always
+// laid out the same way, followed by a save state of the rest. Memory
+// that hasn't changed lines up byte for byte with the last snapshot
+// however the registers' encoding changes size.
+func (rewind *Rewind) capture() ([]byte, error) {
+	console := rewind.Console
+	if console.Cartridge == nil {
+		return nil, ErrNoCartridge
+	}
+
+	var state bytes.Buffer
+	for _, memory := range console.memory() {
+		state.Write(memory)
+	}
+	if err := console.saveRegisters(&state); err != nil {
+		return nil, err
+	}
+
+	return state.Bytes(), nil
+}
+
+// restore loads a snapshot taken by capture
+func (rewind *Rewind) restore(state []byte) error {
+	console := rewind.Console
+	memory := console.memory()
+
+	size := 0
+	for _, region := range memory {
+		size += len(region)
+	}
+	if len(state) < size {
+		return errCorruptSnapshot
+	}
+
+	if err := console.LoadState(bytes.NewReader(state[size:])); err != nil {
+		return err
+	}
+	for _, region := range memory {
+		state = state[copy(region, state):]
+	}
+
+	return nil
+}
+
+// dropNewest forgets the newest snapshot, rebuilding the one before it
+func (rewind *Rewind) dropNewest() error {
+	n := len(rewind.snapshots)
+	previous, err := patchState(rewind.latest, rewind.snapshots[n-2].delta)
+	if err != nil {
+		return err
+	}
+
+	rewind.size += len(previous) - len(rewind.latest) - len(rewind.snapshots[n-2].delta)
+	rewind.latest = previous
+	rewind.snapshots[n-2].delta = nil
+	rewind.snapshots = rewind.snapshots[:n-1]
+
+	return nil
+}
+
+// trim drops the oldest snapshots and their input until the history fits
+// in the budget. The newest snapshot is always kept.
+func (rewind *Rewind) trim() {
+	for rewind.size > rewind.Budget && len(rewind.snapshots) > 1 {
+		oldest := rewind.snapshots[0]
+		frames := rewind.snapshots[1].frame - oldest.frame
+
+		rewind.size -= len(oldest.delta) + 2*frames
+		rewind.inputs = rewind.inputs[frames:]
+		rewind.snapshots = rewind.snapshots[1:]
+	}
+}
+
+// diffState encodes target as the difference from base: its length, then
+// runs of unchanged bytes each followed by the changed bytes XORed with
+// base, all lengths as uvarints.
+func diffState(base []byte, target []byte) []byte {
+	delta := binary.AppendUvarint(nil, uint64(len(target)))
+
+	for i := 0; i < len(target); {
+		start := i
+		for i < len(target) && target[i] == byteAt(base, i) {
+			i++
+		}
+		unchanged := i - start
+
+		start = i
+		for i < len(target) && target[i] != byteAt(base, i) {
+			i++
+		}
+
+		delta = binary.AppendUvarint(delta, uint64(unchanged))
+		delta = binary.AppendUvarint(delta, uint64(i-start))
+		for j := start; j < i; j++ {
+			delta = append(delta, target[j]^byteAt(base, j))
+		}
+	}
+
+	return delta
+}
+
+// patchState rebuilds the target that diffState encoded against base.
+func patchState(base []byte, delta []byte) ([]byte, error) {
+	size, n := binary.Uvarint(delta)
+	if n <= 0 {
+		return nil, errCorruptSnapshot
+	}
+	delta = delta[n:]
+
+	target := make([]byte, size)
+	copy(target, base)
+
+	for i := 0; len(delta) > 0; {
+		unchanged, n := binary.Uvarint(delta)
+		if n <= 0 {
+			return nil, errCorruptSnapshot
+		}
+		delta = delta[n:]
+
+		changed, n := binary.Uvarint(delta)
+		if n <= 0 || uint64(len(delta)-n) < changed || uint64(i)+unchanged+changed > size {
+			return nil, errCorruptSnapshot
+		}
+		delta = delta[n:]
+
+		i += int(unchanged)
+		for j := 0; j < int(changed); j++ {
+			target[i] ^= delta[j]
+			i++
+		}
+		delta = delta[changed:]
+	}
+
+	return target, nil
+}
+
+func byteAt(data []byte, i int) byte {
+	if i < len(data) {
+		return data[i]
+	}
+
+	return 0
+}
@@ -0,0 +1,136 @@
+package nes
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+func TestRewindBack(t *testing.T) {
+	console := newTestConsole(t, testROM(countingROM()...))
+	rewind := NewRewind(console, 4, 1<<20)
+
+	var states [][]byte
+	for i := 0; i < 10; i++ {
+		states = append(states, saveTestState(t, console))
+		console.Controllers[0].Buttons = Button(i)
+		if err := rewind.StepFrame(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for i := 9; i >= 0; i-- {
+		if err := rewind.Back(); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(saveTestState(t, console), states[i]) {
+			t.Error("did not rewind to frame", i)
+		}
+	}
+
+	if err := rewind.Back(); err != ErrRewindEmpty {
+		t.Error("did not return ErrRewindEmpty, got", err)
+	}
+}
+
+func TestRewindRecordsOverForgottenFrames(t *testing.T) {
+	console := newTestConsole(t, testROM(countingROM()...))
+	rewind := NewRewind(console, 3, 1<<20)
+
+	for i := 0; i < 5; i++ {
+		rewind.StepFrame()
+	}
+	rewind.Back()
+	rewind.Back()
+	state := saveTestState(t, console)
+
+	rewind.StepFrame()
+	rewind.StepFrame()
+	rewind.Back()
+	rewind.Back()
+
+	if rewind.Frames() != 3 {
+		t.Error("did not forget the rewound frames, can go back", rewind.Frames())
+	}
+	if !bytes.Equal(saveTestState(t, console), state) {
+		t.Error("did not rewind the new frames")
+	}
+}
+
+func TestRewindBudget(t *testing.T) {
+	console := newTestConsole(t, testROM(countingROM()...))
+	rewind := NewRewind(console, 2, 0)
+	snapshot, err := rewind.capture()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rewind.Budget = len(snapshot) + 64
+
+	for i := 0; i < 20; i++ {
+		rewind.StepFrame()
+	}
+
+	if rewind.size > rewind.Budget && len(rewind.snapshots) > 1 {
+		t.Error("did not keep the history within the budget")
+	}
+	if rewind.Frames() == 0 || rewind.Frames() >= 20 {
+		t.Error("did not drop the oldest frames, can go back", rewind.Frames())
+	}
+}
+
+func TestRewindDeltaSize(t *testing.T) {
+	console := newTestConsole(t, testROM(countingROM()...))
+	rewind := NewRewind(console, 1, 1<<20)
+
+	// memory that looks the same shifted wouldn't show anything moving
+	random := rand.New(rand.NewSource(1))
+	random.Read(console.Bus.RAM[0x200:])
+	random.Read(console.PPU.Memory()[1])
+
+	// registers going between zero and not change the size of their
+	// encoding, which mustn't move the RAM after them
+	for i := 0; i < 8; i++ {
+		value := byte(0)
+		if i%2 == 1 {
+			value = 0xFF
+		}
+		console.CPU.A, console.CPU.Y = value, value
+		console.PPU.Write(0x2003, value)
+
+		if err := rewind.StepFrame(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// only the registers and the few bytes of RAM the program touches
+	// may differ, the rest of the memory has to line up
+	var registers bytes.Buffer
+	if err := console.saveRegisters(&registers); err != nil {
+		t.Fatal(err)
+	}
+	for _, snapshot := range rewind.snapshots[:len(rewind.snapshots)-1] {
+		if len(snapshot.delta) > registers.Len()+64 {
+			t.Errorf("the delta for frame %d is %d bytes, the registers are only %d", snapshot.frame, len(snapshot.delta), registers.Len())
+		}
+	}
+}
+
+func TestStateDelta(t *testing.T) {
+	base := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+
+	for _, target := range [][]byte{
+		{1, 2, 3, 4, 5, 6, 7, 8},
+		{1, 2, 9, 9, 5, 6, 7, 0},
+		{9, 2, 3},
+		{1, 2, 3, 4, 5, 6, 7, 8, 9, 10},
+		{},
+	} {
+		patched, err := patchState(base, diffState(base, target))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(patched, target) {
+			t.Error("did not rebuild", target, "got", patched)
+		}
+	}
+}
@@ -0,0 +1,316 @@
+package nes
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"strings"
+
+	"github.com/DevinRiley/nes/cartridge"
+)
+
+// Save states are a small header followed by one chunk per component and
+// a checksum:
+//
+// "NESS"      magic
+// uint16      format version, StateVersion
+// uint32      CRC32 of the ROM's PRG and CHR data
+// chunks      4 byte ID, uint32 length, then the component's state
+// uint32      CRC32 of everything before it
+//
+// Integers are little endian. The components encode their own chunks.
+const (
+	stateMagic = "NESS"
+
+	// StateVersion is the save state format written by this build. It
+	// goes up whenever a chunk changes in a way older builds can't read.
+	StateVersion = 1
+
+	// oldestStateVersion is the oldest format that can still be loaded
+	oldestStateVersion = 1
+)
+
+// stateMigrations upgrade the chunks of a save state from the version
+// they are indexed by to the next one. Changing the format means adding
+// the migration from the previous version here, or raising
+// oldestStateVersion if older states can't be converted.
+var stateMigrations = map[uint16]func(chunks map[string][]byte) error{}
+
+// Errors returned by LoadState
+var (
+	ErrNotSaveState  = errors.New("not a save state")
+	ErrStateChecksum = errors.New("save state is corrupt, checksum does not match")
+	ErrStateWrongROM = errors.New("save state is for a different ROM")
+)
+
+// StateVersionError is returned by LoadState for states written in a
+// format this build can't read.
+type StateVersionError struct {
+	Version uint16
+}
+
+func (err *StateVersionError) Error() string {
+	if err.Version > StateVersion {
+		return fmt.Sprintf("save state version %d is from a newer build, this build reads up to version %d", err.Version, StateVersion)
+	}
+
+	return fmt.Sprintf("save state version %d is no longer supported, the oldest supported version is %d", err.Version, oldestStateVersion)
+}
+
+type stateChunk struct {
+	id        string
+	save      func(w io.Writer) error
+	registers func(w io.Writer) error // save without the memory in Console.memory
+	load      func(r io.Reader) error
+}
+
+// stateChunks lists the components in the order they are saved and
+// loaded. The CPU goes first so a console stopped part way through an
+// instruction is turned away before anything is written.
+func (console *Console) stateChunks() []stateChunk {
+	return []stateChunk{
+		{"CPU ", console.CPU.SaveState, console.CPU.SaveState, console.CPU.LoadState},
+		{"PPU ", console.PPU.SaveState, console.PPU.SaveRegisters, console.PPU.LoadState},
+		{"APU ", console.APU.SaveState, console.APU.SaveState, console.APU.LoadState},
+		{"CART", console.Cartridge.SaveState, console.Cartridge.SaveRegisters, console.Cartridge.LoadState},
+		{"BUS ", console.saveBusState, console.saveBusRegisters, console.loadBusState},
+	}
+}
+
+// SaveState writes a snapshot of the whole machine. It can only be
+// loaded into a console running the same ROM. After RunCycles the CPU
+// may be part way through an instruction, which can't be saved, so
+// StepInstruction has to finish it first.
+func (console *Console) SaveState(w io.Writer) error {
+	return console.saveState(w, false)
+}
+
+// saveRegisters writes a state without the machine's RAM, which is left
+// alone when it is loaded, or the picture in the PPU's frame buffer
+func (console *Console) saveRegisters(w io.Writer) error {
+	return console.saveState(w, true)
+}
+
+// memory is all of the RAM in the machine. The layout only depends on
+// the ROM, so it can be compared byte for byte between snapshots.
+func (console *Console) memory() [][]byte {
+	memory := [][]byte{console.Bus.RAM[:]}
+	memory = append(memory, console.PPU.Memory()...)
+
+	return append(memory, console.Cartridge.Memory()...)
+}
+
+func (console *Console) saveState(w io.Writer, registersOnly bool) error {
+	if console.Cartridge == nil {
+		return ErrNoCartridge
+	}
+
+	var state bytes.Buffer
+	state.WriteString(stateMagic)
+	binary.Write(&state, binary.LittleEndian, uint16(StateVersion))
+	binary.Write(&state, binary.LittleEndian, romChecksum(console.ROM))
+
+	for _, chunk := range console.stateChunks() {
+		save := chunk.save
+		if registersOnly {
+			save = chunk.registers
+		}
+
+		var data bytes.Buffer
+		if err := save(&data); err != nil {
+			return fmt.Errorf("saving %s state: %w", strings.TrimSpace(chunk.id), err)
+		}
+
+		state.WriteString(chunk.id)
+		binary.Write(&state, binary.LittleEndian, uint32(data.Len()))
+		state.Write(data.Bytes())
+	}
+
+	binary.Write(&state, binary.LittleEndian, crc32.ChecksumIEEE(state.Bytes()))
+
+	_, err := w.Write(state.Bytes())
+	return err
+}
+
+// LoadState restores a snapshot written by SaveState. States from older
+// formats are migrated, anything that can't be loaded is reported with
+// one of the errors above and leaves the console as it was. Like
+// SaveState, it needs the CPU to be between instructions.
+func (console *Console) LoadState(r io.Reader) error {
+	if console.Cartridge == nil {
+		return ErrNoCartridge
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	chunks, err := parseState(data, romChecksum(console.ROM))
+	if err != nil {
+		return err
+	}
+
+	var backup bytes.Buffer
+	if err := console.SaveState(&backup); err != nil {
+		return err
+	}
+
+	if err := console.loadChunks(chunks); err != nil {
+		backupChunks, _ := parseState(backup.Bytes(), romChecksum(console.ROM))
+		console.loadChunks(backupChunks)
+		return err
+	}
+
+	return nil
+}
+
+func (console *Console) loadChunks(chunks map[string][]byte) error {
+	for _, chunk := range console.stateChunks() {
+		id := strings.TrimSpace(chunk.id)
+
+		data, ok := chunks[chunk.id]
+		if !ok {
+			return fmt.Errorf("%w: there is no %s chunk", ErrNotSaveState, id)
+		}
+
+		if err := chunk.load(bytes.NewReader(data)); err != nil {
+			return fmt.Errorf("loading %s state: %w", id, err)
+		}
+	}
+
+	return nil
+}
+
+// parseState checks a save state's header and checksum and splits it
+// into chunks, migrated to the current version.
+func parseState(data []byte, rom uint32) (map[string][]byte, error) {
+	const headerSize = len(stateMagic) + 2 + 4
+
+	if len(data) < headerSize+4 || string(data[:len(stateMagic)]) != stateMagic {
+		return nil, ErrNotSaveState
+	}
+
+	version := binary.LittleEndian.Uint16(data[4:])
+	if version > StateVersion || version < oldestStateVersion {
+		return nil, &StateVersionError{version}
+	}
+
+	body, checksum := data[:len(data)-4], binary.LittleEndian.Uint32(data[len(data)-4:])
+	if crc32.ChecksumIEEE(body) != checksum {
+		return nil, ErrStateChecksum
+	}
+
+	if binary.LittleEndian.Uint32(data[6:]) != rom {
+		return nil, ErrStateWrongROM
+	}
+
+	chunks := map[string][]byte{}
+	for rest := body[headerSize:]; len(rest) > 0; {
+		if len(rest) < 8 {
+			return nil, fmt.Errorf("%w: truncated chunk header", ErrNotSaveState)
+		}
+
+		id, size := string(rest[:4]), binary.LittleEndian.Uint32(rest[4:])
+		rest = rest[8:]
+		if uint32(len(rest)) < size {
+			return nil, fmt.Errorf("%w: truncated %s chunk", ErrNotSaveState, strings.TrimSpace(id))
+		}
+
+		chunks[id], rest = rest[:size], rest[size:]
+	}
+
+	for ; version < StateVersion; version++ {
+		migrate, ok := stateMigrations[version]
+		if !ok {
+			return nil, &StateVersionError{version}
+		}
+		if err := migrate(chunks); err != nil {
+			return nil, fmt.Errorf("migrating save state from version %d: %w", version, err)
+		}
+	}
+
+	return chunks, nil
+}
+
+// romChecksum identifies the game a save state belongs to
+func romChecksum(rom *cartridge.ROM) uint32 {
+	checksum := crc32.ChecksumIEEE(rom.PRGData)
+	return crc32.Update(checksum, crc32.IEEETable, rom.CHRData)
+}
+
+type busState struct {
+	RAM          []byte
+	OpenBus      byte
+	Controllers  []controllerState
+	Dots         int
+	OAMPage      byte
+	OAMCycles    int
+	OAMValue     byte
+	DMCAddress   uint16
+	DMCCycles    int
+	DMCRequested uint
+}
+
+// the buttons are live input, so only the shift registers are saved
+type controllerState struct {
+	Strobe bool
+	Shift  byte
+}
+
+func (console *Console) saveBusState(w io.Writer) error {
+	state := console.busRegisters()
+	state.RAM = console.Bus.RAM[:]
+
+	return gob.NewEncoder(w).Encode(&state)
+}
+
+func (console *Console) saveBusRegisters(w io.Writer) error {
+	state := console.busRegisters()
+	return gob.NewEncoder(w).Encode(&state)
+}
+
+func (console *Console) busRegisters() busState {
+	state := busState{
+		OpenBus: console.Bus.openBus,
+		Dots:    console.dots,
+
+		OAMPage:   console.oamPage,
+		OAMCycles: console.oamCycles,
+		OAMValue:  console.oamValue,
+
+		DMCAddress:   console.dmcAddress,
+		DMCCycles:    console.dmcCycles,
+		DMCRequested: console.dmcRequested,
+	}
+	for _, controller := range console.Controllers {
+		state.Controllers = append(state.Controllers, controllerState{controller.strobe, controller.shift})
+	}
+
+	return state
+}
+
+func (console *Console) loadBusState(r io.Reader) error {
+	var state busState
+	if err := gob.NewDecoder(r).Decode(&state); err != nil {
+		return err
+	}
+
+	copy(console.Bus.RAM[:], state.RAM)
+	console.Bus.openBus = state.OpenBus
+	console.dots = state.Dots
+	console.oamPage, console.oamCycles, console.oamValue = state.OAMPage, state.OAMCycles, state.OAMValue
+	console.dmcAddress, console.dmcCycles, console.dmcRequested = state.DMCAddress, state.DMCCycles, state.DMCRequested
+	for i := range console.Controllers {
+		if i < len(state.Controllers) {
+			console.Controllers[i].strobe = state.Controllers[i].Strobe
+			console.Controllers[i].shift = state.Controllers[i].Shift
+		}
+	}
+
+	return nil
+}
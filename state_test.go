@@ -0,0 +1,164 @@
+package nes
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"testing"
+
+	"github.com/DevinRiley/nes/cpu"
+)
+
+// countingROM runs INC $10, INX, JMP $8000 forever with NMI enabled, so
+// the state changes every instruction
+func countingROM() []byte {
+	return []byte{
+		0xA9, 0x80, // LDA #$80
+		0x8D, 0x00, 0x20, // STA $2000
+		0xE6, 0x10, // INC $10
+		0xE8,             // INX
+		0x4C, 0x05, 0x80, // JMP $8005
+	}
+}
+
+func saveTestState(t *testing.T, console *Console) []byte {
+	var state bytes.Buffer
+	if err := console.SaveState(&state); err != nil {
+		t.Fatal(err)
+	}
+
+	return state.Bytes()
+}
+
+func TestStateRoundTrip(t *testing.T) {
+	console := newTestConsole(t, testROM(countingROM()...))
+	console.StepFrame()
+	console.RunCycles(1234)
+	console.StepInstruction()
+
+	state := saveTestState(t, console)
+	console.StepFrame()
+	console.StepFrame()
+	expected := saveTestState(t, console)
+
+	if err := console.LoadState(bytes.NewReader(state)); err != nil {
+		t.Fatal(err)
+	}
+	console.StepFrame()
+	console.StepFrame()
+
+	if !bytes.Equal(saveTestState(t, console), expected) {
+		t.Error("did not run the same after loading the state")
+	}
+}
+
+func TestStateMidInstruction(t *testing.T) {
+	console := newTestConsole(t, testROM(countingROM()...))
+	console.StepFrame()
+	console.RunCycles(3)
+	cycles := console.CPU.Cycles
+
+	var state bytes.Buffer
+	if err := console.SaveState(&state); !errors.Is(err, cpu.ErrMidInstruction) {
+		t.Error("did not refuse to save part way through an instruction, got", err)
+	}
+	if console.CPU.Cycles != cycles || state.Len() != 0 {
+		t.Error("trying to save moved the console along")
+	}
+}
+
+func TestStateIntoNewConsole(t *testing.T) {
+	console := newTestConsole(t, testROM(countingROM()...))
+	console.StepFrame()
+	console.StepFrame()
+	state := saveTestState(t, console)
+
+	restored := newTestConsole(t, testROM(countingROM()...))
+	if err := restored.LoadState(bytes.NewReader(state)); err != nil {
+		t.Fatal(err)
+	}
+
+	if restored.CPU.PC != console.CPU.PC || restored.CPU.Cycles != console.CPU.Cycles ||
+		restored.Bus.RAM[0x10] != console.Bus.RAM[0x10] || restored.PPU.Frames != console.PPU.Frames {
+		t.Error("did not restore the machine")
+	}
+}
+
+func TestStateNotSaveState(t *testing.T) {
+	console := newTestConsole(t, testROM(loop...))
+
+	if err := console.LoadState(bytes.NewReader([]byte("not a state at all"))); !errors.Is(err, ErrNotSaveState) {
+		t.Error("did not return ErrNotSaveState, got", err)
+	}
+}
+
+func TestStateChecksum(t *testing.T) {
+	console := newTestConsole(t, testROM(loop...))
+	state := saveTestState(t, console)
+	state[20] ^= 0xFF
+
+	if err := console.LoadState(bytes.NewReader(state)); !errors.Is(err, ErrStateChecksum) {
+		t.Error("did not return ErrStateChecksum, got", err)
+	}
+}
+
+func TestStateWrongROM(t *testing.T) {
+	console := newTestConsole(t, testROM(loop...))
+	state := saveTestState(t, console)
+
+	other := newTestConsole(t, testROM(countingROM()...))
+	if err := other.LoadState(bytes.NewReader(state)); !errors.Is(err, ErrStateWrongROM) {
+		t.Error("did not return ErrStateWrongROM, got", err)
+	}
+}
+
+// resealState rewrites a state's version and fixes up the checksum
+func resealState(state []byte, version uint16) []byte {
+	binary.LittleEndian.PutUint16(state[4:], version)
+	body := state[:len(state)-4]
+	binary.LittleEndian.PutUint32(state[len(state)-4:], crc32.ChecksumIEEE(body))
+
+	return state
+}
+
+func TestStateNewerVersion(t *testing.T) {
+	console := newTestConsole(t, testROM(loop...))
+	state := resealState(saveTestState(t, console), StateVersion+1)
+
+	var version *StateVersionError
+	if err := console.LoadState(bytes.NewReader(state)); !errors.As(err, &version) || version.Version != StateVersion+1 {
+		t.Error("did not return a StateVersionError, got", err)
+	}
+}
+
+func TestStateOlderVersion(t *testing.T) {
+	console := newTestConsole(t, testROM(loop...))
+	state := resealState(saveTestState(t, console), oldestStateVersion-1)
+
+	var version *StateVersionError
+	if err := console.LoadState(bytes.NewReader(state)); !errors.As(err, &version) {
+		t.Error("did not reject a state older than the oldest version, got", err)
+	}
+}
+
+func TestStateFailedLoadLeavesConsole(t *testing.T) {
+	console := newTestConsole(t, testROM(countingROM()...))
+	console.StepFrame()
+	state := saveTestState(t, console)
+
+	// drop the BUS chunk, which is loaded last
+	state = state[:bytes.Index(state, []byte("BUS "))]
+	state = binary.LittleEndian.AppendUint32(state, crc32.ChecksumIEEE(state))
+
+	console.StepFrame()
+	expected := saveTestState(t, console)
+
+	if err := console.LoadState(bytes.NewReader(state)); !errors.Is(err, ErrNotSaveState) {
+		t.Error("loaded a state without a BUS chunk, got", err)
+	}
+
+	if !bytes.Equal(saveTestState(t, console), expected) {
+		t.Error("a failed load changed the console")
+	}
+}
@@ -0,0 +1,239 @@
+// Package testrom runs accuracy test ROMs, like blargg's instr_test-v5,
+// ppu_vbl_nmi and apu_test, that report their result in PRG RAM:
+//
+//	$6000     status, $80 while running, $81 to ask for the reset
+//	          button to be pressed, otherwise the result code, 0 passes
+//	$6001     $DE $B0 $61, to show the status is valid
+//	$6004     the text the ROM printed, ending in a zero byte
+//
+// ROMs that only show their result on screen time out.
+package testrom
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/DevinRiley/nes"
+	"github.com/DevinRiley/nes/cartridge"
+)
+
+const (
+	statusAddress    = 0x6000
+	signatureAddress = 0x6001
+	textAddress      = 0x6004
+
+	statusRunning = 0x80
+	statusReset   = 0x81
+)
+
+var signature = [3]byte{0xDE, 0xB0, 0x61}
+
+// framesPerSecond is the NTSC frame rate, for turning emulated time into
+// frames
+const framesPerSecond = 60.0988
+
+// resetDelay is how long the ROMs ask to wait before pressing reset
+const resetDelay = 100 * time.Millisecond
+
+// ErrHalted is a Result's Err when a KIL opcode stopped the CPU.
+var ErrHalted = errors.New("the CPU halted")
+
+// DefaultTimeout is enough emulated time for the slowest of blargg's
+// ROMs, all_instrs and cpu_timing_test, to finish.
+const DefaultTimeout = 2 * time.Minute
+
+// Test is a ROM to run and how long to give it, in emulated time.
+// DefaultTimeout is used if Timeout is zero.
+type Test struct {
+	Path    string
+	Timeout time.Duration
+}
+
+// Outcome is how a test ROM ended.
+type Outcome int
+
+const (
+	Passed Outcome = iota
+	Failed
+	TimedOut
+	Crashed // the ROM couldn't be loaded or the CPU stopped
+)
+
+func (outcome Outcome) String() string {
+	switch outcome {
+	case Passed:
+		return "passed"
+	case Failed:
+		return "failed"
+	case TimedOut:
+		return "timed out"
+	}
+
+	return "crashed"
+}
+
+// Result is what a test ROM reported.
+type Result struct {
+	Path    string
+	Outcome Outcome
+	Code    byte          // the status byte, 0 for a pass
+	Text    string        // what the ROM printed, if it got that far
+	Elapsed time.Duration // emulated time the ROM ran for
+	Err     error         // why it crashed
+}
+
+// Run runs a console that has a test ROM loaded until the ROM reports a
+// result or the timeout, in emulated time, runs out. The reset button is
+// pressed whenever the ROM asks for it.
+func Run(console *nes.Console, timeout time.Duration) Result {
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+	frames := framesFor(timeout)
+	reset := -1 // the frame to press reset on
+
+	var result Result
+	for frame := 0; frame < frames; frame++ {
+		err := console.StepFrame()
+		if err == nil && console.CPU.Halted {
+			err = fmt.Errorf("%w at $%04X", ErrHalted, console.CPU.PC)
+		}
+		if err != nil {
+			result.Outcome, result.Err = Crashed, err
+			result.Elapsed = elapsed(frame + 1)
+			return result
+		}
+
+		status, valid := readStatus(console)
+		switch {
+		case !valid || status == statusRunning:
+		case status == statusReset:
+			if reset < 0 {
+				reset = frame + framesFor(resetDelay)
+			} else if frame >= reset {
+				if err := console.Reset(); err != nil {
+					result.Outcome, result.Err = Crashed, err
+					result.Elapsed = elapsed(frame + 1)
+					return result
+				}
+				reset = -1
+			}
+		default:
+			result.Outcome, result.Code = Passed, status
+			if status != 0 {
+				result.Outcome = Failed
+			}
+			result.Text = readText(console)
+			result.Elapsed = elapsed(frame + 1)
+			return result
+		}
+	}
+
+	result.Outcome, result.Elapsed = TimedOut, elapsed(frames)
+	if _, valid := readStatus(console); valid {
+		result.Text = readText(console)
+	}
+
+	return result
+}
+
+// RunAll loads and runs each test in turn.
+func RunAll(tests []Test) []Result {
+	results := make([]Result, len(tests))
+
+	for i, test := range tests {
+		console, err := load(test.Path)
+		if err != nil {
+			results[i] = Result{Outcome: Crashed, Err: err}
+		} else {
+			results[i] = Run(console, test.Timeout)
+		}
+		results[i].Path = test.Path
+	}
+
+	return results
+}
+
+func load(path string) (*nes.Console, error) {
+	rom, err := cartridge.LoadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	console := nes.NewConsole()
+	if err := console.Load(rom); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return console, nil
+}
+
+// WriteSummary writes a table of the results, one ROM a line, and a count
+// of how many passed.
+func WriteSummary(w io.Writer, results []Result) error {
+	table := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(table, "ROM\tRESULT\tTIME\tMESSAGE")
+
+	passed := 0
+	for _, result := range results {
+		outcome := result.Outcome.String()
+		if result.Outcome == Failed {
+			outcome = fmt.Sprintf("failed #%d", result.Code)
+		}
+
+		message := strings.Join(strings.Fields(result.Text), " ")
+		if result.Err != nil {
+			message = result.Err.Error()
+		}
+
+		fmt.Fprintf(table, "%s\t%s\t%.1fs\t%s\n", filepath.Base(result.Path), outcome, result.Elapsed.Seconds(), message)
+		if result.Outcome == Passed {
+			passed++
+		}
+	}
+
+	if err := table.Flush(); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintf(w, "%d of %d passed\n", passed, len(results))
+	return err
+}
+
+// readStatus reads the status byte, valid once the signature is written
+func readStatus(console *nes.Console) (status byte, valid bool) {
+	for i, value := range signature {
+		if console.Bus.Peek(signatureAddress+uint16(i)) != value {
+			return 0, false
+		}
+	}
+
+	return console.Bus.Peek(statusAddress), true
+}
+
+func readText(console *nes.Console) string {
+	var text []byte
+	for address := uint16(textAddress); address < 0x8000; address++ {
+		value := console.Bus.Peek(address)
+		if value == 0 {
+			break
+		}
+		text = append(text, value)
+	}
+
+	return string(text)
+}
+
+func framesFor(duration time.Duration) int {
+	return int(math.Ceil(duration.Seconds() * framesPerSecond))
+}
+
+func elapsed(frames int) time.Duration {
+	return time.Duration(float64(frames) / framesPerSecond * float64(time.Second))
+}
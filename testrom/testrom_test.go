@@ -0,0 +1,205 @@
+package testrom
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DevinRiley/nes"
+	"github.com/DevinRiley/nes/cartridge"
+	"github.com/DevinRiley/nes/cpu"
+)
+
+// report is a test ROM that writes a result after a few frames, it waits
+// on the vblank flag so the harness sees it running first
+const report = `
+		.org $8000
+		LDA #$80
+		STA $6000
+		JSR sign
+		LDX #$10
+@wait:	BIT $2002
+		BPL @wait
+		DEX
+		BNE @wait
+		LDX #$00
+@text:	LDA text,X
+		STA $6004,X
+		INX
+		CPX #textEnd-text
+		BNE @text
+		LDA #STATUS
+		STA $6000
+@done:	JMP @done
+
+sign:	LDA #$DE
+		STA $6001
+		LDA #$B0
+		STA $6002
+		LDA #$61
+		STA $6003
+		RTS
+
+text:	.byte "TEXT", 0
+textEnd:
+		.org $FFFC
+		.word $8000
+`
+
+// resetting asks for a reset, then passes once it has had one
+const resetting = `
+		.org $8000
+		LDA $6100
+		BNE @after
+		INC $6100
+		LDA #$DE
+		STA $6001
+		LDA #$B0
+		STA $6002
+		LDA #$61
+		STA $6003
+		LDA #$81
+		STA $6000
+@wait:	JMP @wait
+@after:	LDA #$00
+		STA $6004
+		STA $6000
+@done:	JMP @done
+		.org $FFFC
+		.word $8000
+`
+
+func testROM(t *testing.T, source string) *cartridge.ROM {
+	program, err := cpu.Assemble(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &cartridge.ROM{PRGSize: 0x8000, PRGData: program.Image(0x8000, 0x8000)}
+}
+
+func reportROM(t *testing.T, status string, text string) *cartridge.ROM {
+	source := strings.Replace(report, "STATUS", status, 1)
+	return testROM(t, strings.Replace(source, "TEXT", text, 1))
+}
+
+func newTestConsole(t *testing.T, rom *cartridge.ROM) *nes.Console {
+	console := nes.NewConsole()
+	if err := console.Load(rom); err != nil {
+		t.Fatal(err)
+	}
+
+	return console
+}
+
+func TestRunPassed(t *testing.T) {
+	result := Run(newTestConsole(t, reportROM(t, "$00", `Passed\n`)), time.Second)
+
+	if result.Outcome != Passed || result.Code != 0 {
+		t.Errorf("did not pass, got %v with code %d", result.Outcome, result.Code)
+	}
+	if result.Text != `Passed\n` {
+		t.Errorf("did not read the text, got %q", result.Text)
+	}
+	if result.Elapsed < 16*time.Second/60 || result.Elapsed > time.Second/2 {
+		t.Error("did not count the emulated time, got", result.Elapsed)
+	}
+}
+
+func TestRunFailed(t *testing.T) {
+	result := Run(newTestConsole(t, reportROM(t, "$03", "BRK didn't push B")), time.Second)
+
+	if result.Outcome != Failed || result.Code != 3 || result.Text != "BRK didn't push B" {
+		t.Errorf("did not fail with code 3, got %v with code %d and %q", result.Outcome, result.Code, result.Text)
+	}
+}
+
+func TestRunTimedOut(t *testing.T) {
+	result := Run(newTestConsole(t, testROM(t, ".org $8000\n@loop: JMP @loop\n.org $FFFC\n.word $8000")), time.Second/2)
+
+	if result.Outcome != TimedOut || result.Elapsed < time.Second/2 {
+		t.Errorf("did not time out after half a second, got %v after %v", result.Outcome, result.Elapsed)
+	}
+}
+
+func TestRunReset(t *testing.T) {
+	result := Run(newTestConsole(t, testROM(t, resetting)), time.Second)
+
+	if result.Outcome != Passed {
+		t.Error("did not press reset, got", result.Outcome)
+	}
+	if result.Elapsed < resetDelay {
+		t.Error("did not wait before pressing reset, got", result.Elapsed)
+	}
+}
+
+func TestRunCrashed(t *testing.T) {
+	result := Run(newTestConsole(t, testROM(t, ".org $8000\n.byte $02\n.org $FFFC\n.word $8000")), time.Second)
+
+	if result.Outcome != Crashed || !errors.Is(result.Err, ErrHalted) {
+		t.Error("did not crash on a KIL opcode, got", result.Outcome, result.Err)
+	}
+}
+
+func TestRunAll(t *testing.T) {
+	results := RunAll([]Test{{Path: filepath.Join(t.TempDir(), "missing.nes")}})
+
+	if len(results) != 1 || results[0].Outcome != Crashed || !errors.Is(results[0].Err, os.ErrNotExist) {
+		t.Error("did not report the missing ROM, got", results)
+	}
+}
+
+func TestWriteSummary(t *testing.T) {
+	results := []Result{
+		{Path: "roms/01-basics.nes", Outcome: Passed, Text: "\n01-basics\n\nPassed\n", Elapsed: time.Second},
+		{Path: "roms/03-immediate.nes", Outcome: Failed, Code: 2, Text: "ADC #n\nFailed", Elapsed: 2 * time.Second},
+	}
+
+	var out bytes.Buffer
+	if err := WriteSummary(&out, results); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "ROM               RESULT     TIME  MESSAGE\n" +
+		"01-basics.nes     passed     1.0s  01-basics Passed\n" +
+		"03-immediate.nes  failed #2  2.0s  ADC #n Failed\n" +
+		"1 of 2 passed\n"
+	if out.String() != want {
+		t.Errorf("did not write the summary table, got\n%s", out.String())
+	}
+}
+
+// TestROMs runs every ROM in the directory named by NES_TEST_ROMS, like a
+// checkout of blargg's test ROMs. It is skipped when that isn't set.
+func TestROMs(t *testing.T) {
+	dir := os.Getenv("NES_TEST_ROMS")
+	if dir == "" {
+		t.Skip("NES_TEST_ROMS is not set")
+	}
+
+	var tests []Test
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && strings.EqualFold(filepath.Ext(path), ".nes") {
+			tests = append(tests, Test{Path: path})
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results := RunAll(tests)
+	for _, result := range results {
+		if result.Outcome != Passed {
+			t.Errorf("%s %v: %s", result.Path, result.Outcome, strings.Join(strings.Fields(result.Text), " "))
+		}
+	}
+
+	var summary bytes.Buffer
+	WriteSummary(&summary, results)
+	t.Log("\n" + summary.String())
+}
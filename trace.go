@@ -0,0 +1,120 @@
+package nes
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/DevinRiley/nes/cpu"
+)
+
+// TraceFormat picks the layout of a Tracer's lines, so traces can be
+// diffed against logs from other emulators.
+type TraceFormat int
+
+const (
+	// NestestTrace is the layout of nestest.log:
+	//
+	//	C000  4C F5 C5  JMP $C5F5                       A:00 X:00 Y:00 P:24 SP:FD PPU:  0, 21 CYC:7
+	NestestTrace TraceFormat = iota
+	// FCEUXTrace is the layout of FCEUX's trace logger, registers first
+	// and the flags as letters:
+	//
+	//	A:00 X:00 Y:00 S:FD P:nvUbdIzc  $C000:4C F5 C5  JMP $C5F5
+	FCEUXTrace
+	// MesenTrace is the layout of Mesen's trace logger, which counts
+	// PPU cycles and scanlines rather than giving a position:
+	//
+	//	C000  $4C $F5 $C5  JMP $C5F5                      A:00 X:00 Y:00 P:24 SP:FD CYC:21  SL:0   FC:0 CPU Cycle:7
+	MesenTrace
+)
+
+// Tracer writes a line for every instruction a console runs, before the
+// instruction runs. Set it as Console.Tracer.
+type Tracer struct {
+	Format TraceFormat
+
+	w   io.Writer
+	err error
+}
+
+// NewTracer traces to w in format.
+func NewTracer(w io.Writer, format TraceFormat) *Tracer {
+	return &Tracer{Format: format, w: w}
+}
+
+// Err is the first error from writing the trace. Tracing stops after it.
+func (tracer *Tracer) Err() error {
+	return tracer.err
+}
+
+// trace is the CPU's OnInstruction hook
+func (console *Console) trace() {
+	tracer := console.Tracer
+	if tracer == nil || tracer.err != nil {
+		return
+	}
+
+	_, tracer.err = io.WriteString(tracer.w, tracer.Line(console)+"\n")
+}
+
+// Line formats the instruction the console is about to run. Memory is
+// peeked for the disassembly and annotations, so tracing doesn't change
+// what the program does.
+func (tracer *Tracer) Line(console *Console) string {
+	processor := console.CPU
+	disassembly := cpu.Disassemble(console.Bus, processor.PC)
+
+	switch tracer.Format {
+	case FCEUXTrace:
+		text := strings.TrimPrefix(disassembly.String(), "*")
+		if address, ok := processor.EffectiveAddress(disassembly); ok {
+			text += fmt.Sprintf(" @ $%04X = #$%02X", address, console.Bus.Peek(address))
+		}
+
+		return fmt.Sprintf("A:%02X X:%02X Y:%02X S:%02X P:%s  $%04X:%-9s %s",
+			processor.A, processor.X, processor.Y, processor.SP, flagLetters(processor.Status()),
+			processor.PC, disassembly.Hex(), text)
+
+	case MesenTrace:
+		code := "$" + strings.ReplaceAll(disassembly.Hex(), " ", " $")
+		text := strings.TrimPrefix(disassembly.String(), "*")
+		if address, ok := processor.EffectiveAddress(disassembly); ok {
+			text += fmt.Sprintf(" [$%04X] = $%02X", address, console.Bus.Peek(address))
+		}
+
+		return fmt.Sprintf("%04X  %-11s  %-30s A:%02X X:%02X Y:%02X P:%02X SP:%02X CYC:%-3d SL:%-3d FC:%d CPU Cycle:%d",
+			processor.PC, code, text, processor.A, processor.X, processor.Y, processor.Status(), processor.SP,
+			console.PPU.Dot, mesenScanline(console.PPU.Scanline), console.PPU.Frames, processor.Cycles)
+	}
+
+	text := disassembly.Listing(nil)
+	if annotation := processor.Annotate(disassembly); annotation != "" {
+		text += " " + annotation
+	}
+
+	return fmt.Sprintf("%-47s A:%02X X:%02X Y:%02X P:%02X SP:%02X PPU:%3d,%3d CYC:%d",
+		text, processor.A, processor.X, processor.Y, processor.Status(), processor.SP,
+		console.PPU.Scanline, console.PPU.Dot, processor.Cycles)
+}
+
+// flagLetters shows the flags as NVUBDIZC, capitals for the ones set
+func flagLetters(status byte) string {
+	letters := []byte("nvubdizc")
+	for i := range letters {
+		if status&(0x80>>i) != 0 {
+			letters[i] -= 'a' - 'A'
+		}
+	}
+
+	return string(letters)
+}
+
+// Mesen numbers the pre-render scanline -1
+func mesenScanline(scanline int) int {
+	if scanline == 261 {
+		return -1
+	}
+
+	return scanline
+}
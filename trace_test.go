@@ -0,0 +1,78 @@
+package nes
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+// tracedNestest is nestest's automated test with a tracer
+func tracedNestest(t *testing.T, format TraceFormat) (*Console, *bytes.Buffer) {
+	console := nestestConsole(t)
+
+	var trace bytes.Buffer
+	console.Tracer = NewTracer(&trace, format)
+
+	return console, &trace
+}
+
+func TestTracerNestest(t *testing.T) {
+	log, err := os.ReadFile("nestest.log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Split(strings.TrimRight(strings.ReplaceAll(string(log), "\r", ""), "\n"), "\n")
+
+	console, trace := tracedNestest(t, NestestTrace)
+	for range want {
+		if err := console.StepInstruction(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got := strings.Split(trace.String(), "\n")
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("did not match nestest.log at line %d\nwant %s\ngot  %s", i+1, want[i], got[i])
+		}
+	}
+}
+
+func TestTracerFormats(t *testing.T) {
+	tests := []struct {
+		format TraceFormat
+		line   string
+	}{
+		{FCEUXTrace, "A:00 X:00 Y:00 S:FD P:nvUbdIzc  $C000:4C F5 C5  JMP $C5F5"},
+		{MesenTrace, "C000  $4C $F5 $C5  JMP $C5F5                      A:00 X:00 Y:00 P:24 SP:FD CYC:21  SL:0   FC:0 CPU Cycle:7"},
+	}
+
+	for _, test := range tests {
+		console, trace := tracedNestest(t, test.format)
+		if err := console.StepInstruction(); err != nil {
+			t.Fatal(err)
+		}
+
+		if line := strings.TrimSuffix(trace.String(), "\n"); line != test.line {
+			t.Errorf("did not trace in format %d\nwant %s\ngot  %s", test.format, test.line, line)
+		}
+	}
+}
+
+func TestTracerAnnotations(t *testing.T) {
+	console, _ := tracedNestest(t, FCEUXTrace)
+	console.Bus.Write(0x0300, 0x5A)
+	console.Bus.Write(0x0200, 0xBD) // LDA $0300,X
+	console.Bus.Write(0x0202, 0x03)
+	console.CPU.PC, console.CPU.X = 0x0200, 0x00
+
+	if line := console.Tracer.Line(console); !strings.HasSuffix(line, "LDA $0300,X @ $0300 = #$5A") {
+		t.Error("did not annotate the effective address, got", line)
+	}
+
+	console.Tracer.Format = MesenTrace
+	if line := console.Tracer.Line(console); !strings.Contains(line, "LDA $0300,X [$0300] = $5A") {
+		t.Error("did not annotate the effective address, got", line)
+	}
+}
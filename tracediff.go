@@ -0,0 +1,251 @@
+package nes
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/DevinRiley/nes/cpu"
+)
+
+// traceField matches the register, PPU and cycle columns of a nestest.log
+// line. Whatever comes before the first of them is the instruction.
+var traceField = regexp.MustCompile(`\b(A|X|Y|P|SP|PPU|CYC):(\s*\d+,\s*\d+|\S+)`)
+
+var errDiverged = errors.New("the trace diverged")
+
+// Divergence is the first line where a console's trace differs from a
+// reference trace.
+type Divergence struct {
+	Line   int // counted from 1
+	Want   string
+	Got    string
+	Fields []FieldDiff
+
+	// Before is the instructions leading up to the divergence, oldest
+	// first, with the memory each one touched
+	Before []TracedInstruction
+}
+
+// FieldDiff is a column of a trace line that differs from the reference.
+type FieldDiff struct {
+	Name string
+	Want string
+	Got  string
+}
+
+// TracedInstruction is a line of a trace and the memory the CPU read and
+// wrote from the start of that instruction to the start of the next,
+// including any interrupt it took.
+type TracedInstruction struct {
+	Line     int
+	Text     string
+	Accesses []BusAccess
+}
+
+// BusAccess is a read or write the CPU made.
+type BusAccess struct {
+	Address uint16
+	Value   byte
+	Write   bool
+}
+
+func (access BusAccess) String() string {
+	if access.Write {
+		return fmt.Sprintf("write $%04X = $%02X", access.Address, access.Value)
+	}
+
+	return fmt.Sprintf("read  $%04X = $%02X", access.Address, access.Value)
+}
+
+// DiffTrace runs a console against a reference trace in the format of
+// nestest.log, one line per instruction, until the reference ends or the
+// console's trace differs from it. Columns missing from the reference,
+// like the PPU position in older logs, aren't compared, and neither is
+// spacing. It returns nil if the console follows the whole reference,
+// otherwise the divergence with up to context instructions before it.
+func DiffTrace(console *Console, reference io.Reader, context int) (*Divergence, error) {
+	diff := &traceDiff{reference: bufio.NewScanner(reference), context: context}
+	diff.next()
+
+	tracer, bus := console.Tracer, console.CPU.Bus
+	defer func() { console.Tracer, console.CPU.Bus = tracer, bus }()
+	console.Tracer = NewTracer(diff, NestestTrace)
+	console.CPU.Bus = &accessBus{Bus: bus, diff: diff}
+
+	for diff.more && diff.divergence == nil {
+		if err := console.StepInstruction(); err != nil {
+			return nil, fmt.Errorf("line %d: %w", diff.line+1, err)
+		}
+	}
+
+	if err := diff.reference.Err(); err != nil {
+		return nil, err
+	}
+
+	return diff.divergence, nil
+}
+
+// traceDiff is the Tracer's writer, it compares each line with the
+// reference as it is traced
+type traceDiff struct {
+	reference *bufio.Scanner
+	want      string // the next line of the reference
+	more      bool   // whether there is a next line
+	line      int    // lines compared so far
+	partial   []byte
+
+	context    int
+	recent     []TracedInstruction // the last context+1 instructions
+	divergence *Divergence
+}
+
+// next reads the next line of the reference, skipping blank ones
+func (diff *traceDiff) next() {
+	for diff.more = diff.reference.Scan(); diff.more; diff.more = diff.reference.Scan() {
+		if diff.want = strings.TrimRight(diff.reference.Text(), "\r"); strings.TrimSpace(diff.want) != "" {
+			return
+		}
+	}
+}
+
+func (diff *traceDiff) Write(p []byte) (int, error) {
+	diff.partial = append(diff.partial, p...)
+
+	for {
+		end := strings.IndexByte(string(diff.partial), '\n')
+		if end < 0 {
+			return len(p), nil
+		}
+		got := string(diff.partial[:end])
+		diff.partial = diff.partial[end+1:]
+
+		if diff.more && diff.divergence == nil {
+			diff.compare(got)
+		}
+		if diff.divergence != nil {
+			return len(p), errDiverged
+		}
+	}
+}
+
+func (diff *traceDiff) compare(got string) {
+	diff.line++
+
+	if fields := diffTraceLines(diff.want, got); len(fields) > 0 {
+		diff.divergence = &Divergence{Line: diff.line, Want: diff.want, Got: got, Fields: fields}
+		if n := len(diff.recent); n > diff.context {
+			diff.divergence.Before = diff.recent[n-diff.context:]
+		} else {
+			diff.divergence.Before = diff.recent
+		}
+		return
+	}
+
+	diff.recent = append(diff.recent, TracedInstruction{Line: diff.line, Text: got})
+	if len(diff.recent) > diff.context+1 {
+		diff.recent = diff.recent[1:]
+	}
+	diff.next()
+}
+
+func (diff *traceDiff) access(access BusAccess) {
+	if n := len(diff.recent); n > 0 && diff.divergence == nil {
+		diff.recent[n-1].Accesses = append(diff.recent[n-1].Accesses, access)
+	}
+}
+
+// traceColumn is a named field of a trace line
+type traceColumn struct {
+	name  string
+	value string
+}
+
+// diffTraceLines compares the instruction and each column of the
+// reference line with the traced line
+func diffTraceLines(want string, got string) []FieldDiff {
+	gotColumns := map[string]string{}
+	for _, column := range splitTraceLine(got) {
+		gotColumns[column.name] = column.value
+	}
+
+	var diffs []FieldDiff
+	for _, column := range splitTraceLine(want) {
+		if value := gotColumns[column.name]; value != column.value {
+			diffs = append(diffs, FieldDiff{Name: column.name, Want: column.value, Got: value})
+		}
+	}
+
+	return diffs
+}
+
+// splitTraceLine breaks a line into its columns, with the spacing taken
+// out of their values
+func splitTraceLine(line string) []traceColumn {
+	instruction := line
+	if match := traceField.FindStringIndex(line); match != nil {
+		instruction = line[:match[0]]
+	}
+
+	words := strings.Fields(instruction)
+	columns := []traceColumn{{name: "PC"}, {name: "instruction"}}
+	if len(words) > 0 {
+		columns[0].value, columns[1].value = words[0], strings.Join(words[1:], " ")
+	}
+
+	for _, match := range traceField.FindAllStringSubmatch(line[len(instruction):], -1) {
+		columns = append(columns, traceColumn{name: match[1], value: strings.Join(strings.Fields(match[2]), "")})
+	}
+
+	return columns
+}
+
+// String reports the divergence, the instructions before it and the
+// memory they touched, then the lines and the columns that differ.
+func (divergence *Divergence) String() string {
+	var report strings.Builder
+
+	fmt.Fprintf(&report, "line %d differs from the reference\n", divergence.Line)
+	for _, instruction := range divergence.Before {
+		fmt.Fprintf(&report, "%6d  %s\n", instruction.Line, instruction.Text)
+		for _, access := range instruction.Accesses {
+			fmt.Fprintf(&report, "          %s\n", access)
+		}
+	}
+
+	fmt.Fprintf(&report, "want    %s\n", divergence.Want)
+	fmt.Fprintf(&report, "got     %s\n", divergence.Got)
+	for _, field := range divergence.Fields {
+		fmt.Fprintf(&report, "  %s: want %s, got %s\n", field.Name, field.Want, field.Got)
+	}
+
+	return report.String()
+}
+
+// accessBus tells the trace diff about every access the CPU makes
+type accessBus struct {
+	cpu.Bus
+	diff *traceDiff
+}
+
+func (bus *accessBus) Read(address uint16) byte {
+	value := bus.Bus.Read(address)
+	bus.diff.access(BusAccess{Address: address, Value: value})
+	return value
+}
+
+func (bus *accessBus) Write(address uint16, value byte) {
+	bus.Bus.Write(address, value)
+	bus.diff.access(BusAccess{Address: address, Value: value, Write: true})
+}
+
+func (bus *accessBus) Peek(address uint16) byte {
+	if peeker, ok := bus.Bus.(cpu.Peeker); ok {
+		return peeker.Peek(address)
+	}
+
+	return bus.Bus.Read(address)
+}
@@ -0,0 +1,95 @@
+package nes
+
+import (
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// nestestLog is the first lines of nestest.log, with edit applied to them
+func nestestLog(t *testing.T, lines int, edit func(line int, text string) string) string {
+	log, err := os.ReadFile("nestest.log")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reference := strings.Split(string(log), "\n")[:lines]
+	for i := range reference {
+		reference[i] = edit(i+1, reference[i])
+	}
+
+	return strings.Join(reference, "\n")
+}
+
+func TestDiffTraceDivergence(t *testing.T) {
+	reference := nestestLog(t, 200, func(line int, text string) string {
+		if line == 100 {
+			return strings.Replace(text, "CYC:259", "CYC:258", 1)
+		}
+		return text
+	})
+
+	console := nestestConsole(t)
+	divergence, err := DiffTrace(console, strings.NewReader(reference), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if divergence == nil || divergence.Line != 100 {
+		t.Fatal("did not find the divergence at line 100, got", divergence)
+	}
+	if len(divergence.Fields) != 1 || divergence.Fields[0] != (FieldDiff{"CYC", "258", "259"}) {
+		t.Error("did not diff only the cycle column, got", divergence.Fields)
+	}
+
+	before := divergence.Before
+	if len(before) != 3 || before[0].Line != 97 || before[2].Line != 99 {
+		t.Fatal("did not keep the 3 instructions before the divergence, got", before)
+	}
+	if accesses := before[2].Accesses; len(accesses) == 0 || accesses[0] != (BusAccess{Address: 0xC81B, Value: 0xF0}) {
+		t.Error("did not record the memory BEQ touched, got", accesses)
+	}
+
+	if console.Tracer != nil || console.CPU.Bus != console.Bus {
+		t.Error("did not put the console back the way it was")
+	}
+}
+
+func TestDiffTraceRegisters(t *testing.T) {
+	reference := nestestLog(t, 10, func(line int, text string) string {
+		if line == 5 {
+			return strings.Replace(strings.Replace(text, "X:00", "X:01", 1), "P:26", "P:A6", 1)
+		}
+		return text
+	})
+
+	divergence, err := DiffTrace(nestestConsole(t), strings.NewReader(reference), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if divergence == nil || divergence.Line != 5 || len(divergence.Before) != 4 {
+		t.Fatal("did not find the divergence at line 5, got", divergence)
+	}
+	want := []FieldDiff{{"X", "01", "00"}, {"P", "A6", "26"}}
+	if len(divergence.Fields) != 2 || divergence.Fields[0] != want[0] || divergence.Fields[1] != want[1] {
+		t.Error("did not diff the X and P columns, got", divergence.Fields)
+	}
+}
+
+func TestDiffTraceMissingColumns(t *testing.T) {
+	ppu := regexp.MustCompile(` PPU:\s*\d+,\s*\d+`)
+	reference := nestestLog(t, 500, func(line int, text string) string {
+		return strings.Join(strings.Fields(ppu.ReplaceAllString(text, "")), " ")
+	})
+
+	divergence, err := DiffTrace(nestestConsole(t), strings.NewReader(reference), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if divergence != nil {
+		t.Error("did not skip the missing PPU column or ignore spacing,", divergence)
+	}
+}